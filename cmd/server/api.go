@@ -0,0 +1,49 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/runtime/security"
+
+	"github.com/volmedo/pAPI/pkg/restapi"
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+// newAPIHandler creates the handler of the API configured by c. The generated API
+// is customized here instead of in the generated code, so that it survives when
+// the server is generated again. Errors raised before requests reach the service,
+// such as authentication or validation errors, are written by service.ServeError.
+//
+// If clientCert is not nil, requests without credentials sent over a TLS connection
+// with a verified client certificate are authenticated by calling it with the certificate
+func newAPIHandler(c restapi.Config, clientCert func(*x509.Certificate) (interface{}, error)) (http.Handler, error) {
+	handler, api, err := restapi.HandlerAPI(c)
+	if err != nil {
+		return nil, err
+	}
+	api.ServeError = service.ServeError
+
+	if clientCert == nil {
+		return handler, nil
+	}
+
+	apiKeyAuthenticator := api.APIKeyAuthenticator
+	api.APIKeyAuthenticator = func(name, in string, auth security.TokenAuthentication) runtime.Authenticator {
+		return clientCertAuthenticator(apiKeyAuthenticator(name, in, auth), clientCert)
+	}
+
+	// Authenticators are bound to the routes when they are built, which HandlerAPI
+	// has already done, so the routes are built again in a new context
+	spec, err := loads.Analyzed(append(json.RawMessage(nil), restapi.SwaggerJSON...), "")
+	if err != nil {
+		return nil, fmt.Errorf("analyze swagger: %v", err)
+	}
+
+	return middleware.NewRoutableContext(spec, api, nil).APIHandler(c.InnerMiddleware), nil
+}
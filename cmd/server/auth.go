@@ -0,0 +1,364 @@
+package main
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/security"
+	"github.com/go-openapi/strfmt"
+	jwt "github.com/golang-jwt/jwt"
+
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+// authenticator checks the credentials sent by a client and returns
+// the principal they belong to
+type authenticator interface {
+	Authenticate(token string) (*service.Principal, error)
+}
+
+// apiKey is an entry of an API keys file. Only the SHA-256 hash of each key
+// is kept in the file so that a leaked file doesn't leak the keys themselves.
+// Clients using the key can access the payments of the given organisations,
+// performing the operations allowed by the given scopes
+type apiKey struct {
+	Name          string        `json:"name"`
+	SHA256        string        `json:"sha256"`
+	Organisations []strfmt.UUID `json:"organisations"`
+	Scopes        []string      `json:"scopes"`
+}
+
+// apiKeyAuthenticator authenticates requests using a static set of API keys
+type apiKeyAuthenticator struct {
+	keys []apiKey
+	// hashes holds the decoded hashes of keys, in the same order
+	hashes [][]byte
+}
+
+// loadAPIKeys reads the API keys file at path, which must contain a JSON array
+// of objects with the name of each key, the hex encoded SHA-256 hash of it,
+// the organisations it gives access to and the scopes granted to it
+func loadAPIKeys(path string) (*apiKeyAuthenticator, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading API keys file: %v", err)
+	}
+
+	var keys []apiKey
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return nil, fmt.Errorf("error parsing API keys file: %v", err)
+	}
+
+	return newAPIKeyAuthenticator(keys)
+}
+
+// newAPIKeyAuthenticator creates an authenticator that accepts the given keys
+func newAPIKeyAuthenticator(keys []apiKey) (*apiKeyAuthenticator, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no API keys defined")
+	}
+
+	hashes := make([][]byte, len(keys))
+	for i, key := range keys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("API key #%d has no name", i)
+		}
+
+		hash, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("API key %q has an invalid SHA-256 hash", key.Name)
+		}
+		hashes[i] = hash
+
+		if err := checkOrganisations(key.Organisations); err != nil {
+			return nil, fmt.Errorf("API key %q: %v", key.Name, err)
+		}
+
+		if len(key.Scopes) == 0 {
+			return nil, fmt.Errorf("API key %q: no scopes defined", key.Name)
+		}
+	}
+
+	return &apiKeyAuthenticator{keys: keys, hashes: hashes}, nil
+}
+
+// Authenticate returns a principal named after the key that matches token
+func (a *apiKeyAuthenticator) Authenticate(token string) (*service.Principal, error) {
+	sum := sha256.Sum256([]byte(token))
+	// Check every key to avoid leaking which one matched through timing
+	match := -1
+	for i, hash := range a.hashes {
+		if subtle.ConstantTimeCompare(sum[:], hash) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	key := a.keys[match]
+	return &service.Principal{
+		Subject:       key.Name,
+		Organisations: key.Organisations,
+		Scopes:        key.Scopes,
+	}, nil
+}
+
+// checkOrganisations checks that a principal belongs to at least one
+// organisation and that every organisation ID is a valid UUID
+func checkOrganisations(orgs []strfmt.UUID) error {
+	if len(orgs) == 0 {
+		return fmt.Errorf("no organisations defined")
+	}
+
+	for _, org := range orgs {
+		if !strfmt.IsUUID(org.String()) {
+			return fmt.Errorf("invalid organisation ID %q", org)
+		}
+	}
+
+	return nil
+}
+
+// jwtConfig holds the keys and the claims used to verify JWTs
+type jwtConfig struct {
+	// HMACKeyPath is the path of a file that contains the secret used to
+	// verify HS256 signatures
+	HMACKeyPath string
+	// RSAKeyPath is the path of a PEM file that contains the public key used
+	// to verify RS256 signatures
+	RSAKeyPath string
+	// Issuer, when set, must match the "iss" claim of tokens
+	Issuer string
+	// Audience, when set, must be included in the "aud" claim of tokens
+	Audience string
+}
+
+// jwtAuthenticator authenticates requests using JWTs signed with HS256 or RS256
+type jwtAuthenticator struct {
+	hmacKey  []byte
+	rsaKey   *rsa.PublicKey
+	issuer   string
+	audience string
+}
+
+// newJWTAuthenticator creates an authenticator that verifies JWTs with the keys
+// specified in conf. At least one of the keys must be provided
+func newJWTAuthenticator(conf *jwtConfig) (*jwtAuthenticator, error) {
+	if conf.HMACKeyPath == "" && conf.RSAKeyPath == "" {
+		return nil, fmt.Errorf("no JWT keys defined")
+	}
+
+	a := &jwtAuthenticator{
+		issuer:   conf.Issuer,
+		audience: conf.Audience,
+	}
+
+	if conf.HMACKeyPath != "" {
+		key, err := ioutil.ReadFile(conf.HMACKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading HS256 key: %v", err)
+		}
+		a.hmacKey = []byte(strings.TrimSpace(string(key)))
+		if len(a.hmacKey) == 0 {
+			return nil, fmt.Errorf("HS256 key is empty")
+		}
+	}
+
+	if conf.RSAKeyPath != "" {
+		pem, err := ioutil.ReadFile(conf.RSAKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading RS256 key: %v", err)
+		}
+		a.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing RS256 key: %v", err)
+		}
+	}
+
+	return a, nil
+}
+
+// Authenticate verifies the signature and the claims of token and returns
+// a principal for its subject. The scopes of the principal are read from the
+// "scope" claim, a space separated list of scopes as used in OAuth 2.0
+func (a *jwtAuthenticator) Authenticate(token string) (*service.Principal, error) {
+	claims := jwt.MapClaims{}
+	// Tokens are only accepted if they are signed with the algorithm the
+	// key is meant for, so that a public RSA key can't be used as an HMAC secret
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method {
+		case jwt.SigningMethodHS256:
+			if a.hmacKey != nil {
+				return a.hmacKey, nil
+			}
+		case jwt.SigningMethodRS256:
+			if a.rsaKey != nil {
+				return a.rsaKey, nil
+			}
+		}
+		return nil, fmt.Errorf("unexpected signing method %q", t.Header["alg"])
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %v", err)
+	}
+
+	// Expired tokens are rejected by ParseWithClaims, but tokens without an
+	// expiration time would be valid forever
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, fmt.Errorf("invalid token: missing expiration time")
+	}
+	if a.issuer != "" && !claims.VerifyIssuer(a.issuer, true) {
+		return nil, fmt.Errorf("invalid token: unexpected issuer")
+	}
+	if a.audience != "" && !hasAudience(claims, a.audience) {
+		return nil, fmt.Errorf("invalid token: unexpected audience")
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("invalid token: missing subject")
+	}
+
+	orgs, err := organisationsClaim(claims)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %v", err)
+	}
+
+	scope, _ := claims["scope"].(string)
+
+	return &service.Principal{
+		Subject:       subject,
+		Organisations: orgs,
+		Scopes:        strings.Fields(scope),
+	}, nil
+}
+
+// organisationsClaim reads the "organisations" claim, which must be an array
+// with the IDs of the organisations the subject of the token belongs to
+func organisationsClaim(claims jwt.MapClaims) ([]strfmt.UUID, error) {
+	raw, ok := claims["organisations"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing organisations")
+	}
+
+	orgs := make([]strfmt.UUID, 0, len(raw))
+	for _, org := range raw {
+		id, ok := org.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid organisation ID %v", org)
+		}
+		orgs = append(orgs, strfmt.UUID(id))
+	}
+
+	if err := checkOrganisations(orgs); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// hasAudience checks whether the "aud" claim, which may be a single string or
+// an array of them, includes audience
+func hasAudience(claims jwt.MapClaims, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clientCertPrincipal returns a principal for a client certificate verified
+// during the TLS handshake. Certificates hold the same information as API keys:
+// the common name of the subject identifies the client, while the organisation
+// (O) and organisational unit (OU) entries of the subject hold the IDs of the
+// organisations of the client and the scopes granted to it, respectively.
+// Errors are always 401 errors, as in newAuthFunc
+func clientCertPrincipal(cert *x509.Certificate) (interface{}, error) {
+	subject := cert.Subject
+	if subject.CommonName == "" {
+		return nil, errors.New(401, "invalid client certificate: missing common name")
+	}
+
+	orgs := make([]strfmt.UUID, len(subject.Organization))
+	for i, org := range subject.Organization {
+		orgs[i] = strfmt.UUID(org)
+	}
+	if err := checkOrganisations(orgs); err != nil {
+		return nil, errors.New(401, "invalid client certificate: %v", err)
+	}
+
+	return &service.Principal{
+		Subject:       subject.CommonName,
+		Organisations: orgs,
+		Scopes:        subject.OrganizationalUnit,
+	}, nil
+}
+
+// clientCertAuthenticator authenticates requests the next authenticator doesn't apply to
+// using the verified client certificate of their TLS connection, if any.
+func clientCertAuthenticator(next runtime.Authenticator, auth func(*x509.Certificate) (interface{}, error)) runtime.Authenticator {
+	return runtime.AuthenticatorFunc(func(params interface{}) (bool, interface{}, error) {
+		if applies, principal, err := next.Authenticate(params); applies {
+			return applies, principal, err
+		}
+
+		var req *http.Request
+		switch p := params.(type) {
+		case *http.Request:
+			req = p
+		case *security.ScopedAuthRequest:
+			req = p.Request
+		}
+		if req == nil || req.TLS == nil || len(req.TLS.VerifiedChains) == 0 {
+			return false, nil, nil
+		}
+
+		principal, err := auth(req.TLS.VerifiedChains[0][0])
+		return true, principal, err
+	})
+}
+
+// newAuthFunc adapts an authenticator to the functions used by the generated API
+// to authenticate requests. prefix is removed from the token before
+// authenticating it, and a nil authenticator rejects every request.
+// Errors are always 401 errors, as any other error would be reported
+// to clients as an internal server error
+func newAuthFunc(a authenticator, prefix string) func(string) (interface{}, error) {
+	return func(token string) (interface{}, error) {
+		if a == nil {
+			return nil, errors.Unauthenticated("scheme not supported")
+		}
+
+		if prefix != "" {
+			if !strings.HasPrefix(token, prefix) {
+				return nil, errors.New(401, "invalid credentials")
+			}
+			token = strings.TrimPrefix(token, prefix)
+		}
+
+		principal, err := a.Authenticate(token)
+		if err != nil {
+			return nil, errors.New(401, err.Error())
+		}
+
+		return principal, nil
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	jwt "github.com/golang-jwt/jwt"
+
+	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi"
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+const (
+	testOrg  strfmt.UUID = "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb"
+	otherOrg strfmt.UUID = "2f1f6f9e-6b0c-4a3e-9d55-5b1f4d4c3e2a"
+)
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeTempFile(t *testing.T, dir, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("Error writing %s: %v", name, err)
+	}
+	return path
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-auth")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keysFile := `[
+		{"name": "billing", "sha256": "` + hashKey("billing-key") + `", "organisations": ["` + testOrg.String() + `"], "scopes": ["payments:read"]},
+		{"name": "reporting", "sha256": "` + hashKey("reporting-key") + `", "organisations": ["` + otherOrg.String() + `"], "scopes": ["payments:read"]}
+	]`
+	path := writeTempFile(t, dir, "apikeys.json", []byte(keysFile))
+
+	a, err := loadAPIKeys(path)
+	if err != nil {
+		t.Fatalf("Error loading API keys: %v", err)
+	}
+
+	tests := map[string]struct {
+		token       string
+		wantSubject string
+		wantOrg     strfmt.UUID
+		wantErr     bool
+	}{
+		"first key": {
+			token:       "billing-key",
+			wantSubject: "billing",
+			wantOrg:     testOrg,
+		},
+		"second key": {
+			token:       "reporting-key",
+			wantSubject: "reporting",
+			wantOrg:     otherOrg,
+		},
+		"unknown key": {
+			token:   "unknown-key",
+			wantErr: true,
+		},
+		"empty key": {
+			token:   "",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			principal, err := a.Authenticate(tc.token)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error but got principal %+v", principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if principal.Subject != tc.wantSubject {
+				t.Errorf("Want subject %q but got %q", tc.wantSubject, principal.Subject)
+			}
+			if len(principal.Organisations) != 1 || principal.Organisations[0] != tc.wantOrg {
+				t.Errorf("Want organisations [%s] but got %v", tc.wantOrg, principal.Organisations)
+			}
+			if !reflect.DeepEqual(principal.Scopes, []string{"payments:read"}) {
+				t.Errorf("Want scopes [payments:read] but got %v", principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorInvalidKeys(t *testing.T) {
+	orgs := []strfmt.UUID{testOrg}
+	scopes := []string{"payments:read"}
+	tests := map[string][]apiKey{
+		"no keys":              {},
+		"missing name":         {{SHA256: hashKey("key"), Organisations: orgs, Scopes: scopes}},
+		"bad hash":             {{Name: "key", SHA256: "not-a-hash", Organisations: orgs, Scopes: scopes}},
+		"short hash":           {{Name: "key", SHA256: "abcd", Organisations: orgs, Scopes: scopes}},
+		"missing organisation": {{Name: "key", SHA256: hashKey("key"), Scopes: scopes}},
+		"bad organisation":     {{Name: "key", SHA256: hashKey("key"), Organisations: []strfmt.UUID{"org"}, Scopes: scopes}},
+		"missing scopes":       {{Name: "key", SHA256: hashKey("key"), Organisations: orgs}},
+	}
+
+	for name, keys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newAPIKeyAuthenticator(keys); err == nil {
+				t.Fatal("Expected an error but got nil")
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-auth")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := []byte("top-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating RSA key: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("Error encoding RSA public key: %v", err)
+	}
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+
+	a, err := newJWTAuthenticator(&jwtConfig{
+		HMACKeyPath: writeTempFile(t, dir, "jwt.key", append(secret, '\n')),
+		RSAKeyPath:  writeTempFile(t, dir, "jwt.pem", pubPEM),
+		Issuer:      "https://auth.example.com",
+		Audience:    "papi",
+	})
+	if err != nil {
+		t.Fatalf("Error creating JWT authenticator: %v", err)
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "client-1",
+			"iss": "https://auth.example.com",
+			"aud": "papi",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			// Organisations are decoded from JSON as []interface{}
+			"organisations": []interface{}{testOrg.String()},
+			"scope":         "payments:read payments:write",
+		}
+	}
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("Error signing token: %v", err)
+		}
+		return token
+	}
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := map[string]struct {
+		token      string
+		wantScopes []string
+		wantErr    bool
+	}{
+		"HS256": {
+			token:      sign(jwt.SigningMethodHS256, secret, validClaims()),
+			wantScopes: []string{"payments:read", "payments:write"},
+		},
+		"RS256": {
+			token:      sign(jwt.SigningMethodRS256, rsaKey, validClaims()),
+			wantScopes: []string{"payments:read", "payments:write"},
+		},
+		"audience in array": {
+			token:      sign(jwt.SigningMethodHS256, secret, with("aud", []string{"other", "papi"})),
+			wantScopes: []string{"payments:read", "payments:write"},
+		},
+		// Tokens without scopes are valid, but can't be used for any operation
+		"missing scope": {
+			token:      sign(jwt.SigningMethodHS256, secret, with("scope", nil)),
+			wantScopes: []string{},
+		},
+		"wrong secret": {
+			token:   sign(jwt.SigningMethodHS256, []byte("wrong"), validClaims()),
+			wantErr: true,
+		},
+		"public key used as HMAC secret": {
+			token:   sign(jwt.SigningMethodHS256, pubPEM, validClaims()),
+			wantErr: true,
+		},
+		"unsupported algorithm": {
+			token:   sign(jwt.SigningMethodHS512, secret, validClaims()),
+			wantErr: true,
+		},
+		"expired": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("exp", time.Now().Add(-time.Hour).Unix())),
+			wantErr: true,
+		},
+		"no expiration time": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("exp", nil)),
+			wantErr: true,
+		},
+		"wrong issuer": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("iss", "https://evil.example.com")),
+			wantErr: true,
+		},
+		"wrong audience": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("aud", "other")),
+			wantErr: true,
+		},
+		"missing subject": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("sub", nil)),
+			wantErr: true,
+		},
+		"missing organisations": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("organisations", nil)),
+			wantErr: true,
+		},
+		"empty organisations": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("organisations", []string{})),
+			wantErr: true,
+		},
+		"invalid organisation": {
+			token:   sign(jwt.SigningMethodHS256, secret, with("organisations", []string{"org"})),
+			wantErr: true,
+		},
+		"garbage": {
+			token:   "not.a.token",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			principal, err := a.Authenticate(tc.token)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error but got principal %+v", principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if principal.Subject != "client-1" {
+				t.Errorf("Want subject %q but got %q", "client-1", principal.Subject)
+			}
+			if len(principal.Organisations) != 1 || principal.Organisations[0] != testOrg {
+				t.Errorf("Want organisations [%s] but got %v", testOrg, principal.Organisations)
+			}
+			if !reflect.DeepEqual(principal.Scopes, tc.wantScopes) {
+				t.Errorf("Want scopes %v but got %v", tc.wantScopes, principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestAuthFunc(t *testing.T) {
+	keys, err := newAPIKeyAuthenticator([]apiKey{{
+		Name:          "client",
+		SHA256:        hashKey("secret"),
+		Organisations: []strfmt.UUID{testOrg},
+		Scopes:        []string{"payments:read"},
+	}})
+	if err != nil {
+		t.Fatalf("Error creating API key authenticator: %v", err)
+	}
+
+	tests := map[string]struct {
+		auth        authenticator
+		prefix      string
+		token       string
+		wantSubject string
+	}{
+		"valid": {
+			auth:        keys,
+			token:       "secret",
+			wantSubject: "client",
+		},
+		"valid with prefix": {
+			auth:        keys,
+			prefix:      "Bearer ",
+			token:       "Bearer secret",
+			wantSubject: "client",
+		},
+		"missing prefix": {
+			auth:   keys,
+			prefix: "Bearer ",
+			token:  "secret",
+		},
+		"invalid": {
+			auth:  keys,
+			token: "wrong",
+		},
+		"not configured": {
+			token: "secret",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			principal, err := newAuthFunc(tc.auth, tc.prefix)(tc.token)
+			if tc.wantSubject == "" {
+				apiErr, ok := err.(errors.Error)
+				if !ok || apiErr.Code() != http.StatusUnauthorized {
+					t.Fatalf("Want a 401 error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := principal.(*service.Principal).Subject; got != tc.wantSubject {
+				t.Errorf("Want subject %q but got %q", tc.wantSubject, got)
+			}
+		})
+	}
+}
+
+func TestAuthenticatedHandler(t *testing.T) {
+	orgs := []strfmt.UUID{testOrg}
+	keys, err := newAPIKeyAuthenticator([]apiKey{
+		{Name: "auditor", SHA256: hashKey("auditor-key"), Organisations: orgs, Scopes: []string{"payments:read"}},
+		{Name: "operator", SHA256: hashKey("operator-key"), Organisations: orgs, Scopes: []string{"payments:read", "payments:write"}},
+	})
+	if err != nil {
+		t.Fatalf("Error creating API key authenticator: %v", err)
+	}
+
+	handler, err := newAPIHandler(restapi.Config{
+		PaymentsAPI: &service.PaymentsService{Repo: service.NewMemPaymentRepository()},
+		AuthAPIKey:  newAuthFunc(keys, ""),
+		AuthBearer:  newAuthFunc(nil, "Bearer "),
+		Authorizer:  service.Authorize,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error creating API handler: %v", err)
+	}
+
+	paymentURL := "/v1/payments/" + testOrg.String()
+	tests := map[string]struct {
+		method   string
+		url      string
+		headers  map[string]string
+		wantCode int
+	}{
+		"no credentials": {
+			wantCode: http.StatusUnauthorized,
+		},
+		"valid API key": {
+			headers: map[string]string{"X-API-Key": "auditor-key"},
+			// The repo is empty, so authenticated requests find no payments
+			wantCode: http.StatusNotFound,
+		},
+		"invalid API key": {
+			headers:  map[string]string{"X-API-Key": "wrong"},
+			wantCode: http.StatusUnauthorized,
+		},
+		"unsupported scheme": {
+			headers:  map[string]string{"Authorization": "Bearer secret"},
+			wantCode: http.StatusUnauthorized,
+		},
+		"delete without scope": {
+			method:   http.MethodDelete,
+			url:      paymentURL,
+			headers:  map[string]string{"X-API-Key": "operator-key"},
+			wantCode: http.StatusForbidden,
+		},
+		"update without scope": {
+			method:   http.MethodPut,
+			url:      paymentURL,
+			headers:  map[string]string{"X-API-Key": "auditor-key"},
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			method, url := tc.method, tc.url
+			if method == "" {
+				method, url = http.MethodGet, "/v1/payments"
+			}
+			req, _ := http.NewRequest(method, url, nil)
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Fatalf("Want %d but got %d: %s", tc.wantCode, resp.Code, resp.Body.String())
+			}
+
+			if resp.Code == http.StatusForbidden {
+				var apiErr models.APIError
+				if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.ErrorCode == "" {
+					t.Errorf("Want an ApiError body but got %v (%v)", apiErr, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/namsral/flag"
+	toml "github.com/pelletier/go-toml"
+	"github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+func init() {
+	// namsral/flag reads the file set with a "config" flag by itself, as a
+	// list of flags. Config files are YAML or TOML documents read by
+	// applyConfigFile instead
+	flag.DefaultConfigFlagname = ""
+}
+
+// config holds the configuration of the server, which is taken from flags, env
+// variables prefixed with PAPI_ and a config file, in order of precedence
+type config struct {
+	// ConfigPath is the path of a YAML or TOML config file
+	ConfigPath string
+
+	Port           int
+	RPS            int64
+	LogLevel       string
+	Backend        string
+	BaseURL        string
+	IdempotencyTTL time.Duration
+
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	MaxBodyBytes   int64
+	ShutdownDelay  time.Duration
+	ShutdownGrace  time.Duration
+	HealthTimeout  time.Duration
+
+	TLS         tlsFiles
+	APIKeysPath string
+	JWT         jwtConfig
+	DB          service.DBConfig
+	Metrics     service.PaymentMetricsConfig
+
+	TraceOutput      string
+	TraceSampleRatio float64
+}
+
+// newFlagSet creates the flags of the server, which are bound to conf. Every
+// flag can be set with an env variable too, such as PAPI_PORT for -port
+func newFlagSet(name string, conf *config, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	// Use "PAPI" as prefix for env variables to avoid potential clashes
+	fs := flag.NewFlagSetWithEnvPrefix(name, "PAPI", errorHandling)
+
+	fs.StringVar(&conf.ConfigPath, "config", "", "Path to a YAML (.yml, .yaml) or TOML (.toml) config file, overridden by env variables and flags")
+
+	fs.IntVar(&conf.Port, "port", 8080, "Port where the server is listening for connections.")
+	fs.Int64Var(&conf.RPS, "rps", 100, "Rate limit expressed in requests per second (per client)")
+	fs.StringVar(&conf.LogLevel, "loglevel", "info", "Minimum level of the entries written to the log ('debug', 'info', 'warn' or 'error')")
+	fs.StringVar(&conf.Backend, "backend", "postgres", "Data backend used to store payments ('postgres' or 'memory')")
+	fs.StringVar(&conf.BaseURL, "baseurl", "", "Public URL of the API used to build links in responses, such as https://api.example.com/v1 (defaults to the URL of each request)")
+	fs.IntVar(&conf.Metrics.MaxOrganisations, "metricsmaxorgs", 100, "Maximum number of distinct organisations payment metrics are labelled with, the rest are labelled as 'other' (0 means no limit)")
+	fs.IntVar(&conf.Metrics.MaxLabelValues, "metricsmaxlabelvalues", 50, "Maximum number of distinct schemes, currencies and scheme payment types payment metrics are labelled with (0 means no limit)")
+	fs.StringVar(&conf.TraceOutput, "traceoutput", "", "Where spans are written as JSON lines, 'stdout' or the path of a file (spans are not exported if empty)")
+	fs.Float64Var(&conf.TraceSampleRatio, "tracesampleratio", 1, "Fraction of new traces that are exported, from 0 to 1 (traces continued from a traceparent header keep its decision)")
+	fs.DurationVar(&conf.IdempotencyTTL, "idempotencyttl", 24*time.Hour, "Time the responses to requests with an Idempotency-Key header are kept for retries")
+
+	fs.DurationVar(&conf.ReadTimeout, "readtimeout", 30*time.Second, "Maximum time to read a request, including its body (0 means no timeout)")
+	fs.DurationVar(&conf.WriteTimeout, "writetimeout", 60*time.Second, "Maximum time to handle a request and write its response (0 means no timeout)")
+	fs.DurationVar(&conf.IdleTimeout, "idletimeout", 120*time.Second, "Maximum time to wait for the next request on a keep-alive connection (0 means the read timeout)")
+	fs.IntVar(&conf.MaxHeaderBytes, "maxheaderbytes", 1<<20, "Maximum size of request headers in bytes")
+	fs.Int64Var(&conf.MaxBodyBytes, "maxbodybytes", 10<<20, "Maximum size of request bodies in bytes, larger bodies get a 413 response")
+	fs.DurationVar(&conf.ShutdownDelay, "shutdowndelay", 0, "Time the server keeps serving requests after failing readiness checks when shutting down")
+	fs.DurationVar(&conf.HealthTimeout, "healthtimeout", 2*time.Second, "Maximum time each health check is allowed to run")
+	fs.DurationVar(&conf.ShutdownGrace, "shutdowngrace", 20*time.Second, "Maximum time to wait for in-flight requests to complete when shutting down")
+
+	fs.StringVar(&conf.TLS.CertPath, "tls-cert", "", "Path to a PEM file with the server certificate, the API is served over TLS if set")
+	fs.StringVar(&conf.TLS.KeyPath, "tls-key", "", "Path to a PEM file with the private key of the server certificate")
+	fs.StringVar(&conf.TLS.ClientCAPath, "tls-client-ca", "", "Path to a PEM file with the CAs client certificates are verified with, API requests can be authenticated with client certificates if set")
+
+	fs.StringVar(&conf.APIKeysPath, "apikeys", "", "Path to a JSON file with the names and SHA-256 hashes of the accepted API keys")
+	fs.StringVar(&conf.JWT.HMACKeyPath, "jwtkey", "", "Path to a file with the secret used to verify HS256 JWTs")
+	fs.StringVar(&conf.JWT.RSAKeyPath, "jwtpubkey", "", "Path to a PEM file with the public key used to verify RS256 JWTs")
+	fs.StringVar(&conf.JWT.Issuer, "jwtissuer", "", "Issuer JWTs must have been issued by (not checked if empty)")
+	fs.StringVar(&conf.JWT.Audience, "jwtaudience", "", "Audience JWTs must have been issued for (not checked if empty)")
+
+	fs.StringVar(&conf.DB.Host, "dbhost", "localhost", "Address of the server that hosts the DB")
+	fs.IntVar(&conf.DB.Port, "dbport", 5432, "Port where the DB server is listening for connections")
+	fs.StringVar(&conf.DB.User, "dbuser", "postgres", "User to use when accessing the DB")
+	fs.StringVar(&conf.DB.Pass, "dbpass", "postgres", "Password to use when accessing the DB")
+	fs.StringVar(&conf.DB.Name, "dbname", "postgres", "Name of the DB to connect to")
+	fs.StringVar(&conf.DB.MigrationsPath, "migrations", "./migrations", "Path to the folder that contains the migration files")
+	fs.DurationVar(&conf.DB.QueryTimeout, "dbtimeout", 10*time.Second, "Maximum time a single DB query is allowed to run (0 means no timeout)")
+
+	return fs
+}
+
+// configKey is a key of a config file, which sets the flag with the same value
+type configKey struct {
+	section string
+	key     string
+	flag    string
+	// secret keys are redacted when the configuration is printed
+	secret bool
+}
+
+// configKeys is the schema of config files, which hold a section for each
+// group of keys. Keys are listed in the order they are printed
+var configKeys = []configKey{
+	{section: "server", key: "port", flag: "port"},
+	{section: "server", key: "base_url", flag: "baseurl"},
+	{section: "server", key: "backend", flag: "backend"},
+	{section: "server", key: "log_level", flag: "loglevel"},
+	{section: "server", key: "read_timeout", flag: "readtimeout"},
+	{section: "server", key: "write_timeout", flag: "writetimeout"},
+	{section: "server", key: "idle_timeout", flag: "idletimeout"},
+	{section: "server", key: "shutdown_delay", flag: "shutdowndelay"},
+	{section: "server", key: "shutdown_grace", flag: "shutdowngrace"},
+	{section: "server", key: "health_timeout", flag: "healthtimeout"},
+
+	{section: "limits", key: "rps", flag: "rps"},
+	{section: "limits", key: "max_header_bytes", flag: "maxheaderbytes"},
+	{section: "limits", key: "max_body_bytes", flag: "maxbodybytes"},
+
+	{section: "tls", key: "cert", flag: "tls-cert"},
+	{section: "tls", key: "key", flag: "tls-key"},
+	{section: "tls", key: "client_ca", flag: "tls-client-ca"},
+
+	{section: "auth", key: "api_keys", flag: "apikeys"},
+	{section: "auth", key: "jwt_key", flag: "jwtkey"},
+	{section: "auth", key: "jwt_public_key", flag: "jwtpubkey"},
+	{section: "auth", key: "jwt_issuer", flag: "jwtissuer"},
+	{section: "auth", key: "jwt_audience", flag: "jwtaudience"},
+
+	{section: "db", key: "host", flag: "dbhost"},
+	{section: "db", key: "port", flag: "dbport"},
+	{section: "db", key: "user", flag: "dbuser"},
+	{section: "db", key: "password", flag: "dbpass", secret: true},
+	{section: "db", key: "name", flag: "dbname"},
+	{section: "db", key: "migrations", flag: "migrations"},
+	{section: "db", key: "query_timeout", flag: "dbtimeout"},
+
+	{section: "idempotency", key: "ttl", flag: "idempotencyttl"},
+
+	{section: "metrics", key: "max_organisations", flag: "metricsmaxorgs"},
+	{section: "metrics", key: "max_label_values", flag: "metricsmaxlabelvalues"},
+
+	{section: "tracing", key: "output", flag: "traceoutput"},
+	{section: "tracing", key: "sample_ratio", flag: "tracesampleratio"},
+}
+
+// redacted replaces the values of secret keys when the configuration is printed
+const redacted = "REDACTED"
+
+// loadConfig parses the configuration of the server from args and env
+// variables, and from the config file set in either of them, if any.
+// Flags take precedence over env variables, and both over the config file
+func loadConfig(name string, args []string, errorHandling flag.ErrorHandling) (*config, *flag.FlagSet, error) {
+	conf := &config{}
+	fs := newFlagSet(name, conf, errorHandling)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if conf.ConfigPath != "" {
+		if err := applyConfigFile(fs, conf.ConfigPath); err != nil {
+			return nil, nil, fmt.Errorf("invalid config file %s: %v", conf.ConfigPath, err)
+		}
+	}
+
+	return conf, fs, nil
+}
+
+// configFormat returns the format of the config file at path from its extension
+func configFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return "yaml", nil
+	case ".toml":
+		return "toml", nil
+	}
+
+	return "", fmt.Errorf("unknown format, the extension must be .yml, .yaml or .toml")
+}
+
+// applyConfigFile sets the flags of fs with the values of the config file at
+// path. Flags that have been set already, by args or env variables, are kept
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+	format, err := configFormat(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	sections := make(map[string]map[string]interface{})
+	switch format {
+	case "yaml":
+		if err := yaml.Unmarshal(data, &sections); err != nil {
+			return err
+		}
+
+	case "toml":
+		tree, err := toml.LoadBytes(data)
+		if err != nil {
+			return err
+		}
+		for name, section := range tree.ToMap() {
+			keys, ok := section.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s is not a section", name)
+			}
+			sections[name] = keys
+		}
+	}
+
+	flags := make(map[string]configKey, len(configKeys))
+	for _, k := range configKeys {
+		flags[k.section+"."+k.key] = k
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for section, keys := range sections {
+		for key, value := range keys {
+			k, ok := flags[section+"."+key]
+			if !ok {
+				return fmt.Errorf("unknown key %s.%s", section, key)
+			}
+
+			switch value.(type) {
+			case string, bool, int, int64, uint64, float64:
+			default:
+				return fmt.Errorf("%s.%s must be a string, a number or a boolean", section, key)
+			}
+
+			if set[k.flag] {
+				continue
+			}
+			if err := fs.Set(k.flag, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf("invalid value for %s.%s: %v", section, key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks the configuration and the files it points to, such as keys
+// and certificates, without connecting to the DB. Every problem found is
+// returned
+func (conf *config) validate() []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, err := logrus.ParseLevel(conf.LogLevel); err != nil {
+		fail("invalid log level %q, it must be 'debug', 'info', 'warn' or 'error'", conf.LogLevel)
+	}
+	if conf.Backend != "postgres" && conf.Backend != "memory" {
+		fail("unknown backend %q, it must be either 'postgres' or 'memory'", conf.Backend)
+	}
+	if conf.BaseURL != "" {
+		if u, err := url.Parse(conf.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			fail("invalid base URL %q, it must be an absolute URL", conf.BaseURL)
+		}
+	}
+	if conf.RPS <= 0 {
+		fail("the rate limit must be positive (rps = %d)", conf.RPS)
+	}
+	if conf.MaxBodyBytes <= 0 {
+		fail("the maximum size of request bodies must be positive (maxbodybytes = %d)", conf.MaxBodyBytes)
+	}
+	if conf.TraceSampleRatio < 0 || conf.TraceSampleRatio > 1 {
+		fail("the trace sample ratio must be between 0 and 1 (tracesampleratio = %v)", conf.TraceSampleRatio)
+	}
+
+	if conf.TLS.CertPath != "" || conf.TLS.KeyPath != "" {
+		if _, err := newTLSReloader(conf.TLS); err != nil {
+			fail("invalid TLS configuration: %v", err)
+		}
+	}
+	if conf.TLS.ClientCAPath != "" && conf.TLS.CertPath == "" {
+		fail("client certificates can only be verified when serving over TLS, provide a server certificate")
+	}
+
+	if conf.APIKeysPath != "" {
+		if _, err := loadAPIKeys(conf.APIKeysPath); err != nil {
+			fail("unable to load API keys: %v", err)
+		}
+	}
+	if conf.JWT.HMACKeyPath != "" || conf.JWT.RSAKeyPath != "" {
+		if _, err := newJWTAuthenticator(&conf.JWT); err != nil {
+			fail("unable to configure JWT verification: %v", err)
+		}
+	}
+	if conf.APIKeysPath == "" && conf.JWT.HMACKeyPath == "" && conf.JWT.RSAKeyPath == "" && conf.TLS.ClientCAPath == "" {
+		fail("no credentials configured, provide API keys, JWT keys or a CA for client certificates")
+	}
+
+	return errs
+}
+
+// printConfig writes the effective configuration held by fs to w as a config
+// file in the given format, with the values of secret keys redacted
+func printConfig(w io.Writer, fs *flag.FlagSet, format string) error {
+	type entry struct {
+		key   string
+		value interface{}
+	}
+	var sections []string
+	entries := make(map[string][]entry)
+
+	for _, k := range configKeys {
+		f := fs.Lookup(k.flag)
+		if f == nil {
+			return fmt.Errorf("unknown flag %s for key %s.%s", k.flag, k.section, k.key)
+		}
+
+		var value interface{} = f.Value.String()
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+		switch v := value.(type) {
+		case time.Duration:
+			value = v.String()
+		case string:
+			if k.secret && v != "" {
+				value = redacted
+			}
+		}
+
+		if _, ok := entries[k.section]; !ok {
+			sections = append(sections, k.section)
+		}
+		entries[k.section] = append(entries[k.section], entry{key: k.key, value: value})
+	}
+
+	switch format {
+	case "toml":
+		buf := &bytes.Buffer{}
+		for i, section := range sections {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			values := make(map[string]interface{})
+			for _, e := range entries[section] {
+				values[e.key] = e.value
+			}
+			tree, err := toml.TreeFromMap(map[string]interface{}{section: values})
+			if err != nil {
+				return err
+			}
+			buf.WriteString(tree.String())
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+
+	default:
+		doc := yaml.MapSlice{}
+		for _, section := range sections {
+			values := yaml.MapSlice{}
+			for _, e := range entries[section] {
+				values = append(values, yaml.MapItem{Key: e.key, Value: e.value})
+			}
+			doc = append(doc, yaml.MapItem{Key: section, Value: values})
+		}
+		out, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	}
+}
+
+// runConfigCommand runs the config subcommands, "validate" and "print", with
+// the given args, which are the flags of the server, and returns the exit code
+func runConfigCommand(name string, args []string, stdout, stderr io.Writer) int {
+	usage := func() int {
+		fmt.Fprintf(stderr, "Usage: %s config validate|print [flags]\n", name)
+		return 2
+	}
+	if len(args) == 0 {
+		return usage()
+	}
+
+	conf, fs, err := loadConfig(name+" config "+args[0], args[1:], flag.ContinueOnError)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "validate":
+		errs := conf.validate()
+		for _, err := range errs {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+		}
+		if len(errs) > 0 {
+			return 1
+		}
+		fmt.Fprintln(stdout, "Configuration is valid")
+		return 0
+
+	case "print":
+		// Print in the format of the config file, so that it can be reused
+		format := "yaml"
+		if conf.ConfigPath != "" {
+			format, _ = configFormat(conf.ConfigPath)
+		}
+		if err := printConfig(stdout, fs, format); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	return usage()
+}
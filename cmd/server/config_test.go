@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/namsral/flag"
+)
+
+func TestConfigKeys(t *testing.T) {
+	fs := newFlagSet("test", &config{}, flag.ContinueOnError)
+
+	keys := make(map[string]bool)
+	for _, k := range configKeys {
+		if fs.Lookup(k.flag) == nil {
+			t.Errorf("want a flag for key %s.%s but %s does not exist", k.section, k.key, k.flag)
+		}
+		if keys[k.section+"."+k.key] {
+			t.Errorf("want unique keys but %s.%s is repeated", k.section, k.key)
+		}
+		keys[k.section+"."+k.key] = true
+	}
+
+	flags := make(map[string]bool)
+	for _, k := range configKeys {
+		flags[k.flag] = true
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name != "config" && !flags[f.Name] {
+			t.Errorf("want a config key for flag %s", f.Name)
+		}
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	yamlFile := `
+server:
+  port: 9090
+  backend: memory
+  read_timeout: 5s
+limits:
+  rps: 10
+db:
+  host: db.example.com
+  password: s3cr3t
+tracing:
+  sample_ratio: 0.5
+`
+	tomlFile := `
+[server]
+port = 9090
+backend = "memory"
+read_timeout = "5s"
+
+[limits]
+rps = 10
+
+[db]
+host = "db.example.com"
+password = "s3cr3t"
+
+[tracing]
+sample_ratio = 0.5
+`
+	files := map[string]string{
+		"yaml": writeTempFile(t, dir, "papi.yml", []byte(yamlFile)),
+		"toml": writeTempFile(t, dir, "papi.toml", []byte(tomlFile)),
+	}
+
+	for format, path := range files {
+		t.Run(format, func(t *testing.T) {
+			conf, _, err := loadConfig("test", []string{"-config", path}, flag.ContinueOnError)
+			if err != nil {
+				t.Fatalf("Error loading config: %v", err)
+			}
+
+			if conf.Port != 9090 || conf.Backend != "memory" || conf.ReadTimeout != 5*time.Second || conf.RPS != 10 {
+				t.Errorf("want the values of the server and limits sections but got %+v", conf)
+			}
+			if conf.DB.Host != "db.example.com" || conf.DB.Pass != "s3cr3t" || conf.TraceSampleRatio != 0.5 {
+				t.Errorf("want the values of the db and tracing sections but got %+v", conf)
+			}
+			// Keys missing from the file keep their defaults
+			if conf.DB.Port != 5432 || conf.LogLevel != "info" {
+				t.Errorf("want the defaults of missing keys but got %+v", conf)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeTempFile(t, dir, "papi.yml", []byte("server:\n  port: 9090\n  log_level: debug\n  backend: memory\n"))
+
+	os.Setenv("PAPI_PORT", "9091")
+	os.Setenv("PAPI_LOGLEVEL", "warn")
+	defer os.Unsetenv("PAPI_PORT")
+	defer os.Unsetenv("PAPI_LOGLEVEL")
+
+	conf, _, err := loadConfig("test", []string{"-config", path, "-port", "9092"}, flag.ContinueOnError)
+	if err != nil {
+		t.Fatalf("Error loading config: %v", err)
+	}
+
+	if conf.Port != 9092 {
+		t.Errorf("want flags to override env variables but got port %d", conf.Port)
+	}
+	if conf.LogLevel != "warn" {
+		t.Errorf("want env variables to override the config file but got log level %q", conf.LogLevel)
+	}
+	if conf.Backend != "memory" {
+		t.Errorf("want the config file to override defaults but got backend %q", conf.Backend)
+	}
+}
+
+func TestLoadConfigInvalidFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		name    string
+		content string
+	}{
+		"unknown key": {
+			name:    "papi.yml",
+			content: "server:\n  prot: 9090\n",
+		},
+		"unknown section": {
+			name:    "papi.toml",
+			content: "[database]\nhost = \"localhost\"\n",
+		},
+		"invalid value": {
+			name:    "papi.yml",
+			content: "server:\n  read_timeout: soon\n",
+		},
+		"nested value": {
+			name:    "papi.yml",
+			content: "db:\n  host:\n    name: localhost\n",
+		},
+		"unknown format": {
+			name:    "papi.json",
+			content: "{}",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeTempFile(t, dir, tc.name, []byte(tc.content))
+			if _, _, err := loadConfig("test", []string{"-config", path}, flag.ContinueOnError); err == nil {
+				t.Errorf("want an error loading %q", tc.content)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	keys := writeTempFile(t, dir, "apikeys.json", []byte(`[{"name": "billing", "sha256": "`+hashKey("billing-key")+`", "organisations": ["`+testOrg.String()+`"], "scopes": ["payments:read"]}]`))
+
+	tests := map[string]struct {
+		args     []string
+		wantErrs int
+	}{
+		"valid": {
+			args: []string{"-apikeys", keys},
+		},
+		"no credentials": {
+			wantErrs: 1,
+		},
+		"several errors": {
+			args:     []string{"-apikeys", keys, "-backend", "mongo", "-loglevel", "loud", "-tracesampleratio", "2"},
+			wantErrs: 3,
+		},
+		"missing files": {
+			args:     []string{"-apikeys", filepath.Join(dir, "missing.json"), "-tls-cert", filepath.Join(dir, "missing.pem")},
+			wantErrs: 2,
+		},
+		"client CA without TLS": {
+			args:     []string{"-tls-client-ca", keys},
+			wantErrs: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf, _, err := loadConfig("test", tc.args, flag.ContinueOnError)
+			if err != nil {
+				t.Fatalf("Error loading config: %v", err)
+			}
+
+			if errs := conf.validate(); len(errs) != tc.wantErrs {
+				t.Errorf("want %d errors but got %v", tc.wantErrs, errs)
+			}
+		})
+	}
+}
+
+func TestConfigCommandPrint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-config")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"papi.yml", "papi.toml"} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTempFile(t, dir, name, nil)
+
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			args := []string{"print", "-config", path, "-dbpass", "s3cr3t", "-port", "9090", "-idempotencyttl", "1h"}
+			if code := runConfigCommand("test", args, stdout, stderr); code != 0 {
+				t.Fatalf("want exit code 0 but got %d: %s", code, stderr)
+			}
+
+			out := stdout.String()
+			if strings.Contains(out, "s3cr3t") || !strings.Contains(out, redacted) {
+				t.Errorf("want the DB password redacted but got:\n%s", out)
+			}
+
+			// The output is a valid config file with the effective configuration
+			writeTempFile(t, dir, name, stdout.Bytes())
+			conf, _, err := loadConfig("test", []string{"-config", path}, flag.ContinueOnError)
+			if err != nil {
+				t.Fatalf("Error loading printed config:\n%s\n%v", out, err)
+			}
+			if conf.Port != 9090 || conf.IdempotencyTTL != time.Hour || conf.DB.Pass != redacted {
+				t.Errorf("want the printed values loaded back but got %+v", conf)
+			}
+		})
+	}
+}
+
+func TestConfigCommandValidate(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		wantCode int
+	}{
+		"valid": {
+			args: []string{"validate", "-backend", "memory", "-jwtkey", "config_test.go"},
+		},
+		"invalid": {
+			args:     []string{"validate", "-backend", "mongo"},
+			wantCode: 1,
+		},
+		"unknown flag": {
+			args:     []string{"validate", "-prot", "9090"},
+			wantCode: 1,
+		},
+		"unknown subcommand": {
+			args:     []string{"check"},
+			wantCode: 2,
+		},
+		"no subcommand": {
+			wantCode: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			if code := runConfigCommand("test", tc.args, stdout, stderr); code != tc.wantCode {
+				t.Errorf("want exit code %d but got %d: %s", tc.wantCode, code, stderr)
+			}
+		})
+	}
+}
@@ -1,77 +1,163 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/go-openapi/errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	httpmetrics "github.com/slok/go-http-metrics/metrics"
 	metrics "github.com/slok/go-http-metrics/metrics/prometheus"
 	"github.com/slok/go-http-metrics/middleware"
 	"github.com/ulule/limiter/v3"
 	"github.com/ulule/limiter/v3/drivers/middleware/stdlib"
 	"github.com/ulule/limiter/v3/drivers/store/memory"
-	"github.com/unrolled/recovery"
+
+	"github.com/volmedo/pAPI/pkg/service"
 )
 
 // newMeasuredHandler creates a middleware that take essential metrics about
 // the handler being measured, such as number of requests, duration of each request,
-// concurrent or in-flight requests and response size.
-// This function returns two handlers, the handler being measured and a Prometheus
-// handler that exposes the metrics being collected
-func newMeasuredHandler(handler http.Handler) (measuredH http.Handler, metricsH http.Handler) {
+// concurrent or in-flight requests and response size. Metrics are registered
+// with reg.
+//
+// The duration and size of requests are labelled with the ID of the operation
+// that handled them, which is recorded by recordRoute. Requests that don't
+// match any operation are labelled as "unmatched". In-flight requests are
+// counted before routing, so they are only measured for the whole API
+func newMeasuredHandler(reg prometheus.Registerer, handler http.Handler) http.Handler {
 	recorder := metrics.NewRecorder(metrics.Config{
-		Prefix: "pAPI",
+		Prefix:   "pAPI",
+		Registry: reg,
 	})
 	mdlw := middleware.New(middleware.Config{
-		Recorder: recorder,
+		Recorder: operationRecorder{Recorder: recorder},
+	})
+	measured := mdlw.Handler("api", handler)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The operation is recorded in the request info
+		_, r = withAccessInfo(r)
+		measured.ServeHTTP(w, r)
 	})
+}
+
+// operationRecorder is a metrics recorder that labels the duration and size of
+// requests with the ID of the operation that handled them
+type operationRecorder struct {
+	httpmetrics.Recorder
+}
 
-	return mdlw.Handler("", handler), promhttp.Handler()
+// operationID returns the ID of the operation that handled the request with ctx
+func operationID(ctx context.Context) string {
+	if info, ok := ctx.Value(accessInfoKey{}).(*accessInfo); ok && info.operation != "" {
+		return info.operation
+	}
+
+	return "unmatched"
+}
+
+// ObserveHTTPRequestDuration measures the duration of a request to an operation
+func (rec operationRecorder) ObserveHTTPRequestDuration(ctx context.Context, id string, duration time.Duration, method, code string) {
+	rec.Recorder.ObserveHTTPRequestDuration(ctx, operationID(ctx), duration, method, code)
+}
+
+// ObserveHTTPResponseSize measures the size of the response of an operation
+func (rec operationRecorder) ObserveHTTPResponseSize(ctx context.Context, id string, sizeBytes int64, method, code string) {
+	rec.Recorder.ObserveHTTPResponseSize(ctx, operationID(ctx), sizeBytes, method, code)
 }
 
 // newRateLimitedHandler creates a new middleware based on ulule/limiter package that
 // limits the request rate that is sent to the specified handler.
 // The returned rate-limited handler will allow up to rps requests per second to
 // handler. When the rate exceeds the limit, a "429 Too Many Requests" response will be
-// sent back without invoking the wrapped handler.
-func newRateLimitedHandler(rps int64, handler http.Handler) (http.Handler, error) {
+// sent back as an ApiError without invoking the wrapped handler. Rejected
+// requests are counted in a metric registered with reg
+func newRateLimitedHandler(rps int64, reg prometheus.Registerer, handler http.Handler) (http.Handler, error) {
 	if rps <= 0 {
 		return nil, fmt.Errorf("rps cannot be negative (rps = %d)", rps)
 	}
 
+	rejected := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "pAPI",
+		Subsystem: "http",
+		Name:      "rate_limited_requests_total",
+		Help:      "The number of requests rejected because the rate limit was reached.",
+	})
+	if err := reg.Register(rejected); err != nil {
+		return nil, err
+	}
+
 	store := memory.NewStore()
 	rate := limiter.Rate{
 		Period: time.Second,
 		Limit:  rps,
 	}
 	instance := limiter.New(store, rate)
-	middleware := stdlib.NewMiddleware(instance)
+	middleware := stdlib.NewMiddleware(instance, stdlib.WithLimitReachedHandler(func(w http.ResponseWriter, r *http.Request) {
+		rejected.Inc()
+		service.ServeError(w, r, errors.New(http.StatusTooManyRequests, "rate limit of %d requests per second exceeded", rps))
+	}))
 
 	return middleware.Handler(handler), nil
 }
 
-// newRecoveredHandler adds a basic panic recovery middleware so that clients
-// get a 500 Internal Server Error when something goes wrong
-func newRecoverableHandler(handler http.Handler) http.Handler {
-	rec := recovery.New()
-	return rec.Handler(handler)
-}
+// newBodyLimitedHandler creates a middleware that rejects requests whose body
+// is larger than limit bytes with a "413 Request Entity Too Large" response.
+// Requests that declare a larger Content-Length are rejected right away. For
+// the rest, reading past the limit fails with an error that the API reports
+// as a 413 response too
+func newBodyLimitedHandler(limit int64, handler http.Handler) (http.Handler, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("the body size limit must be positive (limit = %d)", limit)
+	}
 
-// newHealthHandler returns a basic health endpoint that can be used in readiness
-// and liveness probes. It checks moving parts to report general availability
-// of the service (currently, the connection with the DB is the only moving part).
-// The health endpoint returns a 200 response when the service is available
-// and a 500 one when it is not working correctly
-func newHealthHandler(db *sql.DB) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if err := db.Ping(); err != nil {
-			w.WriteHeader(500)
-			fmt.Fprintf(w, "cannot connect with DB: %v", err)
-		} else {
-			w.WriteHeader(200)
-			fmt.Fprint(w, "ok")
+		if r.ContentLength > limit {
+			service.ServeError(w, r, errBodyTooLarge(limit))
+			return
 		}
-	})
+
+		r.Body = &limitedBody{ReadCloser: r.Body, limit: limit, remaining: limit}
+		handler.ServeHTTP(w, r)
+	}), nil
+}
+
+// errBodyTooLarge returns the error reported for bodies larger than limit bytes
+func errBodyTooLarge(limit int64) error {
+	return errors.New(http.StatusRequestEntityTooLarge, "request body larger than %d bytes", limit)
+}
+
+// limitedBody is a request body that fails with errBodyTooLarge when more than
+// remaining bytes are read from it
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+// Read reads from the body, failing if the limit is exceeded. One byte more
+// than remaining is read to tell bodies that end right at the limit from
+// those that are larger
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, errBodyTooLarge(b.limit)
+	}
+
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = -1
+		return n, errBodyTooLarge(b.limit)
+	}
+	b.remaining -= int64(n)
+
+	return n, err
 }
@@ -1,50 +1,140 @@
 package main
 
 import (
-	"database/sql"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
-	"github.com/DATA-DOG/go-sqlmock"
-	_ "github.com/lib/pq"
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi"
+	"github.com/volmedo/pAPI/pkg/service"
 )
 
-func TestHealth(t *testing.T) {
-	goodConn, _, err := sqlmock.New()
+func TestBodyLimit(t *testing.T) {
+	if _, err := newBodyLimitedHandler(0, http.NotFoundHandler()); err == nil {
+		t.Error("Expected an error creating a handler without limit")
+	}
+
+	keys, err := newAPIKeyAuthenticator([]apiKey{{
+		Name:          "operator",
+		SHA256:        hashKey("operator-key"),
+		Organisations: []strfmt.UUID{testOrg},
+		Scopes:        []string{"payments:write"},
+	}})
 	if err != nil {
-		t.Fatalf("Error creating mock connection: %v", err)
+		t.Fatalf("Error creating API key authenticator: %v", err)
 	}
 
-	badConn, err := sql.Open("postgres", "host=bad.host")
+	apiHandler, err := newAPIHandler(restapi.Config{
+		PaymentsAPI: &service.PaymentsService{Repo: service.NewMemPaymentRepository()},
+		AuthAPIKey:  newAuthFunc(keys, ""),
+		AuthBearer:  newAuthFunc(nil, "Bearer "),
+		Authorizer:  service.Authorize,
+	}, nil)
 	if err != nil {
-		t.Fatalf("Error creating bad connection: %v", err)
+		t.Fatalf("Error creating API handler: %v", err)
 	}
 
+	// Big enough for the request line, small enough for any payment
+	const limit = 64
+	handler, err := newBodyLimitedHandler(limit, apiHandler)
+	if err != nil {
+		t.Fatalf("Error creating body limited handler: %v", err)
+	}
+
+	body := `{"data":{"type":"Payment","attributes":{"amount":"` + strings.Repeat("1", limit) + `"}}}`
 	tests := map[string]struct {
-		conn     *sql.DB
-		wantCode int
+		contentLength    int64
+		transferEncoding []string
 	}{
-		"healthy": {
-			conn:     goodConn,
-			wantCode: http.StatusOK,
+		"declared length": {
+			contentLength: int64(len(body)),
 		},
-		"unhealthy": {
-			conn:     badConn,
-			wantCode: http.StatusInternalServerError,
+		"chunked": {
+			contentLength:    -1,
+			transferEncoding: []string{"chunked"},
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+			req, _ := http.NewRequest(http.MethodPost, "/v1/payments", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/vnd.api+json")
+			req.Header.Set("X-API-Key", "operator-key")
+			req.ContentLength = tc.contentLength
+			req.TransferEncoding = tc.transferEncoding
 			resp := httptest.NewRecorder()
-			handler := newHealthHandler(tc.conn)
 			handler.ServeHTTP(resp, req)
 
-			if resp.Code != tc.wantCode {
-				t.Fatalf("want %d but got %d", tc.wantCode, resp.Code)
+			if resp.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("want %d but got %d: %s", http.StatusRequestEntityTooLarge, resp.Code, resp.Body)
+			}
+
+			var apiErr models.APIError
+			raw, _ := ioutil.ReadAll(resp.Body)
+			if err := json.Unmarshal(raw, &apiErr); err != nil || apiErr.ErrorMessage == "" {
+				t.Errorf("want an ApiError body but got %s", raw)
 			}
 		})
 	}
 }
+
+func TestMetrics(t *testing.T) {
+	apiHandler, err := newAPIHandler(restapi.Config{
+		PaymentsAPI:     &service.PaymentsService{Repo: service.NewMemPaymentRepository()},
+		InnerMiddleware: recordRoute,
+		AuthAPIKey:      newAuthFunc(nil, ""),
+		AuthBearer:      newAuthFunc(nil, "Bearer "),
+		Authorizer:      service.Authorize,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error creating API handler: %v", err)
+	}
+
+	reg := prometheus.NewRegistry()
+	handler, err := newRateLimitedHandler(1, reg, newMeasuredHandler(reg, apiHandler))
+	if err != nil {
+		t.Fatalf("Error creating rate limited handler: %v", err)
+	}
+
+	// The second request exceeds the limit
+	for _, path := range []string{"/v1/payments/" + testOrg.String(), "/v1/payments"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Error gathering metrics: %v", err)
+	}
+
+	var operations []string
+	var rejected float64
+	for _, family := range families {
+		switch family.GetName() {
+		case "pAPI_http_request_duration_seconds":
+			for _, m := range family.GetMetric() {
+				for _, label := range m.GetLabel() {
+					if label.GetName() == "handler" {
+						operations = append(operations, label.GetValue())
+					}
+				}
+			}
+		case "pAPI_http_rate_limited_requests_total":
+			rejected = family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+
+	if len(operations) != 1 || operations[0] != "getPayment" {
+		t.Errorf("Want the duration of getPayment measured but got %v", operations)
+	}
+	if rejected != 1 {
+		t.Errorf("Want 1 rejected request but got %v", rejected)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// healthChecks is a set of named health checks. Dependencies of the server
+// register their own checks, so that health endpoints don't need to know them
+type healthChecks struct {
+	// timeout is the maximum time each check is allowed to run
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]service.HealthCheck
+}
+
+// newHealthChecks creates an empty set of checks that time out after timeout
+func newHealthChecks(timeout time.Duration) *healthChecks {
+	return &healthChecks{timeout: timeout, checks: make(map[string]service.HealthCheck)}
+}
+
+// Register adds check to the set under name, replacing any check registered
+// with the same name
+func (hc *healthChecks) Register(name string, check service.HealthCheck) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	hc.checks[name] = check
+}
+
+// checkResult holds the outcome of a single check
+type checkResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// healthReport holds the outcome of every check in a set. Its status is the
+// worst status of its checks
+type healthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// run runs every check in the set concurrently and returns the report
+func (hc *healthChecks) run(ctx context.Context) *healthReport {
+	hc.mu.RLock()
+	names := make([]string, 0, len(hc.checks))
+	for name := range hc.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]service.HealthCheck, len(names))
+	for i, name := range names {
+		checks[i] = hc.checks[name]
+	}
+	hc.mu.RUnlock()
+
+	results := make([]checkResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check service.HealthCheck) {
+			defer wg.Done()
+			results[i] = hc.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := &healthReport{Status: checkPass, Checks: make(map[string]checkResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		switch results[i].Status {
+		case checkFail:
+			report.Status = checkFail
+		case checkWarn:
+			if report.Status == checkPass {
+				report.Status = checkWarn
+			}
+		}
+	}
+
+	return report
+}
+
+// runCheck runs a single check, which fails if it doesn't finish in time
+func (hc *healthChecks) runCheck(ctx context.Context, check service.HealthCheck) checkResult {
+	if hc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hc.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check did not finish in time: %v", ctx.Err())
+	}
+
+	result := checkResult{
+		Status:    checkPass,
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		result.Status = checkFail
+		if _, ok := err.(service.ErrDegraded); ok {
+			result.Status = checkWarn
+		}
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// newHealthHandler returns a health endpoint that runs checks and writes a JSON
+// report with the status and latency of each of them. It returns a 200 response
+// when the checks pass, even with warnings, and a 503 one when any of them fails
+func newHealthHandler(checks *healthChecks) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := checks.run(r.Context())
+
+		status := http.StatusOK
+		if report.Status == checkFail {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		// Writing the report can only fail if the client went away
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// drainer tells whether the server is shutting down, so that readiness checks
+// tell load balancers to stop sending requests to it
+type drainer struct {
+	draining int32
+}
+
+// Drain makes every subsequent check fail
+func (d *drainer) Drain() {
+	atomic.StoreInt32(&d.draining, 1)
+}
+
+// Check fails if the server is shutting down
+func (d *drainer) Check(ctx context.Context) error {
+	if atomic.LoadInt32(&d.draining) == 1 {
+		return fmt.Errorf("server is shutting down")
+	}
+
+	return nil
+}
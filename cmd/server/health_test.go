@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/lib/pq"
+
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+func TestHealth(t *testing.T) {
+	goodConn, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock connection: %v", err)
+	}
+
+	badConn, err := sql.Open("postgres", "host=bad.host")
+	if err != nil {
+		t.Fatalf("Error creating bad connection: %v", err)
+	}
+
+	tests := map[string]struct {
+		checks     map[string]service.HealthCheck
+		wantCode   int
+		wantStatus string
+	}{
+		"healthy": {
+			checks:     map[string]service.HealthCheck{"db": service.DBPingCheck(goodConn)},
+			wantCode:   http.StatusOK,
+			wantStatus: checkPass,
+		},
+		"unhealthy": {
+			checks:     map[string]service.HealthCheck{"db": service.DBPingCheck(badConn)},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: checkFail,
+		},
+		"degraded": {
+			checks: map[string]service.HealthCheck{
+				"db": service.DBPingCheck(goodConn),
+				"pool": func(ctx context.Context) error {
+					return service.ErrDegraded("all connections in use")
+				},
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: checkWarn,
+		},
+		"timeout": {
+			checks: map[string]service.HealthCheck{
+				"slow": func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				},
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: checkFail,
+		},
+		"no checks": {
+			wantCode:   http.StatusOK,
+			wantStatus: checkPass,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			checks := newHealthChecks(50 * time.Millisecond)
+			for name, check := range tc.checks {
+				checks.Register(name, check)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+			resp := httptest.NewRecorder()
+			newHealthHandler(checks).ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Fatalf("want %d but got %d", tc.wantCode, resp.Code)
+			}
+
+			var report healthReport
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				t.Fatalf("Error decoding health report: %v", err)
+			}
+			if report.Status != tc.wantStatus || len(report.Checks) != len(tc.checks) {
+				t.Errorf("want status %q with %d checks but got %+v", tc.wantStatus, len(tc.checks), report)
+			}
+			for name, result := range report.Checks {
+				if (result.Status == checkPass) != (result.Error == "") {
+					t.Errorf("want an error only for checks that don't pass but got %+v for %s", result, name)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthDraining(t *testing.T) {
+	drain := &drainer{}
+	checks := newHealthChecks(time.Second)
+	checks.Register("shutdown", drain.Check)
+	handler := newHealthHandler(checks)
+
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("want %d before draining but got %d", http.StatusOK, resp.Code)
+	}
+
+	drain.Drain()
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusServiceUnavailable {
+		t.Fatalf("want %d while draining but got %d", http.StatusServiceUnavailable, resp.Code)
+	}
+}
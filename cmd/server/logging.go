@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"runtime"
+	"time"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+// requestIDHeader is the header that carries the ID of a request, both in
+// requests and in responses
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID matches the request IDs accepted from clients. IDs are
+// limited to a safe set of characters so that they can be logged as they are
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// newLogger creates a logger that writes JSON entries of the given level and
+// above (debug, info, warn or error) to out
+func newLogger(level string, out io.Writer) (*logrus.Logger, error) {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %v", level, err)
+	}
+
+	return &logrus.Logger{
+		Out: out,
+		Formatter: &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+		},
+		Hooks: make(logrus.LevelHooks),
+		Level: lvl,
+	}, nil
+}
+
+// newRequestIDHandler creates a middleware that assigns an ID to each request.
+// The ID is taken from the X-Request-ID header of the request if it is valid,
+// and a new one is generated otherwise. The ID is stored in the context of the
+// request, so that it is added to log entries, and it is echoed in the
+// X-Request-ID header of the response
+func newRequestIDHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			newID, err := uuid.NewV4()
+			if err != nil {
+				// Requests are still served, only without an ID
+				handler.ServeHTTP(w, r)
+				return
+			}
+			id = newID.String()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		handler.ServeHTTP(w, r.WithContext(service.ContextWithRequestID(r.Context(), id)))
+	})
+}
+
+// accessInfo holds the details of a request that are only known once it has
+// gone through the API router and the authentication layer. They are used by
+// the access log and the metrics of the request
+type accessInfo struct {
+	route     string
+	operation string
+	principal string
+}
+
+// accessInfoKey is the key under which accessInfo is stored in the context of a request
+type accessInfoKey struct{}
+
+// accessInfoFrom returns the accessInfo stored in the context of r, or nil if
+// the request is neither being logged nor measured
+func accessInfoFrom(r *http.Request) *accessInfo {
+	info, _ := r.Context().Value(accessInfoKey{}).(*accessInfo)
+	return info
+}
+
+// withAccessInfo returns the accessInfo stored in the context of r, along with
+// r itself. If there is none, a new one is stored in a copy of r that is returned instead
+func withAccessInfo(r *http.Request) (*accessInfo, *http.Request) {
+	if info := accessInfoFrom(r); info != nil {
+		return info, r
+	}
+
+	info := &accessInfo{}
+	return info, r.WithContext(context.WithValue(r.Context(), accessInfoKey{}, info))
+}
+
+// accessRecorder is a response writer that keeps the status and size of the response
+type accessRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// WriteHeader records the status of the response before writing it
+func (ar *accessRecorder) WriteHeader(status int) {
+	if ar.status == 0 {
+		ar.status = status
+	}
+	ar.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written
+func (ar *accessRecorder) Write(b []byte) (int, error) {
+	if ar.status == 0 {
+		ar.status = http.StatusOK
+	}
+	n, err := ar.ResponseWriter.Write(b)
+	ar.bytes += n
+	return n, err
+}
+
+// newAccessLogHandler creates a middleware that logs an entry for every request
+// once it has been handled, with its method, route, status, latency, size of
+// the response and the principal that sent it.
+//
+// The route and the principal are filled in by the middlewares returned by
+// recordRoute and recordPrincipal, as they are only known inside the API handler
+func newAccessLogHandler(logger logrus.FieldLogger, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		info, req := withAccessInfo(r)
+		rec := &accessRecorder{ResponseWriter: w}
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		service.RequestLogger(r.Context(), logger).WithFields(logrus.Fields{
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"route":      info.route,
+			"status":     rec.status,
+			"latency_ms": float64(time.Since(start)) / float64(time.Millisecond),
+			"bytes":      rec.bytes,
+			"principal":  info.principal,
+		}).Info("Request handled")
+	})
+}
+
+// recordRoute is meant to be used as inner middleware of the API handler. It
+// records the path pattern and the operation of the route that matched the
+// request for the access log and the metrics
+func recordRoute(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info := accessInfoFrom(r); info != nil {
+			if route := middleware.MatchedRouteFrom(r); route != nil {
+				info.route = route.PathPattern
+				info.operation = route.Operation.ID
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// recordPrincipal wraps an authorizer to record the subject of the principal
+// that sent the request for the access log. Authorizers are called for every
+// authenticated request, so it is a good place to catch the principal
+func recordPrincipal(authorize func(*http.Request) error) func(*http.Request) error {
+	return func(r *http.Request) error {
+		if info := accessInfoFrom(r); info != nil {
+			if principal, ok := service.PrincipalFromContext(r.Context()); ok {
+				info.principal = principal.Subject
+			}
+		}
+		return authorize(r)
+	}
+}
+
+// newRecoverableHandler adds a basic panic recovery middleware so that clients
+// get a 500 Internal Server Error, as an ApiError, when something goes wrong.
+// Panics are logged along with the stack of the goroutine that panicked
+func newRecoverableHandler(logger logrus.FieldLogger, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				service.ServeError(w, r, errors.New(http.StatusInternalServerError, "recovered from panic"))
+
+				stack := make([]byte, 8*1024)
+				stack = stack[:runtime.Stack(stack, false)]
+				service.RequestLogger(r.Context(), logger).WithFields(logrus.Fields{
+					"panic": fmt.Sprint(err),
+					"stack": string(stack),
+				}).Error("Recovered from panic")
+			}
+		}()
+
+		handler.ServeHTTP(w, r)
+	})
+}
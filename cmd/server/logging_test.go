@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi"
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+// logEntries decodes the JSON entries written to buf
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Log entry is not valid JSON: %q", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestNewLogger(t *testing.T) {
+	if _, err := newLogger("loud", &bytes.Buffer{}); err == nil {
+		t.Error("Expected an error with an unknown level")
+	}
+
+	buf := &bytes.Buffer{}
+	logger, err := newLogger("warn", buf)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	logger.Info("hidden")
+	logger.WithField("key", "value").Warn("shown")
+
+	entries := logEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("Want 1 entry but got %d", len(entries))
+	}
+	if entries[0]["msg"] != "shown" || entries[0]["level"] != "warning" || entries[0]["key"] != "value" {
+		t.Errorf("Unexpected entry %v", entries[0])
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		wantSame bool
+	}{
+		"no header": {},
+		"valid header": {
+			header:   "2c1d1c5e-req.42",
+			wantSame: true,
+		},
+		"invalid header": {
+			header: "bad id\nwith a new line",
+		},
+		"too long header": {
+			header: strings.Repeat("a", 129),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ctxID string
+			handler := newRequestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID, _ = service.RequestIDFromContext(r.Context())
+			}))
+
+			req, _ := http.NewRequest(http.MethodGet, "/v1/payments", nil)
+			if tc.header != "" {
+				req.Header.Set(requestIDHeader, tc.header)
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			respID := resp.Header().Get(requestIDHeader)
+			if respID == "" || respID != ctxID {
+				t.Fatalf("Want the same ID in the response and the context but got %q and %q", respID, ctxID)
+			}
+			if tc.wantSame && respID != tc.header {
+				t.Errorf("Want the ID sent by the client but got %q", respID)
+			}
+			if !tc.wantSame && !strfmt.IsUUID(respID) {
+				t.Errorf("Want a generated UUID but got %q", respID)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	keys, err := newAPIKeyAuthenticator([]apiKey{{
+		Name:          "auditor",
+		SHA256:        hashKey("auditor-key"),
+		Organisations: []strfmt.UUID{testOrg},
+		Scopes:        []string{"payments:read"},
+	}})
+	if err != nil {
+		t.Fatalf("Error creating API key authenticator: %v", err)
+	}
+
+	apiHandler, err := newAPIHandler(restapi.Config{
+		PaymentsAPI:     &service.PaymentsService{Repo: service.NewMemPaymentRepository()},
+		InnerMiddleware: recordRoute,
+		AuthAPIKey:      newAuthFunc(keys, ""),
+		AuthBearer:      newAuthFunc(nil, "Bearer "),
+		Authorizer:      recordPrincipal(service.Authorize),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error creating API handler: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	logger, _ := newLogger("info", buf)
+	handler := newRequestIDHandler(newAccessLogHandler(logger, apiHandler))
+
+	tests := map[string]struct {
+		apiKey        string
+		wantStatus    float64
+		wantPrincipal string
+	}{
+		"authenticated": {
+			apiKey: "auditor-key",
+			// The repo is empty, so authenticated requests find no payments
+			wantStatus:    http.StatusNotFound,
+			wantPrincipal: "auditor",
+		},
+		"unauthenticated": {
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			req, _ := http.NewRequest(http.MethodGet, "/v1/payments/"+testOrg.String(), nil)
+			req.Header.Set(requestIDHeader, "access-log-test")
+			if tc.apiKey != "" {
+				req.Header.Set("X-API-Key", tc.apiKey)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := logEntries(t, buf)
+			if len(entries) != 1 {
+				t.Fatalf("Want 1 entry but got %d", len(entries))
+			}
+
+			entry := entries[0]
+			want := map[string]interface{}{
+				"request_id": "access-log-test",
+				"method":     http.MethodGet,
+				"route":      "/v1/payments/{id}",
+				"status":     tc.wantStatus,
+				"principal":  tc.wantPrincipal,
+			}
+			for field, value := range want {
+				if entry[field] != value {
+					t.Errorf("Want %v in %q but got %v", value, field, entry[field])
+				}
+			}
+			if _, ok := entry["latency_ms"].(float64); !ok {
+				t.Errorf("Want the latency of the request but got %v", entry["latency_ms"])
+			}
+			if bytes, ok := entry["bytes"].(float64); !ok || bytes == 0 {
+				t.Errorf("Want the size of the response but got %v", entry["bytes"])
+			}
+		})
+	}
+}
+
+func TestRecoverableHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _ := newLogger("info", buf)
+	handler := newRequestIDHandler(newRecoverableHandler(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/payments", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusInternalServerError {
+		t.Errorf("Want %d but got %d", http.StatusInternalServerError, resp.Code)
+	}
+	var apiErr models.APIError
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || len(apiErr.Errors) != 1 || apiErr.Errors[0].Code != "internal_error" {
+		t.Errorf("Want an internal error as ApiError but got %+v", apiErr)
+	}
+
+	entries := logEntries(t, buf)
+	if len(entries) != 1 || entries[0]["panic"] != "boom" || entries[0]["request_id"] != resp.Header().Get(requestIDHeader) {
+		t.Errorf("Unexpected entries %v", entries)
+	}
+}
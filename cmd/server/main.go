@@ -1,86 +1,250 @@
 package main
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/namsral/flag"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
 
 	"github.com/volmedo/pAPI/pkg/restapi"
 	"github.com/volmedo/pAPI/pkg/service"
+	"github.com/volmedo/pAPI/pkg/tracing"
 )
 
 func main() {
-	var dbHost, dbUser, dbPass, dbName, migrationsPath string
-	var port, dbPort int
-	var rps int64
-
-	// Use "PAPI" as prefix for env variables to avoid potential clashes
-	fs := flag.NewFlagSetWithEnvPrefix(os.Args[0], "PAPI", flag.ExitOnError)
-
-	fs.IntVar(&port, "port", 8080, "Port where the server is listening for connections.")
-	fs.Int64Var(&rps, "rps", 100, "Rate limit expressed in requests per second (per client)")
-
-	fs.StringVar(&dbHost, "dbhost", "localhost", "Address of the server that hosts the DB")
-	fs.IntVar(&dbPort, "dbport", 5432, "Port where the DB server is listening for connections")
-	fs.StringVar(&dbUser, "dbuser", "postgres", "User to use when accessing the DB")
-	fs.StringVar(&dbPass, "dbpass", "postgres", "Password to use when accessing the DB")
-	fs.StringVar(&dbName, "dbname", "postgres", "Name of the DB to connect to")
-	fs.StringVar(&migrationsPath, "migrations", "./migrations", "Path to the folder that contains the migration files")
-
-	// Ignore errors; fs is set for ExitOnError
-	_ = fs.Parse(os.Args[1:])
-
-	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime|log.LUTC)
-
-	// Setup DB
-	dbConf := &service.DBConfig{
-		Host:           dbHost,
-		Port:           dbPort,
-		User:           dbUser,
-		Pass:           dbPass,
-		Name:           dbName,
-		MigrationsPath: migrationsPath,
-	}
-	db, err := service.NewDB(dbConf)
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[0], os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	conf, _, err := loadConfig(os.Args[0], os.Args[1:], flag.ExitOnError)
 	if err != nil {
-		logger.Panicf("Unable to configure DB connection: %v", err)
+		log.Panicf("Unable to load configuration: %v", err)
+	}
+	if errs := conf.validate(); len(errs) > 0 {
+		for _, err := range errs {
+			log.Printf("Invalid configuration: %v", err)
+		}
+		log.Panicf("Unable to start with an invalid configuration")
 	}
 
-	testRepo, err := service.NewDBPaymentRepository(db, dbName, migrationsPath)
+	logger, err := newLogger(conf.LogLevel, os.Stdout)
 	if err != nil {
-		logger.Panicf("Unable to create DB repo: %v", err)
+		log.Panicf("Unable to configure logging: %v", err)
+	}
+	// Errors raised outside the service, such as unexpected errors found by
+	// the API handler, are logged with the standard logger
+	logrus.SetOutput(logger.Out)
+	logrus.SetFormatter(logger.Formatter)
+	logrus.SetLevel(logger.Level)
+
+	// Setup data backend
+	var repo service.PaymentRepository
+	var idempotency service.IdempotencyStore
+	// Liveness checks must only fail when restarting the server would fix
+	// them, so dependencies only register readiness checks
+	liveness := newHealthChecks(conf.HealthTimeout)
+	readiness := newHealthChecks(conf.HealthTimeout)
+	switch conf.Backend {
+	case "postgres":
+		dbConf := &conf.DB
+		db, err := service.NewDB(dbConf)
+		if err != nil {
+			logger.Panicf("Unable to configure DB connection: %v", err)
+		}
+
+		repo, err = service.NewDBPaymentRepository(db, dbConf)
+		if err != nil {
+			logger.Panicf("Unable to create DB repo: %v", err)
+		}
+		idempotency = service.NewDBIdempotencyStore(db, dbConf, conf.IdempotencyTTL)
+		readiness.Register("db", service.DBPingCheck(db))
+		readiness.Register("db_pool", service.DBPoolCheck(db))
+		if conf.DB.MigrationsPath != "" {
+			schemaCheck, err := service.DBSchemaCheck(db, conf.DB.MigrationsPath)
+			if err != nil {
+				logger.Panicf("Unable to read DB migrations: %v", err)
+			}
+			readiness.Register("db_schema", schemaCheck)
+		}
+
+		if err := prometheus.Register(service.NewDBStatsCollector(db)); err != nil {
+			logger.Panicf("Unable to register DB metrics: %v", err)
+		}
+
+	case "memory":
+		repo = service.NewMemPaymentRepository()
+		idempotency = service.NewMemIdempotencyStore(conf.IdempotencyTTL)
+
+	default:
+		logger.Panicf("Unknown backend %q, it must be either 'postgres' or 'memory'", conf.Backend)
+	}
+
+	repo, err = service.NewInstrumentedPaymentRepository(repo, prometheus.DefaultRegisterer)
+	if err != nil {
+		logger.Panicf("Unable to register repository metrics: %v", err)
+	}
+
+	paymentMetrics, err := service.NewPaymentMetrics(prometheus.DefaultRegisterer, conf.Metrics)
+	if err != nil {
+		logger.Panicf("Unable to register payment metrics: %v", err)
 	}
 
 	ps := &service.PaymentsService{
-		Repo:   testRepo,
-		Logger: logger,
+		Repo:        repo,
+		Idempotency: idempotency,
+		Metrics:     paymentMetrics,
+		Logger:      logger,
+	}
+
+	if conf.BaseURL != "" {
+		// The URL has been validated along with the rest of the configuration
+		ps.BaseURL, _ = url.Parse(conf.BaseURL)
+	}
+
+	// Setup authentication
+	var apiKeyAuth, jwtAuth authenticator
+	if conf.APIKeysPath != "" {
+		a, err := loadAPIKeys(conf.APIKeysPath)
+		if err != nil {
+			logger.Panicf("Unable to load API keys: %v", err)
+		}
+		apiKeyAuth = a
+	}
+	if conf.JWT.HMACKeyPath != "" || conf.JWT.RSAKeyPath != "" {
+		a, err := newJWTAuthenticator(&conf.JWT)
+		if err != nil {
+			logger.Panicf("Unable to configure JWT verification: %v", err)
+		}
+		jwtAuth = a
 	}
 
-	apiHandler, err := restapi.Handler(restapi.Config{
-		PaymentsAPI: ps,
-		Logger:      logger.Printf,
-	})
+	apiConf := restapi.Config{
+		PaymentsAPI:     ps,
+		Logger:          logger.Debugf,
+		InnerMiddleware: func(h http.Handler) http.Handler { return recordRoute(service.RecordBody(h)) },
+		AuthAPIKey:      newAuthFunc(apiKeyAuth, ""),
+		AuthBearer:      newAuthFunc(jwtAuth, "Bearer "),
+		Authorizer:      recordPrincipal(service.Authorize),
+	}
+	var clientCert func(*x509.Certificate) (interface{}, error)
+	if conf.TLS.ClientCAPath != "" {
+		clientCert = clientCertPrincipal
+	}
+
+	apiHandler, err := newAPIHandler(apiConf, clientCert)
 	if err != nil {
 		logger.Panicf("Error creating main API handler: %v", err)
 	}
 
-	apiHandler, prometheusHandler := newMeasuredHandler(apiHandler)
-	apiHandler, err = newRateLimitedHandler(rps, apiHandler)
+	tracer, err := newTracer(conf.TraceOutput, conf.TraceSampleRatio, logger)
+	if err != nil {
+		logger.Panicf("Unable to configure tracing: %v", err)
+	}
+
+	// Every middleware is measured as a span, which includes the time spent
+	// in the middlewares after it
+	apiHandler = tracing.Middleware("api", apiHandler)
+	apiHandler, err = newBodyLimitedHandler(conf.MaxBodyBytes, apiHandler)
+	if err != nil {
+		logger.Panicf("Error creating body size limit middleware: %v", err)
+	}
+	apiHandler = tracing.Middleware("middleware.body_limit", apiHandler)
+	apiHandler = tracing.Middleware("middleware.metrics", newMeasuredHandler(prometheus.DefaultRegisterer, apiHandler))
+	apiHandler, err = newRateLimitedHandler(conf.RPS, prometheus.DefaultRegisterer, apiHandler)
 	if err != nil {
 		logger.Panicf("Error creating rate limiter middleware: %v", err)
 	}
-	apiHandler = newRecoverableHandler(apiHandler)
+	apiHandler = tracing.Middleware("middleware.rate_limit", apiHandler)
+	apiHandler = tracing.Middleware("middleware.recover", newRecoverableHandler(logger, apiHandler))
+	apiHandler = tracing.Middleware("middleware.access_log", newAccessLogHandler(logger, apiHandler))
+	apiHandler = tracing.Middleware("middleware.request_id", newRequestIDHandler(apiHandler))
+	apiHandler = tracing.Handler(tracer, apiHandler)
+
+	// Readiness checks fail as soon as the server starts shutting down
+	drain := &drainer{}
+	readiness.Register("shutdown", drain.Check)
 
 	mux := http.NewServeMux()
-	mux.Handle("/health", newHealthHandler(db))
-	mux.Handle("/metrics", prometheusHandler)
+	mux.Handle("/livez", newHealthHandler(liveness))
+	mux.Handle("/readyz", newHealthHandler(readiness))
+	// Kept for clients of previous versions
+	mux.Handle("/health", newHealthHandler(readiness))
+	mux.Handle("/metrics", promhttp.Handler())
 	mux.Handle("/", apiHandler)
 
-	logger.Printf("Starting server, accepting requests on port %d\n", port)
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), mux); err != nil {
+	server := &http.Server{
+		Addr:           fmt.Sprintf(":%d", conf.Port),
+		Handler:        mux,
+		ReadTimeout:    conf.ReadTimeout,
+		WriteTimeout:   conf.WriteTimeout,
+		IdleTimeout:    conf.IdleTimeout,
+		MaxHeaderBytes: conf.MaxHeaderBytes,
+		// Errors accepting connections or in TLS handshakes aren't tied to a request
+		ErrorLog: log.New(logger.WriterLevel(logrus.WarnLevel), "", 0),
+	}
+
+	serveErr := make(chan error, 1)
+	if conf.TLS.CertPath == "" {
+		logger.Infof("Starting server, accepting requests on port %d", conf.Port)
+		go func() { serveErr <- server.ListenAndServe() }()
+	} else {
+		reloader, err := newTLSReloader(conf.TLS)
+		if err != nil {
+			logger.Panicf("Unable to configure TLS: %v", err)
+		}
+		server.TLSConfig = reloader.TLSConfig()
+
+		// Certificates are reloaded on SIGHUP, so that they can be renewed without restarting the server
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := reloader.reload(); err != nil {
+					logger.Warnf("Unable to reload TLS certificates, keeping the current ones: %v", err)
+					continue
+				}
+				logger.Infof("TLS certificates reloaded")
+			}
+		}()
+
+		logger.Infof("Starting server, accepting TLS requests on port %d", conf.Port)
+		// Certificates are already part of the TLS configuration of the server
+		go func() { serveErr <- server.ListenAndServeTLS("", "") }()
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+	select {
+	case err := <-serveErr:
 		logger.Panicf("Error while serving: %v", err)
+	case sig := <-stop:
+		logger.Infof("Received %v, shutting down", sig)
+	}
+
+	// Keep serving for a while after failing readiness checks, so that load
+	// balancers have time to stop sending new requests
+	drain.Drain()
+	time.Sleep(conf.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownGrace)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Warnf("Some requests were still in flight when the grace period ended: %v", err)
+	}
+
+	if err := repo.Close(); err != nil {
+		logger.Errorf("Error closing the payment repository: %v", err)
 	}
+	logger.Infof("Server stopped")
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// tlsFiles holds the paths of the files used to serve the API over TLS
+type tlsFiles struct {
+	// CertPath is the path of a PEM file with the certificate of the server,
+	// optionally followed by intermediate certificates
+	CertPath string
+	// KeyPath is the path of a PEM file with the private key of the certificate
+	KeyPath string
+	// ClientCAPath, when set, is the path of a PEM file with the certificates
+	// of the CAs client certificates must be issued by. Certificates presented
+	// by clients are verified when it is set, but they are not required, so
+	// that the endpoints other than the API can be reached without them
+	ClientCAPath string
+}
+
+// tlsReloader keeps the TLS configuration of the server, which can be reloaded
+// from the files it was read from without restarting the server
+type tlsReloader struct {
+	files tlsFiles
+
+	mu     sync.RWMutex
+	config *tls.Config
+}
+
+// newTLSReloader creates a reloader and loads the TLS configuration from files
+func newTLSReloader(files tlsFiles) (*tlsReloader, error) {
+	if files.CertPath == "" || files.KeyPath == "" {
+		return nil, fmt.Errorf("both a certificate and a key are needed")
+	}
+
+	r := &tlsReloader{files: files}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// reload reads the files again. The current configuration is kept if any of
+// them can't be loaded
+func (r *tlsReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.files.CertPath, r.files.KeyPath)
+	if err != nil {
+		return fmt.Errorf("error loading certificate: %v", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if r.files.ClientCAPath != "" {
+		pem, err := ioutil.ReadFile(r.files.ClientCAPath)
+		if err != nil {
+			return fmt.Errorf("error reading client CA certificates: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no valid certificates found in %s", r.files.ClientCAPath)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	r.mu.Lock()
+	r.config = config
+	r.mu.Unlock()
+
+	return nil
+}
+
+// current returns the latest configuration loaded
+func (r *tlsReloader) current() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config
+}
+
+// TLSConfig returns a configuration for the server that uses the latest
+// configuration loaded for every new connection
+func (r *tlsReloader) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The configuration returned for each connection replaces this one
+		// entirely, so the protocols offered with ALPN must be set in both.
+		// Otherwise, HTTP/2 is never negotiated
+		NextProtos: []string{"h2", "http/1.1"},
+		// GetConfigForClient takes precedence, but a certificate is required
+		// for http.Server to accept the configuration
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &r.current().Certificates[0], nil
+		},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		// The current configuration is shared by every connection, so it
+		// must not be modified
+		config := r.current().Clone()
+		config.NextProtos = base.NextProtos
+		return config, nil
+	}
+
+	return base
+}
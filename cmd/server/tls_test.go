@@ -0,0 +1,347 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/errors"
+
+	"github.com/volmedo/pAPI/pkg/restapi"
+	"github.com/volmedo/pAPI/pkg/service"
+)
+
+// testCert is a certificate along with its private key
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCert creates a certificate for subject signed by parent, or a self
+// signed CA certificate if parent is nil
+func newTestCert(t *testing.T, subject pkix.Name, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Error creating certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func (c *testCert) keyPEM(t *testing.T) []byte {
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("Error encoding key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCert) tlsCert(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.pem, c.keyPEM(t))
+	if err != nil {
+		t.Fatalf("Error loading key pair: %v", err)
+	}
+	return cert
+}
+
+func TestTLSReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-tls")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+	first := newTestCert(t, pkix.Name{CommonName: "first"}, ca)
+	files := tlsFiles{
+		CertPath:     writeTempFile(t, dir, "server.pem", first.pem),
+		KeyPath:      writeTempFile(t, dir, "server.key", first.keyPEM(t)),
+		ClientCAPath: writeTempFile(t, dir, "ca.pem", ca.pem),
+	}
+
+	r, err := newTLSReloader(files)
+	if err != nil {
+		t.Fatalf("Error loading TLS configuration: %v", err)
+	}
+
+	config, _ := r.TLSConfig().GetConfigForClient(nil)
+	if config.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Errorf("Client certificates should be verified when a client CA is set")
+	}
+
+	second := newTestCert(t, pkix.Name{CommonName: "second"}, ca)
+	writeTempFile(t, dir, "server.pem", second.pem)
+	writeTempFile(t, dir, "server.key", second.keyPEM(t))
+	if err := r.reload(); err != nil {
+		t.Fatalf("Error reloading TLS configuration: %v", err)
+	}
+
+	cert, _ := r.TLSConfig().GetCertificate(nil)
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf.Subject.CommonName != "second" {
+		t.Errorf("Want the reloaded certificate but got %q", leaf.Subject.CommonName)
+	}
+
+	// A broken file keeps the current configuration in place
+	writeTempFile(t, dir, "server.key", []byte("not a key"))
+	if err := r.reload(); err == nil {
+		t.Error("Expected an error reloading a broken key")
+	}
+	cert, _ = r.TLSConfig().GetCertificate(nil)
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf.Subject.CommonName != "second" {
+		t.Errorf("Want the previous certificate but got %q", leaf.Subject.CommonName)
+	}
+}
+
+func TestTLSReloaderALPN(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-tls")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+	cert := newTestCert(t, pkix.Name{CommonName: "server"}, ca)
+	r, err := newTLSReloader(tlsFiles{
+		CertPath: writeTempFile(t, dir, "server.pem", cert.pem),
+		KeyPath:  writeTempFile(t, dir, "server.key", cert.keyPEM(t)),
+	})
+	if err != nil {
+		t.Fatalf("Error loading TLS configuration: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %v", err)
+	}
+	server := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		TLSConfig: r.TLSConfig(),
+	}
+	go server.ServeTLS(ln, "", "")
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	for _, proto := range []string{"h2", "http/1.1"} {
+		conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{RootCAs: roots, NextProtos: []string{proto}})
+		if err != nil {
+			t.Fatalf("Error connecting with %s: %v", proto, err)
+		}
+		if got := conn.ConnectionState().NegotiatedProtocol; got != proto {
+			t.Errorf("Want %s negotiated but got %q", proto, got)
+		}
+		conn.Close()
+	}
+}
+
+func TestClientCertPrincipal(t *testing.T) {
+	tests := map[string]struct {
+		subject pkix.Name
+		wantErr bool
+	}{
+		"valid": {
+			subject: pkix.Name{
+				CommonName:         "billing",
+				Organization:       []string{testOrg.String()},
+				OrganizationalUnit: []string{"payments:read"},
+			},
+		},
+		"missing common name": {
+			subject: pkix.Name{Organization: []string{testOrg.String()}},
+			wantErr: true,
+		},
+		"missing organisations": {
+			subject: pkix.Name{CommonName: "billing"},
+			wantErr: true,
+		},
+		"invalid organisation": {
+			subject: pkix.Name{CommonName: "billing", Organization: []string{"ACME Inc."}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			principal, err := clientCertPrincipal(&x509.Certificate{Subject: tc.subject})
+			if tc.wantErr {
+				apiErr, ok := err.(errors.Error)
+				if !ok || apiErr.Code() != http.StatusUnauthorized {
+					t.Fatalf("Want a 401 error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			p := principal.(*service.Principal)
+			if p.Subject != "billing" || !p.HasScope("payments:read") || len(p.Organisations) != 1 {
+				t.Errorf("Unexpected principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestClientCertHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "papi-tls")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, pkix.Name{CommonName: "ca"}, nil)
+	server := newTestCert(t, pkix.Name{CommonName: "localhost"}, ca)
+	r, err := newTLSReloader(tlsFiles{
+		CertPath:     writeTempFile(t, dir, "server.pem", server.pem),
+		KeyPath:      writeTempFile(t, dir, "server.key", server.keyPEM(t)),
+		ClientCAPath: writeTempFile(t, dir, "ca.pem", ca.pem),
+	})
+	if err != nil {
+		t.Fatalf("Error loading TLS configuration: %v", err)
+	}
+
+	handler, err := newAPIHandler(restapi.Config{
+		PaymentsAPI: &service.PaymentsService{Repo: service.NewMemPaymentRepository()},
+		AuthAPIKey:  newAuthFunc(nil, ""),
+		AuthBearer:  newAuthFunc(nil, "Bearer "),
+		Authorizer:  service.Authorize,
+	}, clientCertPrincipal)
+	if err != nil {
+		t.Fatalf("Error creating API handler: %v", err)
+	}
+
+	// The additional endpoints share the listener with the API
+	mux := http.NewServeMux()
+	mux.Handle("/livez", newHealthHandler(newHealthChecks(time.Second)))
+	mux.Handle("/", handler)
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.TLS = r.TLSConfig()
+	// Rejected handshakes are expected
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	rogueCA := newTestCert(t, pkix.Name{CommonName: "rogue"}, nil)
+
+	tests := map[string]struct {
+		cert     *testCert
+		path     string
+		wantCode int
+		wantErr  bool
+	}{
+		"valid certificate": {
+			cert: newTestCert(t, pkix.Name{
+				CommonName:         "billing",
+				Organization:       []string{testOrg.String()},
+				OrganizationalUnit: []string{"payments:read"},
+			}, ca),
+			// The repo is empty, so authenticated requests find no payments
+			wantCode: http.StatusNotFound,
+		},
+		"missing scope": {
+			cert: newTestCert(t, pkix.Name{
+				CommonName:   "billing",
+				Organization: []string{testOrg.String()},
+			}, ca),
+			wantCode: http.StatusForbidden,
+		},
+		"missing organisations": {
+			cert:     newTestCert(t, pkix.Name{CommonName: "billing"}, ca),
+			wantCode: http.StatusUnauthorized,
+		},
+		"untrusted certificate": {
+			cert:    newTestCert(t, pkix.Name{CommonName: "billing"}, rogueCA),
+			wantErr: true,
+		},
+		"no certificate": {
+			wantCode: http.StatusUnauthorized,
+		},
+		"no certificate for health checks": {
+			path:     "/livez",
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tlsConf := &tls.Config{RootCAs: roots}
+			if tc.cert != nil {
+				// Certificates not issued by the CAs the server asks for would
+				// not be sent otherwise
+				cert := tc.cert.tlsCert(t)
+				tlsConf.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return &cert, nil
+				}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+
+			path := tc.path
+			if path == "" {
+				path = "/v1/payments"
+			}
+			resp, err := client.Get(srv.URL + path)
+			if tc.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("Expected the connection to be rejected but got %d", resp.StatusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.wantCode {
+				t.Errorf("Want %d but got %d", tc.wantCode, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/volmedo/pAPI/pkg/tracing"
+)
+
+// newTracer creates a tracer that writes spans to output as JSON lines, which
+// can be "stdout" or the path of a file that spans are appended to. Spans are
+// still created and propagated, but not exported, if output is empty
+func newTracer(output string, sampleRatio float64, logger logrus.FieldLogger) (*tracing.Tracer, error) {
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("sample ratio must be between 0 and 1 (ratio = %v)", sampleRatio)
+	}
+
+	conf := tracing.Config{
+		SampleRatio: sampleRatio,
+		OnError: func(err error) {
+			logger.WithError(err).Warn("Unable to export span")
+		},
+	}
+
+	var w io.Writer
+	switch output {
+	case "":
+	case "stdout":
+		w = os.Stdout
+	default:
+		// The file is kept open for as long as the server runs. Writes are
+		// not buffered, so there is nothing to flush when the server stops
+		f, err := os.OpenFile(output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+	}
+	if w != nil {
+		conf.Exporter = tracing.NewJSONExporter(w)
+	}
+
+	return tracing.NewTracer(conf), nil
+}
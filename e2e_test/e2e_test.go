@@ -3,10 +3,13 @@ package test
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -17,6 +20,7 @@ import (
 	"github.com/DATA-DOG/godog"
 	"github.com/DATA-DOG/godog/colors"
 	"github.com/DATA-DOG/godog/gherkin"
+	rtclient "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 
 	"github.com/volmedo/pAPI/pkg/client"
@@ -30,6 +34,13 @@ var (
 	port       int
 	apiPath    string
 	healthPath string
+	apiKey     string
+	caCert     string
+	clientCert string
+	clientKey  string
+
+	// transport is used for every request sent to the server
+	transport http.RoundTripper = http.DefaultTransport
 
 	opt = godog.Options{
 		Output: colors.Colored(os.Stdout),
@@ -50,7 +61,14 @@ type Client struct {
 }
 
 func newClient(apiURL, healthURL *url.URL) *Client {
-	conf := client.Config{URL: apiURL}
+	conf := client.Config{
+		URL:       apiURL,
+		Transport: transport,
+	}
+	// Requests are authenticated with the client certificate if there is no API key
+	if apiKey != "" {
+		conf.AuthInfo = rtclient.APIKeyAuth("X-API-Key", "header", apiKey)
+	}
 	payments := client.New(conf)
 	registeredIDs := make(map[strfmt.UUID]struct{})
 	return &Client{
@@ -309,7 +327,7 @@ func (c *Client) theResponseContainsAListOfPaymentsWithIDs(ids *gherkin.DataTabl
 
 // ping checks if the API is ready by sending a request to its health endpoint
 func (c *Client) ping() error {
-	resp, err := http.Get(c.healthURL.String())
+	resp, err := (&http.Client{Transport: transport}).Get(c.healthURL.String())
 	if err != nil {
 		return err
 	}
@@ -327,11 +345,24 @@ func TestMain(m *testing.M) {
 	flag.StringVar(&host, "host", client.DefaultHost, "Address or URL of the server serving the Payments API (such as 'localhost' or 'api.example.com')")
 	flag.IntVar(&port, "port", 8080, "Port where the server is listening for connections")
 	flag.StringVar(&apiPath, "api-path", client.DefaultBasePath, "Base path for API endpoints")
-	flag.StringVar(&healthPath, "health-path", "/health", "Path to the API's health endpoint")
+	flag.StringVar(&healthPath, "health-path", "/readyz", "Path to the API's readiness endpoint")
+	flag.StringVar(&apiKey, "api-key", "", "API key used to authenticate requests")
+	flag.StringVar(&caCert, "ca-cert", "", "Path to a PEM file with the CA certificates used to verify the server certificate (system CAs are used if empty)")
+	flag.StringVar(&clientCert, "client-cert", "", "Path to a PEM file with the client certificate sent to the server")
+	flag.StringVar(&clientKey, "client-key", "", "Path to a PEM file with the private key of the client certificate")
 
 	flag.Parse()
 	opt.Paths = flag.Args()
 
+	if scheme == "https" {
+		t, err := newTLSTransport()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to configure TLS: %v\n", err)
+			os.Exit(1)
+		}
+		transport = t
+	}
+
 	status := godog.RunWithOptions("papi-e2e", func(s *godog.Suite) {
 		FeatureContext(s)
 	}, opt)
@@ -342,6 +373,33 @@ func TestMain(m *testing.M) {
 	os.Exit(status)
 }
 
+// newTLSTransport creates a transport that trusts the CAs in caCert and sends
+// the certificate in clientCert, when they are set
+func newTLSTransport() (http.RoundTripper, error) {
+	tlsConf := &tls.Config{}
+
+	if caCert != "" {
+		pem, err := ioutil.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificates: %v", err)
+		}
+		tlsConf.RootCAs = x509.NewCertPool()
+		if !tlsConf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", caCert)
+		}
+	}
+
+	if clientCert != "" {
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Transport{TLSClientConfig: tlsConf}, nil
+}
+
 func FeatureContext(s *godog.Suite) {
 	apiURL := &url.URL{
 		Scheme: scheme,
@@ -1,6 +1,6 @@
 module github.com/volmedo/pAPI
 
-go 1.12
+go 1.13
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.3.3
@@ -13,13 +13,16 @@ require (
 	github.com/go-openapi/swag v0.19.0
 	github.com/go-openapi/validate v0.19.0
 	github.com/gofrs/uuid v3.2.0+incompatible
+	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/golang-migrate/migrate/v4 v4.3.1
 	github.com/google/go-cmp v0.3.0
 	github.com/lib/pq v1.1.1
 	github.com/mitchellh/copystructure v1.0.0
 	github.com/namsral/flag v1.7.4-pre
+	github.com/pelletier/go-toml v1.2.0
 	github.com/prometheus/client_golang v0.9.3
+	github.com/sirupsen/logrus v1.4.1
 	github.com/slok/go-http-metrics v0.4.0
 	github.com/ulule/limiter/v3 v3.2.0
-	github.com/unrolled/recovery v0.0.0-20170109144926-b19e1efea904
+	gopkg.in/yaml.v2 v2.2.2
 )
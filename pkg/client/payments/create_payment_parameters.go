@@ -63,6 +63,11 @@ for the create payment operation typically these are written to a http.Request
 */
 type CreatePaymentParams struct {
 
+	/*IdempotencyKey
+	  Unique key chosen by the client to make the request idempotent. Retries of the request with the same key get the response to the original request
+
+	*/
+	IdempotencyKey *string
 	/*PaymentCreationRequest*/
 	PaymentCreationRequest *models.PaymentCreationRequest
 
@@ -104,6 +109,17 @@ func (o *CreatePaymentParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithIdempotencyKey adds the idempotencyKey to the create payment params
+func (o *CreatePaymentParams) WithIdempotencyKey(idempotencyKey *string) *CreatePaymentParams {
+	o.SetIdempotencyKey(idempotencyKey)
+	return o
+}
+
+// SetIdempotencyKey adds the idempotencyKey to the create payment params
+func (o *CreatePaymentParams) SetIdempotencyKey(idempotencyKey *string) {
+	o.IdempotencyKey = idempotencyKey
+}
+
 // WithPaymentCreationRequest adds the paymentCreationRequest to the create payment params
 func (o *CreatePaymentParams) WithPaymentCreationRequest(paymentCreationRequest *models.PaymentCreationRequest) *CreatePaymentParams {
 	o.SetPaymentCreationRequest(paymentCreationRequest)
@@ -123,6 +139,15 @@ func (o *CreatePaymentParams) WriteToRequest(r runtime.ClientRequest, reg strfmt
 	}
 	var res []error
 
+	if o.IdempotencyKey != nil {
+
+		// header param Idempotency-Key
+		if err := r.SetHeaderParam("Idempotency-Key", *o.IdempotencyKey); err != nil {
+			return err
+		}
+
+	}
+
 	if o.PaymentCreationRequest != nil {
 		if err := r.SetBodyParam(o.PaymentCreationRequest); err != nil {
 			return err
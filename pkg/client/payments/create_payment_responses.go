@@ -32,6 +32,20 @@ func (o *CreatePaymentReader) ReadResponse(response runtime.ClientResponse, cons
 		}
 		return result, nil
 
+	case 401:
+		result := NewCreatePaymentUnauthorized()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	case 403:
+		result := NewCreatePaymentForbidden()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
 	case 409:
 		result := NewCreatePaymentConflict()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -39,6 +53,13 @@ func (o *CreatePaymentReader) ReadResponse(response runtime.ClientResponse, cons
 		}
 		return nil, result
 
+	case 422:
+		result := NewCreatePaymentUnprocessableEntity()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
 	case 429:
 		result := NewCreatePaymentTooManyRequests()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -68,6 +89,10 @@ func NewCreatePaymentCreated() *CreatePaymentCreated {
 Payment created successfully
 */
 type CreatePaymentCreated struct {
+	/*URL of the new payment
+	 */
+	Location string
+
 	Payload *models.PaymentCreationResponse
 }
 
@@ -77,6 +102,9 @@ func (o *CreatePaymentCreated) Error() string {
 
 func (o *CreatePaymentCreated) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
 
+	// response header Location
+	o.Location = response.GetHeader("Location")
+
 	o.Payload = new(models.PaymentCreationResponse)
 
 	// response payload
@@ -87,6 +115,56 @@ func (o *CreatePaymentCreated) readResponse(response runtime.ClientResponse, con
 	return nil
 }
 
+// NewCreatePaymentUnauthorized creates a CreatePaymentUnauthorized with default headers values
+func NewCreatePaymentUnauthorized() *CreatePaymentUnauthorized {
+	return &CreatePaymentUnauthorized{}
+}
+
+/*CreatePaymentUnauthorized handles this case with default header values.
+
+Unauthorized
+*/
+type CreatePaymentUnauthorized struct {
+}
+
+func (o *CreatePaymentUnauthorized) Error() string {
+	return fmt.Sprintf("[POST /payments][%d] createPaymentUnauthorized ", 401)
+}
+
+func (o *CreatePaymentUnauthorized) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	return nil
+}
+
+// NewCreatePaymentForbidden creates a CreatePaymentForbidden with default headers values
+func NewCreatePaymentForbidden() *CreatePaymentForbidden {
+	return &CreatePaymentForbidden{}
+}
+
+/*CreatePaymentForbidden handles this case with default header values.
+
+The client lacks the `payments:write` scope, or the payment belongs to an organisation the caller has no access to
+*/
+type CreatePaymentForbidden struct {
+	Payload *models.APIError
+}
+
+func (o *CreatePaymentForbidden) Error() string {
+	return fmt.Sprintf("[POST /payments][%d] createPaymentForbidden  %+v", 403, o.Payload)
+}
+
+func (o *CreatePaymentForbidden) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewCreatePaymentConflict creates a CreatePaymentConflict with default headers values
 func NewCreatePaymentConflict() *CreatePaymentConflict {
 	return &CreatePaymentConflict{}
@@ -94,7 +172,7 @@ func NewCreatePaymentConflict() *CreatePaymentConflict {
 
 /*CreatePaymentConflict handles this case with default header values.
 
-A payment with the given ID already exists
+A payment with the given ID already exists, or a request with the same idempotency key is still being processed
 */
 type CreatePaymentConflict struct {
 	Payload *models.APIError
@@ -116,6 +194,35 @@ func (o *CreatePaymentConflict) readResponse(response runtime.ClientResponse, co
 	return nil
 }
 
+// NewCreatePaymentUnprocessableEntity creates a CreatePaymentUnprocessableEntity with default headers values
+func NewCreatePaymentUnprocessableEntity() *CreatePaymentUnprocessableEntity {
+	return &CreatePaymentUnprocessableEntity{}
+}
+
+/*CreatePaymentUnprocessableEntity handles this case with default header values.
+
+The idempotency key has already been used with a different request
+*/
+type CreatePaymentUnprocessableEntity struct {
+	Payload *models.APIError
+}
+
+func (o *CreatePaymentUnprocessableEntity) Error() string {
+	return fmt.Sprintf("[POST /payments][%d] createPaymentUnprocessableEntity  %+v", 422, o.Payload)
+}
+
+func (o *CreatePaymentUnprocessableEntity) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewCreatePaymentTooManyRequests creates a CreatePaymentTooManyRequests with default headers values
 func NewCreatePaymentTooManyRequests() *CreatePaymentTooManyRequests {
 	return &CreatePaymentTooManyRequests{}
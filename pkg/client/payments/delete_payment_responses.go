@@ -32,6 +32,20 @@ func (o *DeletePaymentReader) ReadResponse(response runtime.ClientResponse, cons
 		}
 		return result, nil
 
+	case 401:
+		result := NewDeletePaymentUnauthorized()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	case 403:
+		result := NewDeletePaymentForbidden()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
 	case 404:
 		result := NewDeletePaymentNotFound()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -79,6 +93,56 @@ func (o *DeletePaymentNoContent) readResponse(response runtime.ClientResponse, c
 	return nil
 }
 
+// NewDeletePaymentUnauthorized creates a DeletePaymentUnauthorized with default headers values
+func NewDeletePaymentUnauthorized() *DeletePaymentUnauthorized {
+	return &DeletePaymentUnauthorized{}
+}
+
+/*DeletePaymentUnauthorized handles this case with default header values.
+
+Unauthorized
+*/
+type DeletePaymentUnauthorized struct {
+}
+
+func (o *DeletePaymentUnauthorized) Error() string {
+	return fmt.Sprintf("[DELETE /payments/{id}][%d] deletePaymentUnauthorized ", 401)
+}
+
+func (o *DeletePaymentUnauthorized) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	return nil
+}
+
+// NewDeletePaymentForbidden creates a DeletePaymentForbidden with default headers values
+func NewDeletePaymentForbidden() *DeletePaymentForbidden {
+	return &DeletePaymentForbidden{}
+}
+
+/*DeletePaymentForbidden handles this case with default header values.
+
+The client lacks the `payments:delete` scope
+*/
+type DeletePaymentForbidden struct {
+	Payload *models.APIError
+}
+
+func (o *DeletePaymentForbidden) Error() string {
+	return fmt.Sprintf("[DELETE /payments/{id}][%d] deletePaymentForbidden  %+v", 403, o.Payload)
+}
+
+func (o *DeletePaymentForbidden) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewDeletePaymentNotFound creates a DeletePaymentNotFound with default headers values
 func NewDeletePaymentNotFound() *DeletePaymentNotFound {
 	return &DeletePaymentNotFound{}
@@ -32,6 +32,20 @@ func (o *GetPaymentReader) ReadResponse(response runtime.ClientResponse, consume
 		}
 		return result, nil
 
+	case 401:
+		result := NewGetPaymentUnauthorized()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	case 403:
+		result := NewGetPaymentForbidden()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
 	case 404:
 		result := NewGetPaymentNotFound()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -68,6 +82,10 @@ func NewGetPaymentOK() *GetPaymentOK {
 Payment details
 */
 type GetPaymentOK struct {
+	/*Current version of the payment, suitable for use in If-Match headers
+	 */
+	ETag string
+
 	Payload *models.PaymentDetailsResponse
 }
 
@@ -77,6 +95,9 @@ func (o *GetPaymentOK) Error() string {
 
 func (o *GetPaymentOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
 
+	// response header ETag
+	o.ETag = response.GetHeader("ETag")
+
 	o.Payload = new(models.PaymentDetailsResponse)
 
 	// response payload
@@ -87,6 +108,56 @@ func (o *GetPaymentOK) readResponse(response runtime.ClientResponse, consumer ru
 	return nil
 }
 
+// NewGetPaymentUnauthorized creates a GetPaymentUnauthorized with default headers values
+func NewGetPaymentUnauthorized() *GetPaymentUnauthorized {
+	return &GetPaymentUnauthorized{}
+}
+
+/*GetPaymentUnauthorized handles this case with default header values.
+
+Unauthorized
+*/
+type GetPaymentUnauthorized struct {
+}
+
+func (o *GetPaymentUnauthorized) Error() string {
+	return fmt.Sprintf("[GET /payments/{id}][%d] getPaymentUnauthorized ", 401)
+}
+
+func (o *GetPaymentUnauthorized) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	return nil
+}
+
+// NewGetPaymentForbidden creates a GetPaymentForbidden with default headers values
+func NewGetPaymentForbidden() *GetPaymentForbidden {
+	return &GetPaymentForbidden{}
+}
+
+/*GetPaymentForbidden handles this case with default header values.
+
+The client lacks the `payments:read` scope
+*/
+type GetPaymentForbidden struct {
+	Payload *models.APIError
+}
+
+func (o *GetPaymentForbidden) Error() string {
+	return fmt.Sprintf("[GET /payments/{id}][%d] getPaymentForbidden  %+v", 403, o.Payload)
+}
+
+func (o *GetPaymentForbidden) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewGetPaymentNotFound creates a GetPaymentNotFound with default headers values
 func NewGetPaymentNotFound() *GetPaymentNotFound {
 	return &GetPaymentNotFound{}
@@ -82,6 +82,51 @@ for the list payments operation typically these are written to a http.Request
 */
 type ListPaymentsParams struct {
 
+	/*FilterAmountGte
+	  Only list payments with an amount greater than or equal to this one
+
+	*/
+	FilterAmountGte *string
+	/*FilterAmountLte
+	  Only list payments with an amount less than or equal to this one
+
+	*/
+	FilterAmountLte *string
+	/*FilterCurrency
+	  Only list payments in this currency, as an ISO 4217 code
+
+	*/
+	FilterCurrency *string
+	/*FilterOrganisationID
+	  Only list payments created by the organisation with this ID
+
+	*/
+	FilterOrganisationID *string
+	/*FilterPaymentScheme
+	  Only list payments processed through this payment scheme
+
+	*/
+	FilterPaymentScheme *string
+	/*FilterProcessingDateGte
+	  Only list payments processed on or after this date (YYYY-MM-DD)
+
+	*/
+	FilterProcessingDateGte *string
+	/*FilterProcessingDateLte
+	  Only list payments processed on or before this date (YYYY-MM-DD)
+
+	*/
+	FilterProcessingDateLte *string
+	/*PageAfter
+	  Select the page that comes right after this cursor
+
+	*/
+	PageAfter *string
+	/*PageBefore
+	  Select the page that comes right before this cursor
+
+	*/
+	PageBefore *string
 	/*PageNumber
 	  Which page to select
 
@@ -92,6 +137,11 @@ type ListPaymentsParams struct {
 
 	*/
 	PageSize *int64
+	/*Sort
+	  Comma separated list of fields to sort payments by. Fields are sorted in ascending order unless prefixed with -
+
+	*/
+	Sort *string
 
 	timeout    time.Duration
 	Context    context.Context
@@ -131,6 +181,105 @@ func (o *ListPaymentsParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithFilterAmountGte adds the filterAmountGte to the list payments params
+func (o *ListPaymentsParams) WithFilterAmountGte(filterAmountGte *string) *ListPaymentsParams {
+	o.SetFilterAmountGte(filterAmountGte)
+	return o
+}
+
+// SetFilterAmountGte adds the filterAmountGte to the list payments params
+func (o *ListPaymentsParams) SetFilterAmountGte(filterAmountGte *string) {
+	o.FilterAmountGte = filterAmountGte
+}
+
+// WithFilterAmountLte adds the filterAmountLte to the list payments params
+func (o *ListPaymentsParams) WithFilterAmountLte(filterAmountLte *string) *ListPaymentsParams {
+	o.SetFilterAmountLte(filterAmountLte)
+	return o
+}
+
+// SetFilterAmountLte adds the filterAmountLte to the list payments params
+func (o *ListPaymentsParams) SetFilterAmountLte(filterAmountLte *string) {
+	o.FilterAmountLte = filterAmountLte
+}
+
+// WithFilterCurrency adds the filterCurrency to the list payments params
+func (o *ListPaymentsParams) WithFilterCurrency(filterCurrency *string) *ListPaymentsParams {
+	o.SetFilterCurrency(filterCurrency)
+	return o
+}
+
+// SetFilterCurrency adds the filterCurrency to the list payments params
+func (o *ListPaymentsParams) SetFilterCurrency(filterCurrency *string) {
+	o.FilterCurrency = filterCurrency
+}
+
+// WithFilterOrganisationID adds the filterOrganisationID to the list payments params
+func (o *ListPaymentsParams) WithFilterOrganisationID(filterOrganisationID *string) *ListPaymentsParams {
+	o.SetFilterOrganisationID(filterOrganisationID)
+	return o
+}
+
+// SetFilterOrganisationID adds the filterOrganisationID to the list payments params
+func (o *ListPaymentsParams) SetFilterOrganisationID(filterOrganisationID *string) {
+	o.FilterOrganisationID = filterOrganisationID
+}
+
+// WithFilterPaymentScheme adds the filterPaymentScheme to the list payments params
+func (o *ListPaymentsParams) WithFilterPaymentScheme(filterPaymentScheme *string) *ListPaymentsParams {
+	o.SetFilterPaymentScheme(filterPaymentScheme)
+	return o
+}
+
+// SetFilterPaymentScheme adds the filterPaymentScheme to the list payments params
+func (o *ListPaymentsParams) SetFilterPaymentScheme(filterPaymentScheme *string) {
+	o.FilterPaymentScheme = filterPaymentScheme
+}
+
+// WithFilterProcessingDateGte adds the filterProcessingDateGte to the list payments params
+func (o *ListPaymentsParams) WithFilterProcessingDateGte(filterProcessingDateGte *string) *ListPaymentsParams {
+	o.SetFilterProcessingDateGte(filterProcessingDateGte)
+	return o
+}
+
+// SetFilterProcessingDateGte adds the filterProcessingDateGte to the list payments params
+func (o *ListPaymentsParams) SetFilterProcessingDateGte(filterProcessingDateGte *string) {
+	o.FilterProcessingDateGte = filterProcessingDateGte
+}
+
+// WithFilterProcessingDateLte adds the filterProcessingDateLte to the list payments params
+func (o *ListPaymentsParams) WithFilterProcessingDateLte(filterProcessingDateLte *string) *ListPaymentsParams {
+	o.SetFilterProcessingDateLte(filterProcessingDateLte)
+	return o
+}
+
+// SetFilterProcessingDateLte adds the filterProcessingDateLte to the list payments params
+func (o *ListPaymentsParams) SetFilterProcessingDateLte(filterProcessingDateLte *string) {
+	o.FilterProcessingDateLte = filterProcessingDateLte
+}
+
+// WithPageAfter adds the pageAfter to the list payments params
+func (o *ListPaymentsParams) WithPageAfter(pageAfter *string) *ListPaymentsParams {
+	o.SetPageAfter(pageAfter)
+	return o
+}
+
+// SetPageAfter adds the pageAfter to the list payments params
+func (o *ListPaymentsParams) SetPageAfter(pageAfter *string) {
+	o.PageAfter = pageAfter
+}
+
+// WithPageBefore adds the pageBefore to the list payments params
+func (o *ListPaymentsParams) WithPageBefore(pageBefore *string) *ListPaymentsParams {
+	o.SetPageBefore(pageBefore)
+	return o
+}
+
+// SetPageBefore adds the pageBefore to the list payments params
+func (o *ListPaymentsParams) SetPageBefore(pageBefore *string) {
+	o.PageBefore = pageBefore
+}
+
 // WithPageNumber adds the pageNumber to the list payments params
 func (o *ListPaymentsParams) WithPageNumber(pageNumber *int64) *ListPaymentsParams {
 	o.SetPageNumber(pageNumber)
@@ -153,6 +302,17 @@ func (o *ListPaymentsParams) SetPageSize(pageSize *int64) {
 	o.PageSize = pageSize
 }
 
+// WithSort adds the sort to the list payments params
+func (o *ListPaymentsParams) WithSort(sort *string) *ListPaymentsParams {
+	o.SetSort(sort)
+	return o
+}
+
+// SetSort adds the sort to the list payments params
+func (o *ListPaymentsParams) SetSort(sort *string) {
+	o.Sort = sort
+}
+
 // WriteToRequest writes these params to a swagger request
 func (o *ListPaymentsParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
 
@@ -161,6 +321,150 @@ func (o *ListPaymentsParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.
 	}
 	var res []error
 
+	if o.FilterAmountGte != nil {
+
+		// query param filter[amount][gte]
+		var qrFilterAmountGte string
+		if o.FilterAmountGte != nil {
+			qrFilterAmountGte = *o.FilterAmountGte
+		}
+		qFilterAmountGte := qrFilterAmountGte
+		if qFilterAmountGte != "" {
+			if err := r.SetQueryParam("filter[amount][gte]", qFilterAmountGte); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.FilterAmountLte != nil {
+
+		// query param filter[amount][lte]
+		var qrFilterAmountLte string
+		if o.FilterAmountLte != nil {
+			qrFilterAmountLte = *o.FilterAmountLte
+		}
+		qFilterAmountLte := qrFilterAmountLte
+		if qFilterAmountLte != "" {
+			if err := r.SetQueryParam("filter[amount][lte]", qFilterAmountLte); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.FilterCurrency != nil {
+
+		// query param filter[currency]
+		var qrFilterCurrency string
+		if o.FilterCurrency != nil {
+			qrFilterCurrency = *o.FilterCurrency
+		}
+		qFilterCurrency := qrFilterCurrency
+		if qFilterCurrency != "" {
+			if err := r.SetQueryParam("filter[currency]", qFilterCurrency); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.FilterOrganisationID != nil {
+
+		// query param filter[organisation_id]
+		var qrFilterOrganisationID string
+		if o.FilterOrganisationID != nil {
+			qrFilterOrganisationID = *o.FilterOrganisationID
+		}
+		qFilterOrganisationID := qrFilterOrganisationID
+		if qFilterOrganisationID != "" {
+			if err := r.SetQueryParam("filter[organisation_id]", qFilterOrganisationID); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.FilterPaymentScheme != nil {
+
+		// query param filter[payment_scheme]
+		var qrFilterPaymentScheme string
+		if o.FilterPaymentScheme != nil {
+			qrFilterPaymentScheme = *o.FilterPaymentScheme
+		}
+		qFilterPaymentScheme := qrFilterPaymentScheme
+		if qFilterPaymentScheme != "" {
+			if err := r.SetQueryParam("filter[payment_scheme]", qFilterPaymentScheme); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.FilterProcessingDateGte != nil {
+
+		// query param filter[processing_date][gte]
+		var qrFilterProcessingDateGte string
+		if o.FilterProcessingDateGte != nil {
+			qrFilterProcessingDateGte = *o.FilterProcessingDateGte
+		}
+		qFilterProcessingDateGte := qrFilterProcessingDateGte
+		if qFilterProcessingDateGte != "" {
+			if err := r.SetQueryParam("filter[processing_date][gte]", qFilterProcessingDateGte); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.FilterProcessingDateLte != nil {
+
+		// query param filter[processing_date][lte]
+		var qrFilterProcessingDateLte string
+		if o.FilterProcessingDateLte != nil {
+			qrFilterProcessingDateLte = *o.FilterProcessingDateLte
+		}
+		qFilterProcessingDateLte := qrFilterProcessingDateLte
+		if qFilterProcessingDateLte != "" {
+			if err := r.SetQueryParam("filter[processing_date][lte]", qFilterProcessingDateLte); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.PageAfter != nil {
+
+		// query param page[after]
+		var qrPageAfter string
+		if o.PageAfter != nil {
+			qrPageAfter = *o.PageAfter
+		}
+		qPageAfter := qrPageAfter
+		if qPageAfter != "" {
+			if err := r.SetQueryParam("page[after]", qPageAfter); err != nil {
+				return err
+			}
+		}
+
+	}
+
+	if o.PageBefore != nil {
+
+		// query param page[before]
+		var qrPageBefore string
+		if o.PageBefore != nil {
+			qrPageBefore = *o.PageBefore
+		}
+		qPageBefore := qrPageBefore
+		if qPageBefore != "" {
+			if err := r.SetQueryParam("page[before]", qPageBefore); err != nil {
+				return err
+			}
+		}
+
+	}
+
 	if o.PageNumber != nil {
 
 		// query param page[number]
@@ -193,6 +497,22 @@ func (o *ListPaymentsParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.
 
 	}
 
+	if o.Sort != nil {
+
+		// query param sort
+		var qrSort string
+		if o.Sort != nil {
+			qrSort = *o.Sort
+		}
+		qSort := qrSort
+		if qSort != "" {
+			if err := r.SetQueryParam("sort", qSort); err != nil {
+				return err
+			}
+		}
+
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
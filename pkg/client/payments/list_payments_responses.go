@@ -32,6 +32,27 @@ func (o *ListPaymentsReader) ReadResponse(response runtime.ClientResponse, consu
 		}
 		return result, nil
 
+	case 400:
+		result := NewListPaymentsBadRequest()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	case 401:
+		result := NewListPaymentsUnauthorized()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	case 403:
+		result := NewListPaymentsForbidden()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
 	case 404:
 		result := NewListPaymentsNotFound()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -87,6 +108,85 @@ func (o *ListPaymentsOK) readResponse(response runtime.ClientResponse, consumer
 	return nil
 }
 
+// NewListPaymentsBadRequest creates a ListPaymentsBadRequest with default headers values
+func NewListPaymentsBadRequest() *ListPaymentsBadRequest {
+	return &ListPaymentsBadRequest{}
+}
+
+/*ListPaymentsBadRequest handles this case with default header values.
+
+Invalid filter, sort or cursor
+*/
+type ListPaymentsBadRequest struct {
+	Payload *models.APIError
+}
+
+func (o *ListPaymentsBadRequest) Error() string {
+	return fmt.Sprintf("[GET /payments][%d] listPaymentsBadRequest  %+v", 400, o.Payload)
+}
+
+func (o *ListPaymentsBadRequest) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewListPaymentsUnauthorized creates a ListPaymentsUnauthorized with default headers values
+func NewListPaymentsUnauthorized() *ListPaymentsUnauthorized {
+	return &ListPaymentsUnauthorized{}
+}
+
+/*ListPaymentsUnauthorized handles this case with default header values.
+
+Unauthorized
+*/
+type ListPaymentsUnauthorized struct {
+}
+
+func (o *ListPaymentsUnauthorized) Error() string {
+	return fmt.Sprintf("[GET /payments][%d] listPaymentsUnauthorized ", 401)
+}
+
+func (o *ListPaymentsUnauthorized) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	return nil
+}
+
+// NewListPaymentsForbidden creates a ListPaymentsForbidden with default headers values
+func NewListPaymentsForbidden() *ListPaymentsForbidden {
+	return &ListPaymentsForbidden{}
+}
+
+/*ListPaymentsForbidden handles this case with default header values.
+
+The client lacks the `payments:read` scope
+*/
+type ListPaymentsForbidden struct {
+	Payload *models.APIError
+}
+
+func (o *ListPaymentsForbidden) Error() string {
+	return fmt.Sprintf("[GET /payments][%d] listPaymentsForbidden  %+v", 403, o.Payload)
+}
+
+func (o *ListPaymentsForbidden) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewListPaymentsNotFound creates a ListPaymentsNotFound with default headers values
 func NewListPaymentsNotFound() *ListPaymentsNotFound {
 	return &ListPaymentsNotFound{}
@@ -17,14 +17,22 @@ import (
 
 // API is the interface of the payments client
 type API interface {
+	// BulkCreatePayments creates several payments at once
+	BulkCreatePayments(ctx context.Context, params *BulkCreatePaymentsParams) (*BulkCreatePaymentsCreated, *BulkCreatePaymentsMultiStatus, error)
 	// CreatePayment creates payment
 	CreatePayment(ctx context.Context, params *CreatePaymentParams) (*CreatePaymentCreated, error)
 	// DeletePayment deletes a payment resource
 	DeletePayment(ctx context.Context, params *DeletePaymentParams) (*DeletePaymentNoContent, error)
 	// GetPayment fetches payment
 	GetPayment(ctx context.Context, params *GetPaymentParams) (*GetPaymentOK, error)
+	// GetPaymentVersion fetches payment version
+	GetPaymentVersion(ctx context.Context, params *GetPaymentVersionParams) (*GetPaymentVersionOK, error)
+	// ListPaymentVersions lists payment versions
+	ListPaymentVersions(ctx context.Context, params *ListPaymentVersionsParams) (*ListPaymentVersionsOK, error)
 	// ListPayments lists payments
 	ListPayments(ctx context.Context, params *ListPaymentsParams) (*ListPaymentsOK, error)
+	// PatchPayment patches payment details
+	PatchPayment(ctx context.Context, params *PatchPaymentParams) (*PatchPaymentOK, error)
 	// UpdatePayment updates payment details
 	UpdatePayment(ctx context.Context, params *UpdatePaymentParams) (*UpdatePaymentOK, error)
 }
@@ -47,6 +55,37 @@ type Client struct {
 	authInfo  runtime.ClientAuthInfoWriter
 }
 
+/*
+BulkCreatePayments creates several payments at once
+*/
+func (a *Client) BulkCreatePayments(ctx context.Context, params *BulkCreatePaymentsParams) (*BulkCreatePaymentsCreated, *BulkCreatePaymentsMultiStatus, error) {
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "bulkCreatePayments",
+		Method:             "POST",
+		PathPattern:        "/payments/bulk",
+		ProducesMediaTypes: []string{"application/vnd.api+json"},
+		ConsumesMediaTypes: []string{"application/vnd.api+json"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &BulkCreatePaymentsReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
+		Context:            ctx,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	switch value := result.(type) {
+	case *BulkCreatePaymentsCreated:
+		return value, nil, nil
+	case *BulkCreatePaymentsMultiStatus:
+		return nil, value, nil
+	}
+	return nil, nil, nil
+
+}
+
 /*
 CreatePayment creates payment
 */
@@ -61,6 +100,7 @@ func (a *Client) CreatePayment(ctx context.Context, params *CreatePaymentParams)
 		Schemes:            []string{"http"},
 		Params:             params,
 		Reader:             &CreatePaymentReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
 		Context:            ctx,
 		Client:             params.HTTPClient,
 	})
@@ -85,6 +125,7 @@ func (a *Client) DeletePayment(ctx context.Context, params *DeletePaymentParams)
 		Schemes:            []string{"http"},
 		Params:             params,
 		Reader:             &DeletePaymentReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
 		Context:            ctx,
 		Client:             params.HTTPClient,
 	})
@@ -109,6 +150,7 @@ func (a *Client) GetPayment(ctx context.Context, params *GetPaymentParams) (*Get
 		Schemes:            []string{"http"},
 		Params:             params,
 		Reader:             &GetPaymentReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
 		Context:            ctx,
 		Client:             params.HTTPClient,
 	})
@@ -119,6 +161,56 @@ func (a *Client) GetPayment(ctx context.Context, params *GetPaymentParams) (*Get
 
 }
 
+/*
+GetPaymentVersion fetches payment version
+*/
+func (a *Client) GetPaymentVersion(ctx context.Context, params *GetPaymentVersionParams) (*GetPaymentVersionOK, error) {
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "getPaymentVersion",
+		Method:             "GET",
+		PathPattern:        "/payments/{id}/versions/{version}",
+		ProducesMediaTypes: []string{"application/vnd.api+json"},
+		ConsumesMediaTypes: []string{"application/vnd.api+json"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &GetPaymentVersionReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
+		Context:            ctx,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*GetPaymentVersionOK), nil
+
+}
+
+/*
+ListPaymentVersions lists payment versions
+*/
+func (a *Client) ListPaymentVersions(ctx context.Context, params *ListPaymentVersionsParams) (*ListPaymentVersionsOK, error) {
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "listPaymentVersions",
+		Method:             "GET",
+		PathPattern:        "/payments/{id}/versions",
+		ProducesMediaTypes: []string{"application/vnd.api+json"},
+		ConsumesMediaTypes: []string{"application/vnd.api+json"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &ListPaymentVersionsReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
+		Context:            ctx,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*ListPaymentVersionsOK), nil
+
+}
+
 /*
 ListPayments lists payments
 */
@@ -133,6 +225,7 @@ func (a *Client) ListPayments(ctx context.Context, params *ListPaymentsParams) (
 		Schemes:            []string{"http"},
 		Params:             params,
 		Reader:             &ListPaymentsReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
 		Context:            ctx,
 		Client:             params.HTTPClient,
 	})
@@ -143,6 +236,31 @@ func (a *Client) ListPayments(ctx context.Context, params *ListPaymentsParams) (
 
 }
 
+/*
+PatchPayment patches payment details
+*/
+func (a *Client) PatchPayment(ctx context.Context, params *PatchPaymentParams) (*PatchPaymentOK, error) {
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "patchPayment",
+		Method:             "PATCH",
+		PathPattern:        "/payments/{id}",
+		ProducesMediaTypes: []string{"application/vnd.api+json"},
+		ConsumesMediaTypes: []string{"application/merge-patch+json", "application/vnd.api+json"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &PatchPaymentReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
+		Context:            ctx,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PatchPaymentOK), nil
+
+}
+
 /*
 UpdatePayment updates payment details
 */
@@ -157,6 +275,7 @@ func (a *Client) UpdatePayment(ctx context.Context, params *UpdatePaymentParams)
 		Schemes:            []string{"http"},
 		Params:             params,
 		Reader:             &UpdatePaymentReader{formats: a.formats},
+		AuthInfo:           a.authInfo,
 		Context:            ctx,
 		Client:             params.HTTPClient,
 	})
@@ -63,6 +63,11 @@ for the update payment operation typically these are written to a http.Request
 */
 type UpdatePaymentParams struct {
 
+	/*IfMatch
+	  ETag of the version of the payment the update is based on. The update will only be applied if it matches the current version of the payment
+
+	*/
+	IfMatch *string
 	/*PaymentUpdateRequest
 	  New payment details
 
@@ -112,6 +117,17 @@ func (o *UpdatePaymentParams) SetHTTPClient(client *http.Client) {
 	o.HTTPClient = client
 }
 
+// WithIfMatch adds the ifMatch to the update payment params
+func (o *UpdatePaymentParams) WithIfMatch(ifMatch *string) *UpdatePaymentParams {
+	o.SetIfMatch(ifMatch)
+	return o
+}
+
+// SetIfMatch adds the ifMatch to the update payment params
+func (o *UpdatePaymentParams) SetIfMatch(ifMatch *string) {
+	o.IfMatch = ifMatch
+}
+
 // WithPaymentUpdateRequest adds the paymentUpdateRequest to the update payment params
 func (o *UpdatePaymentParams) WithPaymentUpdateRequest(paymentUpdateRequest *models.PaymentUpdateRequest) *UpdatePaymentParams {
 	o.SetPaymentUpdateRequest(paymentUpdateRequest)
@@ -142,6 +158,15 @@ func (o *UpdatePaymentParams) WriteToRequest(r runtime.ClientRequest, reg strfmt
 	}
 	var res []error
 
+	if o.IfMatch != nil {
+
+		// header param If-Match
+		if err := r.SetHeaderParam("If-Match", *o.IfMatch); err != nil {
+			return err
+		}
+
+	}
+
 	if o.PaymentUpdateRequest != nil {
 		if err := r.SetBodyParam(o.PaymentUpdateRequest); err != nil {
 			return err
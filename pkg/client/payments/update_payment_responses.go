@@ -32,6 +32,20 @@ func (o *UpdatePaymentReader) ReadResponse(response runtime.ClientResponse, cons
 		}
 		return result, nil
 
+	case 401:
+		result := NewUpdatePaymentUnauthorized()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	case 403:
+		result := NewUpdatePaymentForbidden()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
 	case 404:
 		result := NewUpdatePaymentNotFound()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -39,6 +53,20 @@ func (o *UpdatePaymentReader) ReadResponse(response runtime.ClientResponse, cons
 		}
 		return nil, result
 
+	case 409:
+		result := NewUpdatePaymentConflict()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
+	case 412:
+		result := NewUpdatePaymentPreconditionFailed()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
+
 	case 429:
 		result := NewUpdatePaymentTooManyRequests()
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -68,6 +96,10 @@ func NewUpdatePaymentOK() *UpdatePaymentOK {
 Payment details
 */
 type UpdatePaymentOK struct {
+	/*New version of the payment, suitable for use in If-Match headers
+	 */
+	ETag string
+
 	Payload *models.PaymentUpdateResponse
 }
 
@@ -77,6 +109,9 @@ func (o *UpdatePaymentOK) Error() string {
 
 func (o *UpdatePaymentOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
 
+	// response header ETag
+	o.ETag = response.GetHeader("ETag")
+
 	o.Payload = new(models.PaymentUpdateResponse)
 
 	// response payload
@@ -87,6 +122,56 @@ func (o *UpdatePaymentOK) readResponse(response runtime.ClientResponse, consumer
 	return nil
 }
 
+// NewUpdatePaymentUnauthorized creates a UpdatePaymentUnauthorized with default headers values
+func NewUpdatePaymentUnauthorized() *UpdatePaymentUnauthorized {
+	return &UpdatePaymentUnauthorized{}
+}
+
+/*UpdatePaymentUnauthorized handles this case with default header values.
+
+Unauthorized
+*/
+type UpdatePaymentUnauthorized struct {
+}
+
+func (o *UpdatePaymentUnauthorized) Error() string {
+	return fmt.Sprintf("[PUT /payments/{id}][%d] updatePaymentUnauthorized ", 401)
+}
+
+func (o *UpdatePaymentUnauthorized) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	return nil
+}
+
+// NewUpdatePaymentForbidden creates a UpdatePaymentForbidden with default headers values
+func NewUpdatePaymentForbidden() *UpdatePaymentForbidden {
+	return &UpdatePaymentForbidden{}
+}
+
+/*UpdatePaymentForbidden handles this case with default header values.
+
+The client lacks the `payments:write` scope, or the update would move the payment to an organisation the caller has no access to
+*/
+type UpdatePaymentForbidden struct {
+	Payload *models.APIError
+}
+
+func (o *UpdatePaymentForbidden) Error() string {
+	return fmt.Sprintf("[PUT /payments/{id}][%d] updatePaymentForbidden  %+v", 403, o.Payload)
+}
+
+func (o *UpdatePaymentForbidden) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewUpdatePaymentNotFound creates a UpdatePaymentNotFound with default headers values
 func NewUpdatePaymentNotFound() *UpdatePaymentNotFound {
 	return &UpdatePaymentNotFound{}
@@ -116,6 +201,64 @@ func (o *UpdatePaymentNotFound) readResponse(response runtime.ClientResponse, co
 	return nil
 }
 
+// NewUpdatePaymentConflict creates a UpdatePaymentConflict with default headers values
+func NewUpdatePaymentConflict() *UpdatePaymentConflict {
+	return &UpdatePaymentConflict{}
+}
+
+/*UpdatePaymentConflict handles this case with default header values.
+
+The version in the payment details doesn't match the current version of the payment, it has been modified in the meantime
+*/
+type UpdatePaymentConflict struct {
+	Payload *models.APIError
+}
+
+func (o *UpdatePaymentConflict) Error() string {
+	return fmt.Sprintf("[PUT /payments/{id}][%d] updatePaymentConflict  %+v", 409, o.Payload)
+}
+
+func (o *UpdatePaymentConflict) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// NewUpdatePaymentPreconditionFailed creates a UpdatePaymentPreconditionFailed with default headers values
+func NewUpdatePaymentPreconditionFailed() *UpdatePaymentPreconditionFailed {
+	return &UpdatePaymentPreconditionFailed{}
+}
+
+/*UpdatePaymentPreconditionFailed handles this case with default header values.
+
+The ETag in the If-Match header doesn't match the current version of the payment, it has been modified in the meantime
+*/
+type UpdatePaymentPreconditionFailed struct {
+	Payload *models.APIError
+}
+
+func (o *UpdatePaymentPreconditionFailed) Error() string {
+	return fmt.Sprintf("[PUT /payments/{id}][%d] updatePaymentPreconditionFailed  %+v", 412, o.Payload)
+}
+
+func (o *UpdatePaymentPreconditionFailed) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.APIError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewUpdatePaymentTooManyRequests creates a UpdatePaymentTooManyRequests with default headers values
 func NewUpdatePaymentTooManyRequests() *UpdatePaymentTooManyRequests {
 	return &UpdatePaymentTooManyRequests{}
@@ -6,30 +6,33 @@ package models
 // Editing this file might prove futile when you re-run the swagger generate command
 
 import (
+	"strconv"
+
 	strfmt "github.com/go-openapi/strfmt"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/swag"
-	"github.com/go-openapi/validate"
 )
 
-// APIError Api error
+// APIError Error document, following the [JSON:API format for errors](https://jsonapi.org/format/#errors). `error_code` and `error_message` are kept for backwards compatibility
 // swagger:model ApiError
 type APIError struct {
 
-	// error code
-	// Format: uuid
-	ErrorCode strfmt.UUID `json:"error_code,omitempty"`
+	// ID of the request the error happened in, which is also returned in the `X-Request-ID` header of the response. Please include it when reporting an error, as it identifies the server logs of the request
+	ErrorCode string `json:"error_code,omitempty"`
 
-	// error message
+	// Deprecated, the `detail` of the first error
 	ErrorMessage string `json:"error_message,omitempty"`
+
+	// Problems found while processing the request
+	Errors []*APIErrorObject `json:"errors"`
 }
 
 // Validate validates this Api error
 func (m *APIError) Validate(formats strfmt.Registry) error {
 	var res []error
 
-	if err := m.validateErrorCode(formats); err != nil {
+	if err := m.validateErrors(formats); err != nil {
 		res = append(res, err)
 	}
 
@@ -39,14 +42,26 @@ func (m *APIError) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
-func (m *APIError) validateErrorCode(formats strfmt.Registry) error {
+func (m *APIError) validateErrors(formats strfmt.Registry) error {
 
-	if swag.IsZero(m.ErrorCode) { // not required
+	if swag.IsZero(m.Errors) { // not required
 		return nil
 	}
 
-	if err := validate.FormatOf("error_code", "body", "uuid", m.ErrorCode.String(), formats); err != nil {
-		return err
+	for i := 0; i < len(m.Errors); i++ {
+		if swag.IsZero(m.Errors[i]) { // not required
+			continue
+		}
+
+		if m.Errors[i] != nil {
+			if err := m.Errors[i].Validate(formats); err != nil {
+				if ve, ok := err.(*errors.Validation); ok {
+					return ve.ValidateName("errors" + "." + strconv.Itoa(i))
+				}
+				return err
+			}
+		}
+
 	}
 
 	return nil
@@ -23,10 +23,9 @@ type Payment struct {
 	// Required: true
 	Attributes *PaymentAttributes `json:"attributes"`
 
-	// Unique resource ID
-	// Required: true
+	// Unique resource ID. It is generated by the server when a payment is created without it
 	// Format: uuid
-	ID *strfmt.UUID `json:"id"`
+	ID *strfmt.UUID `json:"id,omitempty"`
 
 	// Unique ID of the organisation this resource is created by
 	// Required: true
@@ -92,8 +91,8 @@ func (m *Payment) validateAttributes(formats strfmt.Registry) error {
 
 func (m *Payment) validateID(formats strfmt.Registry) error {
 
-	if err := validate.Required("id", "body", m.ID); err != nil {
-		return err
+	if swag.IsZero(m.ID) { // not required
+		return nil
 	}
 
 	if err := validate.FormatOf("id", "body", "uuid", m.ID.String(), formats); err != nil {
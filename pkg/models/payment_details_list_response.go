@@ -23,6 +23,9 @@ type PaymentDetailsListResponse struct {
 
 	// links
 	Links *Links `json:"links,omitempty"`
+
+	// meta
+	Meta *ListMeta `json:"meta,omitempty"`
 }
 
 // Validate validates this payment details list response
@@ -37,6 +40,10 @@ func (m *PaymentDetailsListResponse) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateMeta(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -86,6 +93,24 @@ func (m *PaymentDetailsListResponse) validateLinks(formats strfmt.Registry) erro
 	return nil
 }
 
+func (m *PaymentDetailsListResponse) validateMeta(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Meta) { // not required
+		return nil
+	}
+
+	if m.Meta != nil {
+		if err := m.Meta.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("meta")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *PaymentDetailsListResponse) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -25,10 +25,14 @@ const AuthKey contextKey = "Auth"
 
 // PaymentsAPI
 type PaymentsAPI interface {
+	BulkCreatePayments(ctx context.Context, params payments.BulkCreatePaymentsParams) middleware.Responder
 	CreatePayment(ctx context.Context, params payments.CreatePaymentParams) middleware.Responder
 	DeletePayment(ctx context.Context, params payments.DeletePaymentParams) middleware.Responder
 	GetPayment(ctx context.Context, params payments.GetPaymentParams) middleware.Responder
+	GetPaymentVersion(ctx context.Context, params payments.GetPaymentVersionParams) middleware.Responder
+	ListPaymentVersions(ctx context.Context, params payments.ListPaymentVersionsParams) middleware.Responder
 	ListPayments(ctx context.Context, params payments.ListPaymentsParams) middleware.Responder
+	PatchPayment(ctx context.Context, params payments.PatchPaymentParams) middleware.Responder
 	UpdatePayment(ctx context.Context, params payments.UpdatePaymentParams) middleware.Responder
 }
 
@@ -43,6 +47,12 @@ type Config struct {
 	// Authorizer is used to authorize a request after the Auth function was called using the "Auth*" functions
 	// and the principal was stored in the context in the "AuthKey" context value.
 	Authorizer func(*http.Request) error
+
+	// AuthAPIKey Applies when the "X-API-Key" header is set
+	AuthAPIKey func(token string) (interface{}, error)
+
+	// AuthBearer Applies when the "Authorization" header is set
+	AuthBearer func(token string) (interface{}, error)
 }
 
 // Handler returns an http.Handler given the handler configuration
@@ -66,24 +76,64 @@ func HandlerAPI(c Config) (http.Handler, *operations.PaymentsAPI, error) {
 
 	api.JSONConsumer = runtime.JSONConsumer()
 	api.JSONProducer = runtime.JSONProducer()
-	api.PaymentsCreatePaymentHandler = payments.CreatePaymentHandlerFunc(func(params payments.CreatePaymentParams) middleware.Responder {
+	api.APIKeyAuth = func(token string) (interface{}, error) {
+		if c.AuthAPIKey == nil {
+			return token, nil
+		}
+		return c.AuthAPIKey(token)
+	}
+
+	api.BearerAuth = func(token string) (interface{}, error) {
+		if c.AuthBearer == nil {
+			return token, nil
+		}
+		return c.AuthBearer(token)
+	}
+
+	api.APIAuthorizer = authorizer(c.Authorizer)
+	api.PaymentsBulkCreatePaymentsHandler = payments.BulkCreatePaymentsHandlerFunc(func(params payments.BulkCreatePaymentsParams, principal interface{}) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
+		return c.PaymentsAPI.BulkCreatePayments(ctx, params)
+	})
+	api.PaymentsCreatePaymentHandler = payments.CreatePaymentHandlerFunc(func(params payments.CreatePaymentParams, principal interface{}) middleware.Responder {
 		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
 		return c.PaymentsAPI.CreatePayment(ctx, params)
 	})
-	api.PaymentsDeletePaymentHandler = payments.DeletePaymentHandlerFunc(func(params payments.DeletePaymentParams) middleware.Responder {
+	api.PaymentsDeletePaymentHandler = payments.DeletePaymentHandlerFunc(func(params payments.DeletePaymentParams, principal interface{}) middleware.Responder {
 		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
 		return c.PaymentsAPI.DeletePayment(ctx, params)
 	})
-	api.PaymentsGetPaymentHandler = payments.GetPaymentHandlerFunc(func(params payments.GetPaymentParams) middleware.Responder {
+	api.PaymentsGetPaymentHandler = payments.GetPaymentHandlerFunc(func(params payments.GetPaymentParams, principal interface{}) middleware.Responder {
 		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
 		return c.PaymentsAPI.GetPayment(ctx, params)
 	})
-	api.PaymentsListPaymentsHandler = payments.ListPaymentsHandlerFunc(func(params payments.ListPaymentsParams) middleware.Responder {
+	api.PaymentsGetPaymentVersionHandler = payments.GetPaymentVersionHandlerFunc(func(params payments.GetPaymentVersionParams, principal interface{}) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
+		return c.PaymentsAPI.GetPaymentVersion(ctx, params)
+	})
+	api.PaymentsListPaymentVersionsHandler = payments.ListPaymentVersionsHandlerFunc(func(params payments.ListPaymentVersionsParams, principal interface{}) middleware.Responder {
 		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
+		return c.PaymentsAPI.ListPaymentVersions(ctx, params)
+	})
+	api.PaymentsListPaymentsHandler = payments.ListPaymentsHandlerFunc(func(params payments.ListPaymentsParams, principal interface{}) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
 		return c.PaymentsAPI.ListPayments(ctx, params)
 	})
-	api.PaymentsUpdatePaymentHandler = payments.UpdatePaymentHandlerFunc(func(params payments.UpdatePaymentParams) middleware.Responder {
+	api.PaymentsPatchPaymentHandler = payments.PatchPaymentHandlerFunc(func(params payments.PatchPaymentParams, principal interface{}) middleware.Responder {
+		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
+		return c.PaymentsAPI.PatchPayment(ctx, params)
+	})
+	api.PaymentsUpdatePaymentHandler = payments.UpdatePaymentHandlerFunc(func(params payments.UpdatePaymentParams, principal interface{}) middleware.Responder {
 		ctx := params.HTTPRequest.Context()
+		ctx = storeAuth(ctx, principal)
 		return c.PaymentsAPI.UpdatePayment(ctx, params)
 	})
 	api.ServerShutdown = func() {}
@@ -38,12 +38,62 @@ func init() {
   "paths": {
     "/payments": {
       "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
         "summary": "List payments",
         "operationId": "listPayments",
         "parameters": [
+          {
+            "type": "string",
+            "description": "Only list payments created by the organisation with this ID",
+            "name": "filter[organisation_id]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments in this currency, as an ISO 4217 code",
+            "name": "filter[currency]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments processed through this payment scheme",
+            "name": "filter[payment_scheme]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments processed on or after this date (YYYY-MM-DD)",
+            "name": "filter[processing_date][gte]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments processed on or before this date (YYYY-MM-DD)",
+            "name": "filter[processing_date][lte]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments with an amount greater than or equal to this one",
+            "name": "filter[amount][gte]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments with an amount less than or equal to this one",
+            "name": "filter[amount][lte]",
+            "in": "query"
+          },
           {
             "type": "integer",
             "default": 0,
@@ -59,6 +109,24 @@ func init() {
             "description": "Number of items per page",
             "name": "page[size]",
             "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Select the page that comes right after this cursor",
+            "name": "page[after]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Select the page that comes right before this cursor",
+            "name": "page[before]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Comma separated list of fields to sort payments by. Fields are sorted in ascending order unless prefixed with -",
+            "name": "sort",
+            "in": "query"
           }
         ],
         "responses": {
@@ -68,6 +136,21 @@ func init() {
               "$ref": "#/definitions/PaymentDetailsListResponse"
             }
           },
+          "400": {
+            "description": "Invalid filter, sort or cursor",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
           "404": {
             "description": "The query returned no payments",
             "schema": {
@@ -83,9 +166,20 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
       },
       "post": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
@@ -98,6 +192,13 @@ func init() {
             "schema": {
               "$ref": "#/definitions/PaymentCreationRequest"
             }
+          },
+          {
+            "maxLength": 255,
+            "type": "string",
+            "description": "Unique key chosen by the client to make the request idempotent. Retries of the request with the same key get the response to the original request",
+            "name": "Idempotency-Key",
+            "in": "header"
           }
         ],
         "responses": {
@@ -105,10 +206,31 @@ func init() {
             "description": "Payment created successfully",
             "schema": {
               "$ref": "#/definitions/PaymentCreationResponse"
+            },
+            "headers": {
+              "Location": {
+                "type": "string",
+                "description": "URL of the new payment"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope, or the payment belongs to an organisation the caller has no access to",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
             }
           },
           "409": {
-            "description": "A payment with the given ID already exists",
+            "description": "A payment with the given ID already exists, or a request with the same idempotency key is still being processed",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "422": {
+            "description": "The idempotency key has already been used with a different request",
             "schema": {
               "$ref": "#/definitions/ApiError"
             }
@@ -122,11 +244,103 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
+      }
+    },
+    "/payments/bulk": {
+      "post": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "Create several payments at once",
+        "operationId": "bulkCreatePayments",
+        "parameters": [
+          {
+            "name": "Payment bulk creation request",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationRequest"
+            }
+          },
+          {
+            "type": "boolean",
+            "default": true,
+            "description": "Whether payments are only created if all of them can be. When false, valid payments are created and the rest are reported as failed",
+            "name": "atomic",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "Every payment was created successfully",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "207": {
+            "description": "Some payments could not be created. The rest were, as the request was not atomic",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "409": {
+            "description": "No payment was created because some of them conflict with existing payments or with each other",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "422": {
+            "description": "No payment was created because some of them are not valid",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
       }
     },
     "/payments/{id}": {
       "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
@@ -147,6 +361,21 @@ func init() {
             "description": "Payment details",
             "schema": {
               "$ref": "#/definitions/PaymentDetailsResponse"
+            },
+            "headers": {
+              "ETag": {
+                "type": "string",
+                "description": "Current version of the payment, suitable for use in If-Match headers"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
             }
           },
           "404": {
@@ -164,9 +393,20 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
       },
       "put": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
@@ -188,6 +428,12 @@ func init() {
             "schema": {
               "$ref": "#/definitions/PaymentUpdateRequest"
             }
+          },
+          {
+            "type": "string",
+            "description": "ETag of the version of the payment the update is based on. The update will only be applied if it matches the current version of the payment",
+            "name": "If-Match",
+            "in": "header"
           }
         ],
         "responses": {
@@ -195,6 +441,21 @@ func init() {
             "description": "Payment details",
             "schema": {
               "$ref": "#/definitions/PaymentUpdateResponse"
+            },
+            "headers": {
+              "ETag": {
+                "type": "string",
+                "description": "New version of the payment, suitable for use in If-Match headers"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope, or the update would move the payment to an organisation the caller has no access to",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
             }
           },
           "404": {
@@ -203,6 +464,18 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           },
+          "409": {
+            "description": "The version in the payment details doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "412": {
+            "description": "The ETag in the If-Match header doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
           "429": {
             "description": "Too Many Requests"
           },
@@ -212,9 +485,20 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
       },
       "delete": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
@@ -234,6 +518,15 @@ func init() {
           "204": {
             "description": "Payment deleted OK. No body content will be returned"
           },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:delete` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
           "404": {
             "description": "Payment Not Found",
             "schema": {
@@ -249,86 +542,379 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
-      }
-    }
-  },
-  "definitions": {
-    "AccountNumber": {
-      "description": "Account number",
-      "type": "string",
-      "example": "71268996"
-    },
-    "Amount": {
-      "description": "Amount of money. Requires 1 to 2 decimal places.",
-      "type": "string",
-      "pattern": "^[0-9.]{0,20}$",
-      "example": "10.00"
-    },
-    "ApiError": {
-      "type": "object",
-      "properties": {
-        "error_code": {
-          "type": "string",
-          "format": "uuid"
-        },
-        "error_message": {
-          "type": "string"
-        }
-      }
-    },
-    "BankId": {
-      "description": "Financial institution identification",
-      "type": "string",
-      "example": "333333"
-    },
-    "BankIdCode": {
-      "description": "The type of identification provided at ` + "`" + `bank_id` + "`" + ` attribute. Must be ISO code as listed in the [External Code Sets spreadsheet](https://www.iso20022.org/external_code_list.page)",
-      "type": "string",
-      "enum": [
-        "SWBIC",
-        "GBDSC",
-        "BE",
-        "FR",
-        "DEBLZ",
-        "GRBIC",
-        "ITNCC",
-        "PLKNR",
-        "PTNCC",
-        "ESNCC",
-        "CHBCC"
-      ],
-      "example": "GBDSC"
-    },
-    "ChargesInformation": {
-      "type": "object",
-      "properties": {
-        "bearer_code": {
-          "description": "Specifies which party/parties will bear the charges associated with the processing of the payment transaction.",
-          "type": "string",
-          "enum": [
-            "DEBT",
-            "CRED",
-            "SHAR",
-            "SLEV"
-          ],
-          "example": "SLEV"
-        },
-        "receiver_charges_amount": {
-          "description": "Transaction charges due to the receiver of the transaction.",
-          "$ref": "#/definitions/Amount"
-        },
-        "receiver_charges_currency": {
-          "$ref": "#/definitions/Currency"
         },
-        "sender_charges": {
-          "type": "array",
-          "items": {
-            "description": "List of transaction charges due to the sender of the transaction",
-            "type": "object",
-            "properties": {
-              "amount": {
-                "description": "Amount of each transaction charge due to the sender of the transaction.",
+        "x-required-scopes": [
+          "payments:delete"
+        ]
+      },
+      "patch": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "consumes": [
+          "application/merge-patch+json",
+          "application/vnd.api+json"
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "Patch payment details",
+        "operationId": "patchPayment",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "uuid",
+            "description": "ID of payment to patch",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "Changes to the payment details, as a JSON Merge Patch (RFC 7386) of a payment details document. Members set to null are removed and members left out keep their current values",
+            "name": "Payment patch",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "object"
+            }
+          },
+          {
+            "type": "string",
+            "description": "ETag of the version of the payment the patch is based on. The patch will only be applied if it matches the current version of the payment",
+            "name": "If-Match",
+            "in": "header"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Payment details",
+            "schema": {
+              "$ref": "#/definitions/PaymentUpdateResponse"
+            },
+            "headers": {
+              "ETag": {
+                "type": "string",
+                "description": "New version of the payment, suitable for use in If-Match headers"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope, or the patch would move the payment to an organisation the caller has no access to",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "404": {
+            "description": "Payment Not Found",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "409": {
+            "description": "The version in the patch doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "412": {
+            "description": "The ETag in the If-Match header doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "422": {
+            "description": "The patched payment details are not valid",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
+      }
+    },
+    "/payments/{id}/versions": {
+      "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "List payment versions",
+        "operationId": "listPaymentVersions",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "uuid",
+            "description": "ID of payment whose versions to list",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Every version of the payment, oldest first",
+            "schema": {
+              "$ref": "#/definitions/PaymentVersionListResponse"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "404": {
+            "description": "Payment Not Found",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
+      }
+    },
+    "/payments/{id}/versions/{version}": {
+      "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "Fetch payment version",
+        "operationId": "getPaymentVersion",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "uuid",
+            "description": "ID of payment to fetch",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "type": "integer",
+            "description": "Version number to fetch",
+            "name": "version",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Payment version details",
+            "schema": {
+              "$ref": "#/definitions/PaymentVersionResponse"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "404": {
+            "description": "Payment or version Not Found",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "AccountNumber": {
+      "description": "Account number",
+      "type": "string",
+      "example": "71268996"
+    },
+    "Amount": {
+      "description": "Amount of money. Requires 1 to 2 decimal places.",
+      "type": "string",
+      "pattern": "^[0-9.]{0,20}$",
+      "example": "10.00"
+    },
+    "ApiError": {
+      "description": "Error document, following the [JSON:API format for errors](https://jsonapi.org/format/#errors). ` + "`" + `error_code` + "`" + ` and ` + "`" + `error_message` + "`" + ` are kept for backwards compatibility",
+      "type": "object",
+      "properties": {
+        "error_code": {
+          "description": "ID of the request the error happened in, which is also returned in the ` + "`" + `X-Request-ID` + "`" + ` header of the response. Please include it when reporting an error, as it identifies the server logs of the request",
+          "type": "string"
+        },
+        "error_message": {
+          "description": "Deprecated, the ` + "`" + `detail` + "`" + ` of the first error",
+          "type": "string"
+        },
+        "errors": {
+          "description": "Problems found while processing the request",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ApiErrorObject"
+          }
+        }
+      }
+    },
+    "ApiErrorObject": {
+      "description": "A problem found while processing a request",
+      "type": "object",
+      "properties": {
+        "code": {
+          "description": "Application specific code of the problem, such as ` + "`" + `required` + "`" + `, ` + "`" + `pattern` + "`" + ` or ` + "`" + `not_found` + "`" + `, that can be used to handle it programmatically",
+          "type": "string"
+        },
+        "detail": {
+          "description": "Explanation specific to this occurrence of the problem",
+          "type": "string"
+        },
+        "id": {
+          "description": "ID of the request the problem was found in, the same as ` + "`" + `error_code` + "`" + `",
+          "type": "string"
+        },
+        "source": {
+          "$ref": "#/definitions/ApiErrorSource"
+        },
+        "status": {
+          "description": "HTTP status code applicable to the problem, as a string",
+          "type": "string"
+        },
+        "title": {
+          "description": "Short summary of the problem, which is the same for every problem with the same code",
+          "type": "string"
+        }
+      }
+    },
+    "ApiErrorSource": {
+      "description": "Part of the request that caused a problem",
+      "type": "object",
+      "properties": {
+        "header": {
+          "description": "Name of the request header that caused the problem",
+          "type": "string"
+        },
+        "parameter": {
+          "description": "Name of the query or path parameter that caused the problem",
+          "type": "string"
+        },
+        "pointer": {
+          "description": "[JSON Pointer](https://tools.ietf.org/html/rfc6901) to the field of the request body that caused the problem, such as ` + "`" + `/data/attributes/amount` + "`" + `",
+          "type": "string"
+        }
+      }
+    },
+    "BankId": {
+      "description": "Financial institution identification",
+      "type": "string",
+      "example": "333333"
+    },
+    "BankIdCode": {
+      "description": "The type of identification provided at ` + "`" + `bank_id` + "`" + ` attribute. Must be ISO code as listed in the [External Code Sets spreadsheet](https://www.iso20022.org/external_code_list.page)",
+      "type": "string",
+      "enum": [
+        "SWBIC",
+        "GBDSC",
+        "BE",
+        "FR",
+        "DEBLZ",
+        "GRBIC",
+        "ITNCC",
+        "PLKNR",
+        "PTNCC",
+        "ESNCC",
+        "CHBCC"
+      ],
+      "example": "GBDSC"
+    },
+    "ChargesInformation": {
+      "type": "object",
+      "properties": {
+        "bearer_code": {
+          "description": "Specifies which party/parties will bear the charges associated with the processing of the payment transaction.",
+          "type": "string",
+          "enum": [
+            "DEBT",
+            "CRED",
+            "SHAR",
+            "SLEV"
+          ],
+          "example": "SLEV"
+        },
+        "receiver_charges_amount": {
+          "description": "Transaction charges due to the receiver of the transaction.",
+          "$ref": "#/definitions/Amount"
+        },
+        "receiver_charges_currency": {
+          "$ref": "#/definitions/Currency"
+        },
+        "sender_charges": {
+          "type": "array",
+          "items": {
+            "description": "List of transaction charges due to the sender of the transaction",
+            "type": "object",
+            "properties": {
+              "amount": {
+                "description": "Amount of each transaction charge due to the sender of the transaction.",
                 "$ref": "#/definitions/Amount"
               },
               "currency": {
@@ -375,10 +961,22 @@ func init() {
         }
       }
     },
+    "ListMeta": {
+      "type": "object",
+      "required": [
+        "total"
+      ],
+      "properties": {
+        "total": {
+          "description": "Total number of resources in the list, across all of its pages",
+          "type": "integer",
+          "example": 42
+        }
+      }
+    },
     "Payment": {
       "type": "object",
       "required": [
-        "id",
         "organisation_id",
         "attributes"
       ],
@@ -511,9 +1109,10 @@ func init() {
           }
         },
         "id": {
-          "description": "Unique resource ID",
+          "description": "Unique resource ID. It is generated by the server when a payment is created without it",
           "type": "string",
           "format": "uuid",
+          "x-nullable": true,
           "example": "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
         },
         "organisation_id": {
@@ -535,6 +1134,89 @@ func init() {
         }
       }
     },
+    "PaymentBulkCreationMeta": {
+      "type": "object",
+      "required": [
+        "created",
+        "failed"
+      ],
+      "properties": {
+        "created": {
+          "description": "Number of payments created",
+          "type": "integer",
+          "example": 998
+        },
+        "failed": {
+          "description": "Number of payments that could not be created",
+          "type": "integer",
+          "example": 2
+        }
+      }
+    },
+    "PaymentBulkCreationRequest": {
+      "type": "object",
+      "required": [
+        "data"
+      ],
+      "properties": {
+        "data": {
+          "description": "Payments to create. Each payment is validated on its own, so that invalid payments can be reported individually",
+          "type": "array",
+          "maxItems": 5000,
+          "minItems": 1,
+          "items": {
+            "type": "object"
+          }
+        }
+      }
+    },
+    "PaymentBulkCreationResponse": {
+      "type": "object",
+      "required": [
+        "data",
+        "meta"
+      ],
+      "properties": {
+        "data": {
+          "description": "Result for each payment in the request, in the same order",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PaymentBulkCreationResult"
+          }
+        },
+        "meta": {
+          "$ref": "#/definitions/PaymentBulkCreationMeta"
+        }
+      }
+    },
+    "PaymentBulkCreationResult": {
+      "type": "object",
+      "required": [
+        "index",
+        "status"
+      ],
+      "properties": {
+        "data": {
+          "$ref": "#/definitions/Payment"
+        },
+        "error": {
+          "$ref": "#/definitions/ApiError"
+        },
+        "index": {
+          "description": "Position of the payment in the request",
+          "type": "integer",
+          "example": 0
+        },
+        "links": {
+          "$ref": "#/definitions/Links"
+        },
+        "status": {
+          "description": "Status code for the payment, 201 if it was created. 409 and 422 mean that the payment conflicts with an existing one or is not valid, and 424 that it was not created because other payments in the request failed",
+          "type": "integer",
+          "example": 201
+        }
+      }
+    },
     "PaymentCreationRequest": {
       "type": "object",
       "required": [
@@ -571,6 +1253,9 @@ func init() {
         },
         "links": {
           "$ref": "#/definitions/Links"
+        },
+        "meta": {
+          "$ref": "#/definitions/ListMeta"
         }
       }
     },
@@ -652,6 +1337,84 @@ func init() {
           "$ref": "#/definitions/Links"
         }
       }
+    },
+    "PaymentVersion": {
+      "type": "object",
+      "required": [
+        "data",
+        "operation",
+        "recorded_at",
+        "version"
+      ],
+      "properties": {
+        "data": {
+          "description": "Details of the payment at this version",
+          "$ref": "#/definitions/Payment"
+        },
+        "operation": {
+          "description": "Operation that produced this version",
+          "type": "string",
+          "enum": [
+            "create",
+            "update",
+            "delete"
+          ],
+          "example": "update"
+        },
+        "recorded_at": {
+          "description": "Time at which this version was recorded",
+          "type": "string",
+          "format": "date-time",
+          "example": "2019-03-01T12:00:00.000Z"
+        },
+        "version": {
+          "description": "Version number",
+          "type": "integer",
+          "example": 0
+        }
+      }
+    },
+    "PaymentVersionListResponse": {
+      "type": "object",
+      "properties": {
+        "data": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PaymentVersion"
+          }
+        },
+        "links": {
+          "$ref": "#/definitions/Links"
+        }
+      }
+    },
+    "PaymentVersionResponse": {
+      "type": "object",
+      "required": [
+        "data"
+      ],
+      "properties": {
+        "data": {
+          "$ref": "#/definitions/PaymentVersion"
+        },
+        "links": {
+          "$ref": "#/definitions/Links"
+        }
+      }
+    }
+  },
+  "securityDefinitions": {
+    "apiKey": {
+      "description": "API key sent in the ` + "`" + `X-API-Key` + "`" + ` header. The scopes required by each operation, listed in its ` + "`" + `x-required-scopes` + "`" + ` extension, must be granted to the key",
+      "type": "apiKey",
+      "name": "X-API-Key",
+      "in": "header"
+    },
+    "bearer": {
+      "description": "JWT signed with HS256 or RS256, sent in the ` + "`" + `Authorization` + "`" + ` header as ` + "`" + `Bearer \u003ctoken\u003e` + "`" + `. The scopes required by each operation, listed in its ` + "`" + `x-required-scopes` + "`" + ` extension, must be listed in the ` + "`" + `scope` + "`" + ` claim of the token",
+      "type": "apiKey",
+      "name": "Authorization",
+      "in": "header"
     }
   }
 }`))
@@ -676,12 +1439,62 @@ func init() {
   "paths": {
     "/payments": {
       "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
         "summary": "List payments",
         "operationId": "listPayments",
         "parameters": [
+          {
+            "type": "string",
+            "description": "Only list payments created by the organisation with this ID",
+            "name": "filter[organisation_id]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments in this currency, as an ISO 4217 code",
+            "name": "filter[currency]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments processed through this payment scheme",
+            "name": "filter[payment_scheme]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments processed on or after this date (YYYY-MM-DD)",
+            "name": "filter[processing_date][gte]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments processed on or before this date (YYYY-MM-DD)",
+            "name": "filter[processing_date][lte]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments with an amount greater than or equal to this one",
+            "name": "filter[amount][gte]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Only list payments with an amount less than or equal to this one",
+            "name": "filter[amount][lte]",
+            "in": "query"
+          },
           {
             "minimum": 0,
             "type": "integer",
@@ -698,6 +1511,24 @@ func init() {
             "description": "Number of items per page",
             "name": "page[size]",
             "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Select the page that comes right after this cursor",
+            "name": "page[after]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Select the page that comes right before this cursor",
+            "name": "page[before]",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Comma separated list of fields to sort payments by. Fields are sorted in ascending order unless prefixed with -",
+            "name": "sort",
+            "in": "query"
           }
         ],
         "responses": {
@@ -707,6 +1538,21 @@ func init() {
               "$ref": "#/definitions/PaymentDetailsListResponse"
             }
           },
+          "400": {
+            "description": "Invalid filter, sort or cursor",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
           "404": {
             "description": "The query returned no payments",
             "schema": {
@@ -722,9 +1568,20 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
       },
       "post": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
@@ -737,6 +1594,13 @@ func init() {
             "schema": {
               "$ref": "#/definitions/PaymentCreationRequest"
             }
+          },
+          {
+            "maxLength": 255,
+            "type": "string",
+            "description": "Unique key chosen by the client to make the request idempotent. Retries of the request with the same key get the response to the original request",
+            "name": "Idempotency-Key",
+            "in": "header"
           }
         ],
         "responses": {
@@ -744,10 +1608,329 @@ func init() {
             "description": "Payment created successfully",
             "schema": {
               "$ref": "#/definitions/PaymentCreationResponse"
+            },
+            "headers": {
+              "Location": {
+                "type": "string",
+                "description": "URL of the new payment"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope, or the payment belongs to an organisation the caller has no access to",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "409": {
+            "description": "A payment with the given ID already exists, or a request with the same idempotency key is still being processed",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "422": {
+            "description": "The idempotency key has already been used with a different request",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
+      }
+    },
+    "/payments/bulk": {
+      "post": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "Create several payments at once",
+        "operationId": "bulkCreatePayments",
+        "parameters": [
+          {
+            "name": "Payment bulk creation request",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationRequest"
+            }
+          },
+          {
+            "type": "boolean",
+            "default": true,
+            "description": "Whether payments are only created if all of them can be. When false, valid payments are created and the rest are reported as failed",
+            "name": "atomic",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "201": {
+            "description": "Every payment was created successfully",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "207": {
+            "description": "Some payments could not be created. The rest were, as the request was not atomic",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "409": {
+            "description": "No payment was created because some of them conflict with existing payments or with each other",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "422": {
+            "description": "No payment was created because some of them are not valid",
+            "schema": {
+              "$ref": "#/definitions/PaymentBulkCreationResponse"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
+      }
+    },
+    "/payments/{id}": {
+      "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "Fetch payment",
+        "operationId": "getPayment",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "uuid",
+            "description": "ID of payment to fetch",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Payment details",
+            "schema": {
+              "$ref": "#/definitions/PaymentDetailsResponse"
+            },
+            "headers": {
+              "ETag": {
+                "type": "string",
+                "description": "Current version of the payment, suitable for use in If-Match headers"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "404": {
+            "description": "Payment Not Found",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
+      },
+      "put": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "Update payment details",
+        "operationId": "updatePayment",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "uuid",
+            "description": "ID of payment to update",
+            "name": "id",
+            "in": "path",
+            "required": true
+          },
+          {
+            "description": "New payment details",
+            "name": "Payment update request",
+            "in": "body",
+            "schema": {
+              "$ref": "#/definitions/PaymentUpdateRequest"
+            }
+          },
+          {
+            "type": "string",
+            "description": "ETag of the version of the payment the update is based on. The update will only be applied if it matches the current version of the payment",
+            "name": "If-Match",
+            "in": "header"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Payment details",
+            "schema": {
+              "$ref": "#/definitions/PaymentUpdateResponse"
+            },
+            "headers": {
+              "ETag": {
+                "type": "string",
+                "description": "New version of the payment, suitable for use in If-Match headers"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope, or the update would move the payment to an organisation the caller has no access to",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "404": {
+            "description": "Payment Not Found",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
             }
           },
           "409": {
-            "description": "A payment with the given ID already exists",
+            "description": "The version in the payment details doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "412": {
+            "description": "The ETag in the If-Match header doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "429": {
+            "description": "Too Many Requests"
+          },
+          "500": {
+            "description": "Internal Server Error",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          }
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
+      },
+      "delete": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "tags": [
+          "Payments"
+        ],
+        "summary": "Deletes a payment resource",
+        "operationId": "deletePayment",
+        "parameters": [
+          {
+            "type": "string",
+            "format": "uuid",
+            "description": "ID of payment to delete",
+            "name": "id",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "204": {
+            "description": "Payment deleted OK. No body content will be returned"
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:delete` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "404": {
+            "description": "Payment Not Found",
             "schema": {
               "$ref": "#/definitions/ApiError"
             }
@@ -761,31 +1944,74 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
-      }
-    },
-    "/payments/{id}": {
-      "get": {
+        },
+        "x-required-scopes": [
+          "payments:delete"
+        ]
+      },
+      "patch": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
+        "consumes": [
+          "application/merge-patch+json",
+          "application/vnd.api+json"
+        ],
         "tags": [
           "Payments"
         ],
-        "summary": "Fetch payment",
-        "operationId": "getPayment",
+        "summary": "Patch payment details",
+        "operationId": "patchPayment",
         "parameters": [
           {
             "type": "string",
             "format": "uuid",
-            "description": "ID of payment to fetch",
+            "description": "ID of payment to patch",
             "name": "id",
             "in": "path",
             "required": true
+          },
+          {
+            "description": "Changes to the payment details, as a JSON Merge Patch (RFC 7386) of a payment details document. Members set to null are removed and members left out keep their current values",
+            "name": "Payment patch",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "object"
+            }
+          },
+          {
+            "type": "string",
+            "description": "ETag of the version of the payment the patch is based on. The patch will only be applied if it matches the current version of the payment",
+            "name": "If-Match",
+            "in": "header"
           }
         ],
         "responses": {
           "200": {
             "description": "Payment details",
             "schema": {
-              "$ref": "#/definitions/PaymentDetailsResponse"
+              "$ref": "#/definitions/PaymentUpdateResponse"
+            },
+            "headers": {
+              "ETag": {
+                "type": "string",
+                "description": "New version of the payment, suitable for use in If-Match headers"
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:write` + "`" + ` scope, or the patch would move the payment to an organisation the caller has no access to",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
             }
           },
           "404": {
@@ -794,6 +2020,24 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           },
+          "409": {
+            "description": "The version in the patch doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "412": {
+            "description": "The ETag in the If-Match header doesn't match the current version of the payment, it has been modified in the meantime",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
+          "422": {
+            "description": "The patched payment details are not valid",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
+          },
           "429": {
             "description": "Too Many Requests"
           },
@@ -803,37 +2047,51 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
-      },
-      "put": {
+        },
+        "x-required-scopes": [
+          "payments:write"
+        ]
+      }
+    },
+    "/payments/{id}/versions": {
+      "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
-        "summary": "Update payment details",
-        "operationId": "updatePayment",
+        "summary": "List payment versions",
+        "operationId": "listPaymentVersions",
         "parameters": [
           {
             "type": "string",
             "format": "uuid",
-            "description": "ID of payment to update",
+            "description": "ID of payment whose versions to list",
             "name": "id",
             "in": "path",
             "required": true
-          },
-          {
-            "description": "New payment details",
-            "name": "Payment update request",
-            "in": "body",
-            "schema": {
-              "$ref": "#/definitions/PaymentUpdateRequest"
-            }
           }
         ],
         "responses": {
           "200": {
-            "description": "Payment details",
+            "description": "Every version of the payment, oldest first",
             "schema": {
-              "$ref": "#/definitions/PaymentUpdateResponse"
+              "$ref": "#/definitions/PaymentVersionListResponse"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
             }
           },
           "404": {
@@ -851,30 +2109,63 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
-      },
-      "delete": {
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
+      }
+    },
+    "/payments/{id}/versions/{version}": {
+      "get": {
+        "security": [
+          {
+            "apiKey": []
+          },
+          {
+            "bearer": []
+          }
+        ],
         "tags": [
           "Payments"
         ],
-        "summary": "Deletes a payment resource",
-        "operationId": "deletePayment",
+        "summary": "Fetch payment version",
+        "operationId": "getPaymentVersion",
         "parameters": [
           {
             "type": "string",
             "format": "uuid",
-            "description": "ID of payment to delete",
+            "description": "ID of payment to fetch",
             "name": "id",
             "in": "path",
             "required": true
+          },
+          {
+            "minimum": 0,
+            "type": "integer",
+            "description": "Version number to fetch",
+            "name": "version",
+            "in": "path",
+            "required": true
           }
         ],
         "responses": {
-          "204": {
-            "description": "Payment deleted OK. No body content will be returned"
+          "200": {
+            "description": "Payment version details",
+            "schema": {
+              "$ref": "#/definitions/PaymentVersionResponse"
+            }
+          },
+          "401": {
+            "description": "Unauthorized"
+          },
+          "403": {
+            "description": "The client lacks the ` + "`" + `payments:read` + "`" + ` scope",
+            "schema": {
+              "$ref": "#/definitions/ApiError"
+            }
           },
           "404": {
-            "description": "Payment Not Found",
+            "description": "Payment or version Not Found",
             "schema": {
               "$ref": "#/definitions/ApiError"
             }
@@ -888,7 +2179,10 @@ func init() {
               "$ref": "#/definitions/ApiError"
             }
           }
-        }
+        },
+        "x-required-scopes": [
+          "payments:read"
+        ]
       }
     }
   },
@@ -905,13 +2199,69 @@ func init() {
       "example": "10.00"
     },
     "ApiError": {
+      "description": "Error document, following the [JSON:API format for errors](https://jsonapi.org/format/#errors). ` + "`" + `error_code` + "`" + ` and ` + "`" + `error_message` + "`" + ` are kept for backwards compatibility",
       "type": "object",
       "properties": {
         "error_code": {
-          "type": "string",
-          "format": "uuid"
+          "description": "ID of the request the error happened in, which is also returned in the ` + "`" + `X-Request-ID` + "`" + ` header of the response. Please include it when reporting an error, as it identifies the server logs of the request",
+          "type": "string"
         },
         "error_message": {
+          "description": "Deprecated, the ` + "`" + `detail` + "`" + ` of the first error",
+          "type": "string"
+        },
+        "errors": {
+          "description": "Problems found while processing the request",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/ApiErrorObject"
+          }
+        }
+      }
+    },
+    "ApiErrorObject": {
+      "description": "A problem found while processing a request",
+      "type": "object",
+      "properties": {
+        "code": {
+          "description": "Application specific code of the problem, such as ` + "`" + `required` + "`" + `, ` + "`" + `pattern` + "`" + ` or ` + "`" + `not_found` + "`" + `, that can be used to handle it programmatically",
+          "type": "string"
+        },
+        "detail": {
+          "description": "Explanation specific to this occurrence of the problem",
+          "type": "string"
+        },
+        "id": {
+          "description": "ID of the request the problem was found in, the same as ` + "`" + `error_code` + "`" + `",
+          "type": "string"
+        },
+        "source": {
+          "$ref": "#/definitions/ApiErrorSource"
+        },
+        "status": {
+          "description": "HTTP status code applicable to the problem, as a string",
+          "type": "string"
+        },
+        "title": {
+          "description": "Short summary of the problem, which is the same for every problem with the same code",
+          "type": "string"
+        }
+      }
+    },
+    "ApiErrorSource": {
+      "description": "Part of the request that caused a problem",
+      "type": "object",
+      "properties": {
+        "header": {
+          "description": "Name of the request header that caused the problem",
+          "type": "string"
+        },
+        "parameter": {
+          "description": "Name of the query or path parameter that caused the problem",
+          "type": "string"
+        },
+        "pointer": {
+          "description": "[JSON Pointer](https://tools.ietf.org/html/rfc6901) to the field of the request body that caused the problem, such as ` + "`" + `/data/attributes/amount` + "`" + `",
           "type": "string"
         }
       }
@@ -1014,10 +2364,23 @@ func init() {
         }
       }
     },
+    "ListMeta": {
+      "type": "object",
+      "required": [
+        "total"
+      ],
+      "properties": {
+        "total": {
+          "description": "Total number of resources in the list, across all of its pages",
+          "type": "integer",
+          "minimum": 0,
+          "example": 42
+        }
+      }
+    },
     "Payment": {
       "type": "object",
       "required": [
-        "id",
         "organisation_id",
         "attributes"
       ],
@@ -1150,9 +2513,10 @@ func init() {
           }
         },
         "id": {
-          "description": "Unique resource ID",
+          "description": "Unique resource ID. It is generated by the server when a payment is created without it",
           "type": "string",
           "format": "uuid",
+          "x-nullable": true,
           "example": "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
         },
         "organisation_id": {
@@ -1175,6 +2539,92 @@ func init() {
         }
       }
     },
+    "PaymentBulkCreationMeta": {
+      "type": "object",
+      "required": [
+        "created",
+        "failed"
+      ],
+      "properties": {
+        "created": {
+          "description": "Number of payments created",
+          "type": "integer",
+          "minimum": 0,
+          "example": 998
+        },
+        "failed": {
+          "description": "Number of payments that could not be created",
+          "type": "integer",
+          "minimum": 0,
+          "example": 2
+        }
+      }
+    },
+    "PaymentBulkCreationRequest": {
+      "type": "object",
+      "required": [
+        "data"
+      ],
+      "properties": {
+        "data": {
+          "description": "Payments to create. Each payment is validated on its own, so that invalid payments can be reported individually",
+          "type": "array",
+          "maxItems": 5000,
+          "minItems": 1,
+          "items": {
+            "type": "object"
+          }
+        }
+      }
+    },
+    "PaymentBulkCreationResponse": {
+      "type": "object",
+      "required": [
+        "data",
+        "meta"
+      ],
+      "properties": {
+        "data": {
+          "description": "Result for each payment in the request, in the same order",
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PaymentBulkCreationResult"
+          }
+        },
+        "meta": {
+          "$ref": "#/definitions/PaymentBulkCreationMeta"
+        }
+      }
+    },
+    "PaymentBulkCreationResult": {
+      "type": "object",
+      "required": [
+        "index",
+        "status"
+      ],
+      "properties": {
+        "data": {
+          "$ref": "#/definitions/Payment"
+        },
+        "error": {
+          "$ref": "#/definitions/ApiError"
+        },
+        "index": {
+          "description": "Position of the payment in the request",
+          "type": "integer",
+          "minimum": 0,
+          "example": 0
+        },
+        "links": {
+          "$ref": "#/definitions/Links"
+        },
+        "status": {
+          "description": "Status code for the payment, 201 if it was created. 409 and 422 mean that the payment conflicts with an existing one or is not valid, and 424 that it was not created because other payments in the request failed",
+          "type": "integer",
+          "example": 201
+        }
+      }
+    },
     "PaymentCreationRequest": {
       "type": "object",
       "required": [
@@ -1211,6 +2661,9 @@ func init() {
         },
         "links": {
           "$ref": "#/definitions/Links"
+        },
+        "meta": {
+          "$ref": "#/definitions/ListMeta"
         }
       }
     },
@@ -1292,6 +2745,85 @@ func init() {
           "$ref": "#/definitions/Links"
         }
       }
+    },
+    "PaymentVersion": {
+      "type": "object",
+      "required": [
+        "data",
+        "operation",
+        "recorded_at",
+        "version"
+      ],
+      "properties": {
+        "data": {
+          "description": "Details of the payment at this version",
+          "$ref": "#/definitions/Payment"
+        },
+        "operation": {
+          "description": "Operation that produced this version",
+          "type": "string",
+          "enum": [
+            "create",
+            "update",
+            "delete"
+          ],
+          "example": "update"
+        },
+        "recorded_at": {
+          "description": "Time at which this version was recorded",
+          "type": "string",
+          "format": "date-time",
+          "example": "2019-03-01T12:00:00.000Z"
+        },
+        "version": {
+          "description": "Version number",
+          "type": "integer",
+          "minimum": 0,
+          "example": 0
+        }
+      }
+    },
+    "PaymentVersionListResponse": {
+      "type": "object",
+      "properties": {
+        "data": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PaymentVersion"
+          }
+        },
+        "links": {
+          "$ref": "#/definitions/Links"
+        }
+      }
+    },
+    "PaymentVersionResponse": {
+      "type": "object",
+      "required": [
+        "data"
+      ],
+      "properties": {
+        "data": {
+          "$ref": "#/definitions/PaymentVersion"
+        },
+        "links": {
+          "$ref": "#/definitions/Links"
+        }
+      }
+    }
+  },
+  "securityDefinitions": {
+    "apiKey": {
+      "description": "API key sent in the ` + "`" + `X-API-Key` + "`" + ` header. The scopes required by each operation, listed in its ` + "`" + `x-required-scopes` + "`" + ` extension, must be granted to the key",
+      "type": "apiKey",
+      "name": "X-API-Key",
+      "in": "header"
+    },
+    "bearer": {
+      "description": "JWT signed with HS256 or RS256, sent in the ` + "`" + `Authorization` + "`" + ` header as ` + "`" + `Bearer \u003ctoken\u003e` + "`" + `. The scopes required by each operation, listed in its ` + "`" + `x-required-scopes` + "`" + ` extension, must be listed in the ` + "`" + `scope` + "`" + ` claim of the token",
+      "type": "apiKey",
+      "name": "Authorization",
+      "in": "header"
     }
   }
 }`))
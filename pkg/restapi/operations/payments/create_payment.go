@@ -12,16 +12,16 @@ import (
 )
 
 // CreatePaymentHandlerFunc turns a function with the right signature into a create payment handler
-type CreatePaymentHandlerFunc func(CreatePaymentParams) middleware.Responder
+type CreatePaymentHandlerFunc func(CreatePaymentParams, interface{}) middleware.Responder
 
 // Handle executing the request and returning a response
-func (fn CreatePaymentHandlerFunc) Handle(params CreatePaymentParams) middleware.Responder {
-	return fn(params)
+func (fn CreatePaymentHandlerFunc) Handle(params CreatePaymentParams, principal interface{}) middleware.Responder {
+	return fn(params, principal)
 }
 
 // CreatePaymentHandler interface for that can handle valid create payment params
 type CreatePaymentHandler interface {
-	Handle(CreatePaymentParams) middleware.Responder
+	Handle(CreatePaymentParams, interface{}) middleware.Responder
 }
 
 // NewCreatePayment creates a new http.Handler for the create payment operation
@@ -46,12 +46,25 @@ func (o *CreatePayment) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 	var Params = NewCreatePaymentParams()
 
+	uprinc, aCtx, err := o.Context.Authorize(r, route)
+	if err != nil {
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+	if aCtx != nil {
+		r = aCtx
+	}
+	var principal interface{}
+	if uprinc != nil {
+		principal = uprinc
+	}
+
 	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
 		o.Context.Respond(rw, r, route.Produces, route, err)
 		return
 	}
 
-	res := o.Handler.Handle(Params) // actually handle the request
+	res := o.Handler.Handle(Params, principal) // actually handle the request
 
 	o.Context.Respond(rw, r, route.Produces, route, res)
 
@@ -11,6 +11,9 @@ import (
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/validate"
+
+	strfmt "github.com/go-openapi/strfmt"
 
 	models "github.com/volmedo/pAPI/pkg/models"
 )
@@ -31,6 +34,11 @@ type CreatePaymentParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
+	/*Unique key chosen by the client to make the request idempotent. Retries of the request with the same key get the response to the original request
+	  Max Length: 255
+	  In: header
+	*/
+	IdempotencyKey *string
 	/*
 	  In: body
 	*/
@@ -46,6 +54,10 @@ func (o *CreatePaymentParams) BindRequest(r *http.Request, route *middleware.Mat
 
 	o.HTTPRequest = r
 
+	if err := o.bindIdempotencyKey(r.Header[http.CanonicalHeaderKey("Idempotency-Key")], true, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	if runtime.HasBody(r) {
 		defer r.Body.Close()
 		var body models.PaymentCreationRequest
@@ -67,3 +79,35 @@ func (o *CreatePaymentParams) BindRequest(r *http.Request, route *middleware.Mat
 	}
 	return nil
 }
+
+// bindIdempotencyKey binds and validates parameter IdempotencyKey from header.
+func (o *CreatePaymentParams) bindIdempotencyKey(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.IdempotencyKey = &raw
+
+	if err := o.validateIdempotencyKey(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateIdempotencyKey carries on validations for parameter IdempotencyKey
+func (o *CreatePaymentParams) validateIdempotencyKey(formats strfmt.Registry) error {
+
+	if err := validate.MaxLength("Idempotency-Key", "header", (*o.IdempotencyKey), 255); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -21,6 +21,10 @@ const CreatePaymentCreatedCode int = 201
 swagger:response createPaymentCreated
 */
 type CreatePaymentCreated struct {
+	/*URL of the new payment
+
+	 */
+	Location string `json:"Location"`
 
 	/*
 	  In: Body
@@ -34,6 +38,17 @@ func NewCreatePaymentCreated() *CreatePaymentCreated {
 	return &CreatePaymentCreated{}
 }
 
+// WithLocation adds the location to the create payment created response
+func (o *CreatePaymentCreated) WithLocation(location string) *CreatePaymentCreated {
+	o.Location = location
+	return o
+}
+
+// SetLocation sets the location to the create payment created response
+func (o *CreatePaymentCreated) SetLocation(location string) {
+	o.Location = location
+}
+
 // WithPayload adds the payload to the create payment created response
 func (o *CreatePaymentCreated) WithPayload(payload *models.PaymentCreationResponse) *CreatePaymentCreated {
 	o.Payload = payload
@@ -48,6 +63,13 @@ func (o *CreatePaymentCreated) SetPayload(payload *models.PaymentCreationRespons
 // WriteResponse to the client
 func (o *CreatePaymentCreated) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
 
+	// response header Location
+
+	location := o.Location
+	if location != "" {
+		rw.Header().Set("Location", location)
+	}
+
 	rw.WriteHeader(201)
 	if o.Payload != nil {
 		payload := o.Payload
@@ -57,10 +79,78 @@ func (o *CreatePaymentCreated) WriteResponse(rw http.ResponseWriter, producer ru
 	}
 }
 
+// CreatePaymentUnauthorizedCode is the HTTP code returned for type CreatePaymentUnauthorized
+const CreatePaymentUnauthorizedCode int = 401
+
+/*CreatePaymentUnauthorized Unauthorized
+
+swagger:response createPaymentUnauthorized
+*/
+type CreatePaymentUnauthorized struct {
+}
+
+// NewCreatePaymentUnauthorized creates CreatePaymentUnauthorized with default headers values
+func NewCreatePaymentUnauthorized() *CreatePaymentUnauthorized {
+
+	return &CreatePaymentUnauthorized{}
+}
+
+// WriteResponse to the client
+func (o *CreatePaymentUnauthorized) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.Header().Del(runtime.HeaderContentType) //Remove Content-Type on empty responses
+
+	rw.WriteHeader(401)
+}
+
+// CreatePaymentForbiddenCode is the HTTP code returned for type CreatePaymentForbidden
+const CreatePaymentForbiddenCode int = 403
+
+/*CreatePaymentForbidden The client lacks the `payments:write` scope, or the payment belongs to an organisation the caller has no access to
+
+swagger:response createPaymentForbidden
+*/
+type CreatePaymentForbidden struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewCreatePaymentForbidden creates CreatePaymentForbidden with default headers values
+func NewCreatePaymentForbidden() *CreatePaymentForbidden {
+
+	return &CreatePaymentForbidden{}
+}
+
+// WithPayload adds the payload to the create payment forbidden response
+func (o *CreatePaymentForbidden) WithPayload(payload *models.APIError) *CreatePaymentForbidden {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create payment forbidden response
+func (o *CreatePaymentForbidden) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreatePaymentForbidden) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(403)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // CreatePaymentConflictCode is the HTTP code returned for type CreatePaymentConflict
 const CreatePaymentConflictCode int = 409
 
-/*CreatePaymentConflict A payment with the given ID already exists
+/*CreatePaymentConflict A payment with the given ID already exists, or a request with the same idempotency key is still being processed
 
 swagger:response createPaymentConflict
 */
@@ -101,6 +191,50 @@ func (o *CreatePaymentConflict) WriteResponse(rw http.ResponseWriter, producer r
 	}
 }
 
+// CreatePaymentUnprocessableEntityCode is the HTTP code returned for type CreatePaymentUnprocessableEntity
+const CreatePaymentUnprocessableEntityCode int = 422
+
+/*CreatePaymentUnprocessableEntity The idempotency key has already been used with a different request
+
+swagger:response createPaymentUnprocessableEntity
+*/
+type CreatePaymentUnprocessableEntity struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewCreatePaymentUnprocessableEntity creates CreatePaymentUnprocessableEntity with default headers values
+func NewCreatePaymentUnprocessableEntity() *CreatePaymentUnprocessableEntity {
+
+	return &CreatePaymentUnprocessableEntity{}
+}
+
+// WithPayload adds the payload to the create payment unprocessable entity response
+func (o *CreatePaymentUnprocessableEntity) WithPayload(payload *models.APIError) *CreatePaymentUnprocessableEntity {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create payment unprocessable entity response
+func (o *CreatePaymentUnprocessableEntity) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreatePaymentUnprocessableEntity) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(422)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // CreatePaymentTooManyRequestsCode is the HTTP code returned for type CreatePaymentTooManyRequests
 const CreatePaymentTooManyRequestsCode int = 429
 
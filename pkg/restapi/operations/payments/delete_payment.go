@@ -12,16 +12,16 @@ import (
 )
 
 // DeletePaymentHandlerFunc turns a function with the right signature into a delete payment handler
-type DeletePaymentHandlerFunc func(DeletePaymentParams) middleware.Responder
+type DeletePaymentHandlerFunc func(DeletePaymentParams, interface{}) middleware.Responder
 
 // Handle executing the request and returning a response
-func (fn DeletePaymentHandlerFunc) Handle(params DeletePaymentParams) middleware.Responder {
-	return fn(params)
+func (fn DeletePaymentHandlerFunc) Handle(params DeletePaymentParams, principal interface{}) middleware.Responder {
+	return fn(params, principal)
 }
 
 // DeletePaymentHandler interface for that can handle valid delete payment params
 type DeletePaymentHandler interface {
-	Handle(DeletePaymentParams) middleware.Responder
+	Handle(DeletePaymentParams, interface{}) middleware.Responder
 }
 
 // NewDeletePayment creates a new http.Handler for the delete payment operation
@@ -46,12 +46,25 @@ func (o *DeletePayment) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 	var Params = NewDeletePaymentParams()
 
+	uprinc, aCtx, err := o.Context.Authorize(r, route)
+	if err != nil {
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+	if aCtx != nil {
+		r = aCtx
+	}
+	var principal interface{}
+	if uprinc != nil {
+		principal = uprinc
+	}
+
 	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
 		o.Context.Respond(rw, r, route.Produces, route, err)
 		return
 	}
 
-	res := o.Handler.Handle(Params) // actually handle the request
+	res := o.Handler.Handle(Params, principal) // actually handle the request
 
 	o.Context.Respond(rw, r, route.Produces, route, res)
 
@@ -37,6 +37,74 @@ func (o *DeletePaymentNoContent) WriteResponse(rw http.ResponseWriter, producer
 	rw.WriteHeader(204)
 }
 
+// DeletePaymentUnauthorizedCode is the HTTP code returned for type DeletePaymentUnauthorized
+const DeletePaymentUnauthorizedCode int = 401
+
+/*DeletePaymentUnauthorized Unauthorized
+
+swagger:response deletePaymentUnauthorized
+*/
+type DeletePaymentUnauthorized struct {
+}
+
+// NewDeletePaymentUnauthorized creates DeletePaymentUnauthorized with default headers values
+func NewDeletePaymentUnauthorized() *DeletePaymentUnauthorized {
+
+	return &DeletePaymentUnauthorized{}
+}
+
+// WriteResponse to the client
+func (o *DeletePaymentUnauthorized) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.Header().Del(runtime.HeaderContentType) //Remove Content-Type on empty responses
+
+	rw.WriteHeader(401)
+}
+
+// DeletePaymentForbiddenCode is the HTTP code returned for type DeletePaymentForbidden
+const DeletePaymentForbiddenCode int = 403
+
+/*DeletePaymentForbidden The client lacks the `payments:delete` scope
+
+swagger:response deletePaymentForbidden
+*/
+type DeletePaymentForbidden struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewDeletePaymentForbidden creates DeletePaymentForbidden with default headers values
+func NewDeletePaymentForbidden() *DeletePaymentForbidden {
+
+	return &DeletePaymentForbidden{}
+}
+
+// WithPayload adds the payload to the delete payment forbidden response
+func (o *DeletePaymentForbidden) WithPayload(payload *models.APIError) *DeletePaymentForbidden {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the delete payment forbidden response
+func (o *DeletePaymentForbidden) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *DeletePaymentForbidden) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(403)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // DeletePaymentNotFoundCode is the HTTP code returned for type DeletePaymentNotFound
 const DeletePaymentNotFoundCode int = 404
 
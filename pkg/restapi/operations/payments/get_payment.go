@@ -12,16 +12,16 @@ import (
 )
 
 // GetPaymentHandlerFunc turns a function with the right signature into a get payment handler
-type GetPaymentHandlerFunc func(GetPaymentParams) middleware.Responder
+type GetPaymentHandlerFunc func(GetPaymentParams, interface{}) middleware.Responder
 
 // Handle executing the request and returning a response
-func (fn GetPaymentHandlerFunc) Handle(params GetPaymentParams) middleware.Responder {
-	return fn(params)
+func (fn GetPaymentHandlerFunc) Handle(params GetPaymentParams, principal interface{}) middleware.Responder {
+	return fn(params, principal)
 }
 
 // GetPaymentHandler interface for that can handle valid get payment params
 type GetPaymentHandler interface {
-	Handle(GetPaymentParams) middleware.Responder
+	Handle(GetPaymentParams, interface{}) middleware.Responder
 }
 
 // NewGetPayment creates a new http.Handler for the get payment operation
@@ -46,12 +46,25 @@ func (o *GetPayment) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 	var Params = NewGetPaymentParams()
 
+	uprinc, aCtx, err := o.Context.Authorize(r, route)
+	if err != nil {
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+	if aCtx != nil {
+		r = aCtx
+	}
+	var principal interface{}
+	if uprinc != nil {
+		principal = uprinc
+	}
+
 	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
 		o.Context.Respond(rw, r, route.Produces, route, err)
 		return
 	}
 
-	res := o.Handler.Handle(Params) // actually handle the request
+	res := o.Handler.Handle(Params, principal) // actually handle the request
 
 	o.Context.Respond(rw, r, route.Produces, route, res)
 
@@ -21,6 +21,10 @@ const GetPaymentOKCode int = 200
 swagger:response getPaymentOK
 */
 type GetPaymentOK struct {
+	/*Current version of the payment, suitable for use in If-Match headers
+
+	 */
+	ETag string `json:"ETag"`
 
 	/*
 	  In: Body
@@ -34,6 +38,17 @@ func NewGetPaymentOK() *GetPaymentOK {
 	return &GetPaymentOK{}
 }
 
+// WithETag adds the eTag to the get payment o k response
+func (o *GetPaymentOK) WithETag(eTag string) *GetPaymentOK {
+	o.ETag = eTag
+	return o
+}
+
+// SetETag sets the eTag to the get payment o k response
+func (o *GetPaymentOK) SetETag(eTag string) {
+	o.ETag = eTag
+}
+
 // WithPayload adds the payload to the get payment o k response
 func (o *GetPaymentOK) WithPayload(payload *models.PaymentDetailsResponse) *GetPaymentOK {
 	o.Payload = payload
@@ -48,6 +63,13 @@ func (o *GetPaymentOK) SetPayload(payload *models.PaymentDetailsResponse) {
 // WriteResponse to the client
 func (o *GetPaymentOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
 
+	// response header ETag
+
+	eTag := o.ETag
+	if eTag != "" {
+		rw.Header().Set("ETag", eTag)
+	}
+
 	rw.WriteHeader(200)
 	if o.Payload != nil {
 		payload := o.Payload
@@ -57,6 +79,74 @@ func (o *GetPaymentOK) WriteResponse(rw http.ResponseWriter, producer runtime.Pr
 	}
 }
 
+// GetPaymentUnauthorizedCode is the HTTP code returned for type GetPaymentUnauthorized
+const GetPaymentUnauthorizedCode int = 401
+
+/*GetPaymentUnauthorized Unauthorized
+
+swagger:response getPaymentUnauthorized
+*/
+type GetPaymentUnauthorized struct {
+}
+
+// NewGetPaymentUnauthorized creates GetPaymentUnauthorized with default headers values
+func NewGetPaymentUnauthorized() *GetPaymentUnauthorized {
+
+	return &GetPaymentUnauthorized{}
+}
+
+// WriteResponse to the client
+func (o *GetPaymentUnauthorized) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.Header().Del(runtime.HeaderContentType) //Remove Content-Type on empty responses
+
+	rw.WriteHeader(401)
+}
+
+// GetPaymentForbiddenCode is the HTTP code returned for type GetPaymentForbidden
+const GetPaymentForbiddenCode int = 403
+
+/*GetPaymentForbidden The client lacks the `payments:read` scope
+
+swagger:response getPaymentForbidden
+*/
+type GetPaymentForbidden struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewGetPaymentForbidden creates GetPaymentForbidden with default headers values
+func NewGetPaymentForbidden() *GetPaymentForbidden {
+
+	return &GetPaymentForbidden{}
+}
+
+// WithPayload adds the payload to the get payment forbidden response
+func (o *GetPaymentForbidden) WithPayload(payload *models.APIError) *GetPaymentForbidden {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get payment forbidden response
+func (o *GetPaymentForbidden) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetPaymentForbidden) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(403)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // GetPaymentNotFoundCode is the HTTP code returned for type GetPaymentNotFound
 const GetPaymentNotFoundCode int = 404
 
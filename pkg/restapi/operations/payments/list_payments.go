@@ -12,16 +12,16 @@ import (
 )
 
 // ListPaymentsHandlerFunc turns a function with the right signature into a list payments handler
-type ListPaymentsHandlerFunc func(ListPaymentsParams) middleware.Responder
+type ListPaymentsHandlerFunc func(ListPaymentsParams, interface{}) middleware.Responder
 
 // Handle executing the request and returning a response
-func (fn ListPaymentsHandlerFunc) Handle(params ListPaymentsParams) middleware.Responder {
-	return fn(params)
+func (fn ListPaymentsHandlerFunc) Handle(params ListPaymentsParams, principal interface{}) middleware.Responder {
+	return fn(params, principal)
 }
 
 // ListPaymentsHandler interface for that can handle valid list payments params
 type ListPaymentsHandler interface {
-	Handle(ListPaymentsParams) middleware.Responder
+	Handle(ListPaymentsParams, interface{}) middleware.Responder
 }
 
 // NewListPayments creates a new http.Handler for the list payments operation
@@ -46,12 +46,25 @@ func (o *ListPayments) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 	var Params = NewListPaymentsParams()
 
+	uprinc, aCtx, err := o.Context.Authorize(r, route)
+	if err != nil {
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+	if aCtx != nil {
+		r = aCtx
+	}
+	var principal interface{}
+	if uprinc != nil {
+		principal = uprinc
+	}
+
 	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
 		o.Context.Respond(rw, r, route.Produces, route, err)
 		return
 	}
 
-	res := o.Handler.Handle(Params) // actually handle the request
+	res := o.Handler.Handle(Params, principal) // actually handle the request
 
 	o.Context.Respond(rw, r, route.Produces, route, res)
 
@@ -44,6 +44,42 @@ type ListPaymentsParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
+	/*Only list payments with an amount greater than or equal to this one
+	  In: query
+	*/
+	FilterAmountGte *string
+	/*Only list payments with an amount less than or equal to this one
+	  In: query
+	*/
+	FilterAmountLte *string
+	/*Only list payments in this currency, as an ISO 4217 code
+	  In: query
+	*/
+	FilterCurrency *string
+	/*Only list payments created by the organisation with this ID
+	  In: query
+	*/
+	FilterOrganisationID *string
+	/*Only list payments processed through this payment scheme
+	  In: query
+	*/
+	FilterPaymentScheme *string
+	/*Only list payments processed on or after this date (YYYY-MM-DD)
+	  In: query
+	*/
+	FilterProcessingDateGte *string
+	/*Only list payments processed on or before this date (YYYY-MM-DD)
+	  In: query
+	*/
+	FilterProcessingDateLte *string
+	/*Select the page that comes right after this cursor
+	  In: query
+	*/
+	PageAfter *string
+	/*Select the page that comes right before this cursor
+	  In: query
+	*/
+	PageBefore *string
 	/*Which page to select
 	  Minimum: 0
 	  In: query
@@ -57,6 +93,10 @@ type ListPaymentsParams struct {
 	  Default: 10
 	*/
 	PageSize *int64
+	/*Comma separated list of fields to sort payments by. Fields are sorted in ascending order unless prefixed with -
+	  In: query
+	*/
+	Sort *string
 }
 
 // BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
@@ -70,6 +110,51 @@ func (o *ListPaymentsParams) BindRequest(r *http.Request, route *middleware.Matc
 
 	qs := runtime.Values(r.URL.Query())
 
+	qFilterAmountGte, qhkFilterAmountGte, _ := qs.GetOK("filter[amount][gte]")
+	if err := o.bindFilterAmountGte(qFilterAmountGte, qhkFilterAmountGte, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qFilterAmountLte, qhkFilterAmountLte, _ := qs.GetOK("filter[amount][lte]")
+	if err := o.bindFilterAmountLte(qFilterAmountLte, qhkFilterAmountLte, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qFilterCurrency, qhkFilterCurrency, _ := qs.GetOK("filter[currency]")
+	if err := o.bindFilterCurrency(qFilterCurrency, qhkFilterCurrency, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qFilterOrganisationID, qhkFilterOrganisationID, _ := qs.GetOK("filter[organisation_id]")
+	if err := o.bindFilterOrganisationID(qFilterOrganisationID, qhkFilterOrganisationID, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qFilterPaymentScheme, qhkFilterPaymentScheme, _ := qs.GetOK("filter[payment_scheme]")
+	if err := o.bindFilterPaymentScheme(qFilterPaymentScheme, qhkFilterPaymentScheme, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qFilterProcessingDateGte, qhkFilterProcessingDateGte, _ := qs.GetOK("filter[processing_date][gte]")
+	if err := o.bindFilterProcessingDateGte(qFilterProcessingDateGte, qhkFilterProcessingDateGte, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qFilterProcessingDateLte, qhkFilterProcessingDateLte, _ := qs.GetOK("filter[processing_date][lte]")
+	if err := o.bindFilterProcessingDateLte(qFilterProcessingDateLte, qhkFilterProcessingDateLte, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qPageAfter, qhkPageAfter, _ := qs.GetOK("page[after]")
+	if err := o.bindPageAfter(qPageAfter, qhkPageAfter, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qPageBefore, qhkPageBefore, _ := qs.GetOK("page[before]")
+	if err := o.bindPageBefore(qPageBefore, qhkPageBefore, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	qPageNumber, qhkPageNumber, _ := qs.GetOK("page[number]")
 	if err := o.bindPageNumber(qPageNumber, qhkPageNumber, route.Formats); err != nil {
 		res = append(res, err)
@@ -80,12 +165,179 @@ func (o *ListPaymentsParams) BindRequest(r *http.Request, route *middleware.Matc
 		res = append(res, err)
 	}
 
+	qSort, qhkSort, _ := qs.GetOK("sort")
+	if err := o.bindSort(qSort, qhkSort, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+// bindFilterAmountGte binds and validates parameter FilterAmountGte from query.
+func (o *ListPaymentsParams) bindFilterAmountGte(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.FilterAmountGte = &raw
+
+	return nil
+}
+
+// bindFilterAmountLte binds and validates parameter FilterAmountLte from query.
+func (o *ListPaymentsParams) bindFilterAmountLte(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.FilterAmountLte = &raw
+
+	return nil
+}
+
+// bindFilterCurrency binds and validates parameter FilterCurrency from query.
+func (o *ListPaymentsParams) bindFilterCurrency(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.FilterCurrency = &raw
+
+	return nil
+}
+
+// bindFilterOrganisationID binds and validates parameter FilterOrganisationID from query.
+func (o *ListPaymentsParams) bindFilterOrganisationID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.FilterOrganisationID = &raw
+
+	return nil
+}
+
+// bindFilterPaymentScheme binds and validates parameter FilterPaymentScheme from query.
+func (o *ListPaymentsParams) bindFilterPaymentScheme(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.FilterPaymentScheme = &raw
+
+	return nil
+}
+
+// bindFilterProcessingDateGte binds and validates parameter FilterProcessingDateGte from query.
+func (o *ListPaymentsParams) bindFilterProcessingDateGte(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.FilterProcessingDateGte = &raw
+
+	return nil
+}
+
+// bindFilterProcessingDateLte binds and validates parameter FilterProcessingDateLte from query.
+func (o *ListPaymentsParams) bindFilterProcessingDateLte(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.FilterProcessingDateLte = &raw
+
+	return nil
+}
+
+// bindPageAfter binds and validates parameter PageAfter from query.
+func (o *ListPaymentsParams) bindPageAfter(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.PageAfter = &raw
+
+	return nil
+}
+
+// bindPageBefore binds and validates parameter PageBefore from query.
+func (o *ListPaymentsParams) bindPageBefore(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.PageBefore = &raw
+
+	return nil
+}
+
 // bindPageNumber binds and validates parameter PageNumber from query.
 func (o *ListPaymentsParams) bindPageNumber(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
@@ -163,3 +415,21 @@ func (o *ListPaymentsParams) validatePageSize(formats strfmt.Registry) error {
 
 	return nil
 }
+
+// bindSort binds and validates parameter Sort from query.
+func (o *ListPaymentsParams) bindSort(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.Sort = &raw
+
+	return nil
+}
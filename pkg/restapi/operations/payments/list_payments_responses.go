@@ -57,6 +57,118 @@ func (o *ListPaymentsOK) WriteResponse(rw http.ResponseWriter, producer runtime.
 	}
 }
 
+// ListPaymentsBadRequestCode is the HTTP code returned for type ListPaymentsBadRequest
+const ListPaymentsBadRequestCode int = 400
+
+/*ListPaymentsBadRequest Invalid filter, sort or cursor
+
+swagger:response listPaymentsBadRequest
+*/
+type ListPaymentsBadRequest struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewListPaymentsBadRequest creates ListPaymentsBadRequest with default headers values
+func NewListPaymentsBadRequest() *ListPaymentsBadRequest {
+
+	return &ListPaymentsBadRequest{}
+}
+
+// WithPayload adds the payload to the list payments bad request response
+func (o *ListPaymentsBadRequest) WithPayload(payload *models.APIError) *ListPaymentsBadRequest {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list payments bad request response
+func (o *ListPaymentsBadRequest) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListPaymentsBadRequest) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(400)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// ListPaymentsUnauthorizedCode is the HTTP code returned for type ListPaymentsUnauthorized
+const ListPaymentsUnauthorizedCode int = 401
+
+/*ListPaymentsUnauthorized Unauthorized
+
+swagger:response listPaymentsUnauthorized
+*/
+type ListPaymentsUnauthorized struct {
+}
+
+// NewListPaymentsUnauthorized creates ListPaymentsUnauthorized with default headers values
+func NewListPaymentsUnauthorized() *ListPaymentsUnauthorized {
+
+	return &ListPaymentsUnauthorized{}
+}
+
+// WriteResponse to the client
+func (o *ListPaymentsUnauthorized) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.Header().Del(runtime.HeaderContentType) //Remove Content-Type on empty responses
+
+	rw.WriteHeader(401)
+}
+
+// ListPaymentsForbiddenCode is the HTTP code returned for type ListPaymentsForbidden
+const ListPaymentsForbiddenCode int = 403
+
+/*ListPaymentsForbidden The client lacks the `payments:read` scope
+
+swagger:response listPaymentsForbidden
+*/
+type ListPaymentsForbidden struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewListPaymentsForbidden creates ListPaymentsForbidden with default headers values
+func NewListPaymentsForbidden() *ListPaymentsForbidden {
+
+	return &ListPaymentsForbidden{}
+}
+
+// WithPayload adds the payload to the list payments forbidden response
+func (o *ListPaymentsForbidden) WithPayload(payload *models.APIError) *ListPaymentsForbidden {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the list payments forbidden response
+func (o *ListPaymentsForbidden) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *ListPaymentsForbidden) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(403)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // ListPaymentsNotFoundCode is the HTTP code returned for type ListPaymentsNotFound
 const ListPaymentsNotFoundCode int = 404
 
@@ -15,8 +15,18 @@ import (
 
 // ListPaymentsURL generates an URL for the list payments operation
 type ListPaymentsURL struct {
-	PageNumber *int64
-	PageSize   *int64
+	FilterAmountGte         *string
+	FilterAmountLte         *string
+	FilterCurrency          *string
+	FilterOrganisationID    *string
+	FilterPaymentScheme     *string
+	FilterProcessingDateGte *string
+	FilterProcessingDateLte *string
+	PageAfter               *string
+	PageBefore              *string
+	PageNumber              *int64
+	PageSize                *int64
+	Sort                    *string
 
 	_basePath string
 	// avoid unkeyed usage
@@ -52,6 +62,78 @@ func (o *ListPaymentsURL) Build() (*url.URL, error) {
 
 	qs := make(url.Values)
 
+	var filterAmountGte string
+	if o.FilterAmountGte != nil {
+		filterAmountGte = *o.FilterAmountGte
+	}
+	if filterAmountGte != "" {
+		qs.Set("filter[amount][gte]", filterAmountGte)
+	}
+
+	var filterAmountLte string
+	if o.FilterAmountLte != nil {
+		filterAmountLte = *o.FilterAmountLte
+	}
+	if filterAmountLte != "" {
+		qs.Set("filter[amount][lte]", filterAmountLte)
+	}
+
+	var filterCurrency string
+	if o.FilterCurrency != nil {
+		filterCurrency = *o.FilterCurrency
+	}
+	if filterCurrency != "" {
+		qs.Set("filter[currency]", filterCurrency)
+	}
+
+	var filterOrganisationID string
+	if o.FilterOrganisationID != nil {
+		filterOrganisationID = *o.FilterOrganisationID
+	}
+	if filterOrganisationID != "" {
+		qs.Set("filter[organisation_id]", filterOrganisationID)
+	}
+
+	var filterPaymentScheme string
+	if o.FilterPaymentScheme != nil {
+		filterPaymentScheme = *o.FilterPaymentScheme
+	}
+	if filterPaymentScheme != "" {
+		qs.Set("filter[payment_scheme]", filterPaymentScheme)
+	}
+
+	var filterProcessingDateGte string
+	if o.FilterProcessingDateGte != nil {
+		filterProcessingDateGte = *o.FilterProcessingDateGte
+	}
+	if filterProcessingDateGte != "" {
+		qs.Set("filter[processing_date][gte]", filterProcessingDateGte)
+	}
+
+	var filterProcessingDateLte string
+	if o.FilterProcessingDateLte != nil {
+		filterProcessingDateLte = *o.FilterProcessingDateLte
+	}
+	if filterProcessingDateLte != "" {
+		qs.Set("filter[processing_date][lte]", filterProcessingDateLte)
+	}
+
+	var pageAfter string
+	if o.PageAfter != nil {
+		pageAfter = *o.PageAfter
+	}
+	if pageAfter != "" {
+		qs.Set("page[after]", pageAfter)
+	}
+
+	var pageBefore string
+	if o.PageBefore != nil {
+		pageBefore = *o.PageBefore
+	}
+	if pageBefore != "" {
+		qs.Set("page[before]", pageBefore)
+	}
+
 	var pageNumber string
 	if o.PageNumber != nil {
 		pageNumber = swag.FormatInt64(*o.PageNumber)
@@ -68,6 +150,14 @@ func (o *ListPaymentsURL) Build() (*url.URL, error) {
 		qs.Set("page[size]", pageSize)
 	}
 
+	var sort string
+	if o.Sort != nil {
+		sort = *o.Sort
+	}
+	if sort != "" {
+		qs.Set("sort", sort)
+	}
+
 	_result.RawQuery = qs.Encode()
 
 	return &_result, nil
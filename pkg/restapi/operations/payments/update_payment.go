@@ -12,16 +12,16 @@ import (
 )
 
 // UpdatePaymentHandlerFunc turns a function with the right signature into a update payment handler
-type UpdatePaymentHandlerFunc func(UpdatePaymentParams) middleware.Responder
+type UpdatePaymentHandlerFunc func(UpdatePaymentParams, interface{}) middleware.Responder
 
 // Handle executing the request and returning a response
-func (fn UpdatePaymentHandlerFunc) Handle(params UpdatePaymentParams) middleware.Responder {
-	return fn(params)
+func (fn UpdatePaymentHandlerFunc) Handle(params UpdatePaymentParams, principal interface{}) middleware.Responder {
+	return fn(params, principal)
 }
 
 // UpdatePaymentHandler interface for that can handle valid update payment params
 type UpdatePaymentHandler interface {
-	Handle(UpdatePaymentParams) middleware.Responder
+	Handle(UpdatePaymentParams, interface{}) middleware.Responder
 }
 
 // NewUpdatePayment creates a new http.Handler for the update payment operation
@@ -46,12 +46,25 @@ func (o *UpdatePayment) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 	var Params = NewUpdatePaymentParams()
 
+	uprinc, aCtx, err := o.Context.Authorize(r, route)
+	if err != nil {
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+	if aCtx != nil {
+		r = aCtx
+	}
+	var principal interface{}
+	if uprinc != nil {
+		principal = uprinc
+	}
+
 	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
 		o.Context.Respond(rw, r, route.Produces, route, err)
 		return
 	}
 
-	res := o.Handler.Handle(Params) // actually handle the request
+	res := o.Handler.Handle(Params, principal) // actually handle the request
 
 	o.Context.Respond(rw, r, route.Produces, route, res)
 
@@ -34,6 +34,10 @@ type UpdatePaymentParams struct {
 	// HTTP Request Object
 	HTTPRequest *http.Request `json:"-"`
 
+	/*ETag of the version of the payment the update is based on. The update will only be applied if it matches the current version of the payment
+	  In: header
+	*/
+	IfMatch *string
 	/*New payment details
 	  In: body
 	*/
@@ -54,6 +58,10 @@ func (o *UpdatePaymentParams) BindRequest(r *http.Request, route *middleware.Mat
 
 	o.HTTPRequest = r
 
+	if err := o.bindIfMatch(r.Header[http.CanonicalHeaderKey("If-Match")], true, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
 	if runtime.HasBody(r) {
 		defer r.Body.Close()
 		var body models.PaymentUpdateRequest
@@ -81,6 +89,24 @@ func (o *UpdatePaymentParams) BindRequest(r *http.Request, route *middleware.Mat
 	return nil
 }
 
+// bindIfMatch binds and validates parameter IfMatch from header.
+func (o *UpdatePaymentParams) bindIfMatch(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	o.IfMatch = &raw
+
+	return nil
+}
+
 // bindID binds and validates parameter ID from path.
 func (o *UpdatePaymentParams) bindID(rawData []string, hasKey bool, formats strfmt.Registry) error {
 	var raw string
@@ -21,6 +21,10 @@ const UpdatePaymentOKCode int = 200
 swagger:response updatePaymentOK
 */
 type UpdatePaymentOK struct {
+	/*New version of the payment, suitable for use in If-Match headers
+
+	 */
+	ETag string `json:"ETag"`
 
 	/*
 	  In: Body
@@ -34,6 +38,17 @@ func NewUpdatePaymentOK() *UpdatePaymentOK {
 	return &UpdatePaymentOK{}
 }
 
+// WithETag adds the eTag to the update payment o k response
+func (o *UpdatePaymentOK) WithETag(eTag string) *UpdatePaymentOK {
+	o.ETag = eTag
+	return o
+}
+
+// SetETag sets the eTag to the update payment o k response
+func (o *UpdatePaymentOK) SetETag(eTag string) {
+	o.ETag = eTag
+}
+
 // WithPayload adds the payload to the update payment o k response
 func (o *UpdatePaymentOK) WithPayload(payload *models.PaymentUpdateResponse) *UpdatePaymentOK {
 	o.Payload = payload
@@ -48,6 +63,13 @@ func (o *UpdatePaymentOK) SetPayload(payload *models.PaymentUpdateResponse) {
 // WriteResponse to the client
 func (o *UpdatePaymentOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
 
+	// response header ETag
+
+	eTag := o.ETag
+	if eTag != "" {
+		rw.Header().Set("ETag", eTag)
+	}
+
 	rw.WriteHeader(200)
 	if o.Payload != nil {
 		payload := o.Payload
@@ -57,6 +79,74 @@ func (o *UpdatePaymentOK) WriteResponse(rw http.ResponseWriter, producer runtime
 	}
 }
 
+// UpdatePaymentUnauthorizedCode is the HTTP code returned for type UpdatePaymentUnauthorized
+const UpdatePaymentUnauthorizedCode int = 401
+
+/*UpdatePaymentUnauthorized Unauthorized
+
+swagger:response updatePaymentUnauthorized
+*/
+type UpdatePaymentUnauthorized struct {
+}
+
+// NewUpdatePaymentUnauthorized creates UpdatePaymentUnauthorized with default headers values
+func NewUpdatePaymentUnauthorized() *UpdatePaymentUnauthorized {
+
+	return &UpdatePaymentUnauthorized{}
+}
+
+// WriteResponse to the client
+func (o *UpdatePaymentUnauthorized) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.Header().Del(runtime.HeaderContentType) //Remove Content-Type on empty responses
+
+	rw.WriteHeader(401)
+}
+
+// UpdatePaymentForbiddenCode is the HTTP code returned for type UpdatePaymentForbidden
+const UpdatePaymentForbiddenCode int = 403
+
+/*UpdatePaymentForbidden The client lacks the `payments:write` scope, or the update would move the payment to an organisation the caller has no access to
+
+swagger:response updatePaymentForbidden
+*/
+type UpdatePaymentForbidden struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewUpdatePaymentForbidden creates UpdatePaymentForbidden with default headers values
+func NewUpdatePaymentForbidden() *UpdatePaymentForbidden {
+
+	return &UpdatePaymentForbidden{}
+}
+
+// WithPayload adds the payload to the update payment forbidden response
+func (o *UpdatePaymentForbidden) WithPayload(payload *models.APIError) *UpdatePaymentForbidden {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the update payment forbidden response
+func (o *UpdatePaymentForbidden) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UpdatePaymentForbidden) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(403)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // UpdatePaymentNotFoundCode is the HTTP code returned for type UpdatePaymentNotFound
 const UpdatePaymentNotFoundCode int = 404
 
@@ -101,6 +191,94 @@ func (o *UpdatePaymentNotFound) WriteResponse(rw http.ResponseWriter, producer r
 	}
 }
 
+// UpdatePaymentConflictCode is the HTTP code returned for type UpdatePaymentConflict
+const UpdatePaymentConflictCode int = 409
+
+/*UpdatePaymentConflict The version in the payment details doesn't match the current version of the payment, it has been modified in the meantime
+
+swagger:response updatePaymentConflict
+*/
+type UpdatePaymentConflict struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewUpdatePaymentConflict creates UpdatePaymentConflict with default headers values
+func NewUpdatePaymentConflict() *UpdatePaymentConflict {
+
+	return &UpdatePaymentConflict{}
+}
+
+// WithPayload adds the payload to the update payment conflict response
+func (o *UpdatePaymentConflict) WithPayload(payload *models.APIError) *UpdatePaymentConflict {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the update payment conflict response
+func (o *UpdatePaymentConflict) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UpdatePaymentConflict) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(409)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+// UpdatePaymentPreconditionFailedCode is the HTTP code returned for type UpdatePaymentPreconditionFailed
+const UpdatePaymentPreconditionFailedCode int = 412
+
+/*UpdatePaymentPreconditionFailed The ETag in the If-Match header doesn't match the current version of the payment, it has been modified in the meantime
+
+swagger:response updatePaymentPreconditionFailed
+*/
+type UpdatePaymentPreconditionFailed struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.APIError `json:"body,omitempty"`
+}
+
+// NewUpdatePaymentPreconditionFailed creates UpdatePaymentPreconditionFailed with default headers values
+func NewUpdatePaymentPreconditionFailed() *UpdatePaymentPreconditionFailed {
+
+	return &UpdatePaymentPreconditionFailed{}
+}
+
+// WithPayload adds the payload to the update payment precondition failed response
+func (o *UpdatePaymentPreconditionFailed) WithPayload(payload *models.APIError) *UpdatePaymentPreconditionFailed {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the update payment precondition failed response
+func (o *UpdatePaymentPreconditionFailed) SetPayload(payload *models.APIError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *UpdatePaymentPreconditionFailed) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(412)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 // UpdatePaymentTooManyRequestsCode is the HTTP code returned for type UpdatePaymentTooManyRequests
 const UpdatePaymentTooManyRequestsCode int = 429
 
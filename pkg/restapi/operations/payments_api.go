@@ -39,21 +39,46 @@ func NewPaymentsAPI(spec *loads.Document) *PaymentsAPI {
 		BearerAuthenticator: security.BearerAuth,
 		JSONConsumer:        runtime.JSONConsumer(),
 		JSONProducer:        runtime.JSONProducer(),
-		PaymentsCreatePaymentHandler: payments.CreatePaymentHandlerFunc(func(params payments.CreatePaymentParams) middleware.Responder {
+		PaymentsBulkCreatePaymentsHandler: payments.BulkCreatePaymentsHandlerFunc(func(params payments.BulkCreatePaymentsParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation PaymentsBulkCreatePayments has not yet been implemented")
+		}),
+		PaymentsCreatePaymentHandler: payments.CreatePaymentHandlerFunc(func(params payments.CreatePaymentParams, principal interface{}) middleware.Responder {
 			return middleware.NotImplemented("operation PaymentsCreatePayment has not yet been implemented")
 		}),
-		PaymentsDeletePaymentHandler: payments.DeletePaymentHandlerFunc(func(params payments.DeletePaymentParams) middleware.Responder {
+		PaymentsDeletePaymentHandler: payments.DeletePaymentHandlerFunc(func(params payments.DeletePaymentParams, principal interface{}) middleware.Responder {
 			return middleware.NotImplemented("operation PaymentsDeletePayment has not yet been implemented")
 		}),
-		PaymentsGetPaymentHandler: payments.GetPaymentHandlerFunc(func(params payments.GetPaymentParams) middleware.Responder {
+		PaymentsGetPaymentHandler: payments.GetPaymentHandlerFunc(func(params payments.GetPaymentParams, principal interface{}) middleware.Responder {
 			return middleware.NotImplemented("operation PaymentsGetPayment has not yet been implemented")
 		}),
-		PaymentsListPaymentsHandler: payments.ListPaymentsHandlerFunc(func(params payments.ListPaymentsParams) middleware.Responder {
+		PaymentsGetPaymentVersionHandler: payments.GetPaymentVersionHandlerFunc(func(params payments.GetPaymentVersionParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation PaymentsGetPaymentVersion has not yet been implemented")
+		}),
+		PaymentsListPaymentVersionsHandler: payments.ListPaymentVersionsHandlerFunc(func(params payments.ListPaymentVersionsParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation PaymentsListPaymentVersions has not yet been implemented")
+		}),
+		PaymentsListPaymentsHandler: payments.ListPaymentsHandlerFunc(func(params payments.ListPaymentsParams, principal interface{}) middleware.Responder {
 			return middleware.NotImplemented("operation PaymentsListPayments has not yet been implemented")
 		}),
-		PaymentsUpdatePaymentHandler: payments.UpdatePaymentHandlerFunc(func(params payments.UpdatePaymentParams) middleware.Responder {
+		PaymentsPatchPaymentHandler: payments.PatchPaymentHandlerFunc(func(params payments.PatchPaymentParams, principal interface{}) middleware.Responder {
+			return middleware.NotImplemented("operation PaymentsPatchPayment has not yet been implemented")
+		}),
+		PaymentsUpdatePaymentHandler: payments.UpdatePaymentHandlerFunc(func(params payments.UpdatePaymentParams, principal interface{}) middleware.Responder {
 			return middleware.NotImplemented("operation PaymentsUpdatePayment has not yet been implemented")
 		}),
+
+		// Applies when the "X-API-Key" header is set
+		APIKeyAuth: func(token string) (interface{}, error) {
+			return nil, errors.NotImplemented("api key auth (apiKey) X-API-Key from header param [X-API-Key] has not yet been implemented")
+		},
+
+		// Applies when the "Authorization" header is set
+		BearerAuth: func(token string) (interface{}, error) {
+			return nil, errors.NotImplemented("api key auth (bearer) Authorization from header param [Authorization] has not yet been implemented")
+		},
+
+		// default authorizer is authorized meaning no requirements
+		APIAuthorizer: security.Authorized(),
 	}
 }
 
@@ -85,14 +110,33 @@ type PaymentsAPI struct {
 	// JSONProducer registers a producer for a "application/vnd.api+json" mime type
 	JSONProducer runtime.Producer
 
+	// APIKeyAuth registers a function that takes a token and returns a principal
+	// it performs authentication based on an api key X-API-Key provided in the header
+	APIKeyAuth func(string) (interface{}, error)
+
+	// BearerAuth registers a function that takes a token and returns a principal
+	// it performs authentication based on an api key Authorization provided in the header
+	BearerAuth func(string) (interface{}, error)
+
+	// APIAuthorizer provides access control (ACL/RBAC/ABAC) by providing access to the request and authenticated principal
+	APIAuthorizer runtime.Authorizer
+
+	// PaymentsBulkCreatePaymentsHandler sets the operation handler for the bulk create payments operation
+	PaymentsBulkCreatePaymentsHandler payments.BulkCreatePaymentsHandler
 	// PaymentsCreatePaymentHandler sets the operation handler for the create payment operation
 	PaymentsCreatePaymentHandler payments.CreatePaymentHandler
 	// PaymentsDeletePaymentHandler sets the operation handler for the delete payment operation
 	PaymentsDeletePaymentHandler payments.DeletePaymentHandler
 	// PaymentsGetPaymentHandler sets the operation handler for the get payment operation
 	PaymentsGetPaymentHandler payments.GetPaymentHandler
+	// PaymentsGetPaymentVersionHandler sets the operation handler for the get payment version operation
+	PaymentsGetPaymentVersionHandler payments.GetPaymentVersionHandler
+	// PaymentsListPaymentVersionsHandler sets the operation handler for the list payment versions operation
+	PaymentsListPaymentVersionsHandler payments.ListPaymentVersionsHandler
 	// PaymentsListPaymentsHandler sets the operation handler for the list payments operation
 	PaymentsListPaymentsHandler payments.ListPaymentsHandler
+	// PaymentsPatchPaymentHandler sets the operation handler for the patch payment operation
+	PaymentsPatchPaymentHandler payments.PatchPaymentHandler
 	// PaymentsUpdatePaymentHandler sets the operation handler for the update payment operation
 	PaymentsUpdatePaymentHandler payments.UpdatePaymentHandler
 
@@ -158,6 +202,18 @@ func (o *PaymentsAPI) Validate() error {
 		unregistered = append(unregistered, "JSONProducer")
 	}
 
+	if o.APIKeyAuth == nil {
+		unregistered = append(unregistered, "XAPIKeyAuth")
+	}
+
+	if o.BearerAuth == nil {
+		unregistered = append(unregistered, "AuthorizationAuth")
+	}
+
+	if o.PaymentsBulkCreatePaymentsHandler == nil {
+		unregistered = append(unregistered, "payments.BulkCreatePaymentsHandler")
+	}
+
 	if o.PaymentsCreatePaymentHandler == nil {
 		unregistered = append(unregistered, "payments.CreatePaymentHandler")
 	}
@@ -170,10 +226,22 @@ func (o *PaymentsAPI) Validate() error {
 		unregistered = append(unregistered, "payments.GetPaymentHandler")
 	}
 
+	if o.PaymentsGetPaymentVersionHandler == nil {
+		unregistered = append(unregistered, "payments.GetPaymentVersionHandler")
+	}
+
+	if o.PaymentsListPaymentVersionsHandler == nil {
+		unregistered = append(unregistered, "payments.ListPaymentVersionsHandler")
+	}
+
 	if o.PaymentsListPaymentsHandler == nil {
 		unregistered = append(unregistered, "payments.ListPaymentsHandler")
 	}
 
+	if o.PaymentsPatchPaymentHandler == nil {
+		unregistered = append(unregistered, "payments.PatchPaymentHandler")
+	}
+
 	if o.PaymentsUpdatePaymentHandler == nil {
 		unregistered = append(unregistered, "payments.UpdatePaymentHandler")
 	}
@@ -193,14 +261,28 @@ func (o *PaymentsAPI) ServeErrorFor(operationID string) func(http.ResponseWriter
 // AuthenticatorsFor gets the authenticators for the specified security schemes
 func (o *PaymentsAPI) AuthenticatorsFor(schemes map[string]spec.SecurityScheme) map[string]runtime.Authenticator {
 
-	return nil
+	result := make(map[string]runtime.Authenticator)
+	for name, scheme := range schemes {
+		switch name {
+
+		case "apiKey":
+
+			result[name] = o.APIKeyAuthenticator(scheme.Name, scheme.In, o.APIKeyAuth)
+
+		case "bearer":
+
+			result[name] = o.APIKeyAuthenticator(scheme.Name, scheme.In, o.BearerAuth)
+
+		}
+	}
+	return result
 
 }
 
 // Authorizer returns the registered authorizer
 func (o *PaymentsAPI) Authorizer() runtime.Authorizer {
 
-	return nil
+	return o.APIAuthorizer
 
 }
 
@@ -211,6 +293,9 @@ func (o *PaymentsAPI) ConsumersFor(mediaTypes []string) map[string]runtime.Consu
 	for _, mt := range mediaTypes {
 		switch mt {
 
+		case "application/merge-patch+json":
+			result["application/merge-patch+json"] = o.JSONConsumer
+
 		case "application/vnd.api+json":
 			result["application/vnd.api+json"] = o.JSONConsumer
 
@@ -276,6 +361,11 @@ func (o *PaymentsAPI) initHandlerCache() {
 		o.handlers = make(map[string]map[string]http.Handler)
 	}
 
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/payments/bulk"] = payments.NewBulkCreatePayments(o.context, o.PaymentsBulkCreatePaymentsHandler)
+
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
@@ -291,11 +381,26 @@ func (o *PaymentsAPI) initHandlerCache() {
 	}
 	o.handlers["GET"]["/payments/{id}"] = payments.NewGetPayment(o.context, o.PaymentsGetPaymentHandler)
 
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/payments/{id}/versions/{version}"] = payments.NewGetPaymentVersion(o.context, o.PaymentsGetPaymentVersionHandler)
+
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/payments/{id}/versions"] = payments.NewListPaymentVersions(o.context, o.PaymentsListPaymentVersionsHandler)
+
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
 	o.handlers["GET"]["/payments"] = payments.NewListPayments(o.context, o.PaymentsListPaymentsHandler)
 
+	if o.handlers["PATCH"] == nil {
+		o.handlers["PATCH"] = make(map[string]http.Handler)
+	}
+	o.handlers["PATCH"]["/payments/{id}"] = payments.NewPatchPayment(o.context, o.PaymentsPatchPaymentHandler)
+
 	if o.handlers["PUT"] == nil {
 		o.handlers["PUT"] = make(map[string]http.Handler)
 	}
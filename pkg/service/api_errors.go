@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/errors"
+	"github.com/gofrs/uuid"
+
+	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/tracing"
+)
+
+// Codes of the problems reported in ApiError objects. The codes of problems
+// found validating requests are in validationCodes
+const (
+	codeBadRequest           = "bad_request"
+	codeMalformedBody        = "malformed_body"
+	codeUnauthorized         = "unauthorized"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeMethodNotAllowed     = "method_not_allowed"
+	codeNotAcceptable        = "not_acceptable"
+	codeConflict             = "conflict"
+	codeVersionMismatch      = "version_mismatch"
+	codePreconditionFailed   = "precondition_failed"
+	codePayloadTooLarge      = "payload_too_large"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codeInvalid              = "invalid"
+	codeFailedDependency     = "failed_dependency"
+	codeTooManyRequests      = "too_many_requests"
+	codeInternalError        = "internal_error"
+)
+
+// statusCodes are the codes of the problems reported with an HTTP status
+// when there is nothing more specific to say about them
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            codeBadRequest,
+	http.StatusUnauthorized:          codeUnauthorized,
+	http.StatusForbidden:             codeForbidden,
+	http.StatusNotFound:              codeNotFound,
+	http.StatusMethodNotAllowed:      codeMethodNotAllowed,
+	http.StatusNotAcceptable:         codeNotAcceptable,
+	http.StatusConflict:              codeConflict,
+	http.StatusPreconditionFailed:    codePreconditionFailed,
+	http.StatusRequestEntityTooLarge: codePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  codeUnsupportedMediaType,
+	http.StatusUnprocessableEntity:   codeInvalid,
+	http.StatusFailedDependency:      codeFailedDependency,
+	http.StatusTooManyRequests:       codeTooManyRequests,
+	http.StatusInternalServerError:   codeInternalError,
+}
+
+// errorTitles are the titles of the problems with each code. Problems with
+// codes that are not listed take the text of their HTTP status as title
+var errorTitles = map[string]string{
+	codeMalformedBody:           "Malformed request body",
+	codeVersionMismatch:         "Version mismatch",
+	codeInvalid:                 "Invalid request",
+	"invalid_type":              "Invalid type",
+	"required":                  "Missing required value",
+	"too_long":                  "Value too long",
+	"too_short":                 "Value too short",
+	"pattern":                   "Value doesn't match pattern",
+	"enum":                      "Value not allowed",
+	"multiple_of":               "Value not a multiple",
+	"maximum":                   "Value too large",
+	"minimum":                   "Value too small",
+	"unique":                    "Duplicated items",
+	"max_items":                 "Too many items",
+	"min_items":                 "Too few items",
+	"additional_items":          "Additional items not allowed",
+	"too_few_properties":        "Too few properties",
+	"too_many_properties":       "Too many properties",
+	"unallowed_property":        "Property not allowed",
+	"failed_pattern_properties": "Property name doesn't match pattern",
+}
+
+// internalErrorDetail is the detail of unexpected errors sent to clients
+const internalErrorDetail = "An unexpected error occurred, please report it along with the error code"
+
+// requestID returns the ID of the request stored in ctx, or a random one if
+// there is none, so that errors always have an ID
+func requestID(ctx context.Context) string {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return id
+	}
+
+	id, _ := uuid.NewV4()
+	return id.String()
+}
+
+// newAPIErrorObject creates an object that describes a problem with the given
+// HTTP status and code. The ID of the request stored in ctx is used as ID
+func newAPIErrorObject(ctx context.Context, status int, code, detail string) *models.APIErrorObject {
+	title, ok := errorTitles[code]
+	if !ok {
+		title = http.StatusText(status)
+	}
+
+	return &models.APIErrorObject{
+		ID:     requestID(ctx),
+		Status: strconv.Itoa(status),
+		Code:   code,
+		Title:  title,
+		Detail: detail,
+	}
+}
+
+// newAPIErrors creates an ApiError with the given problems. The error code and
+// message of the ApiError are taken from the first problem
+func newAPIErrors(objects ...*models.APIErrorObject) *models.APIError {
+	apiErr := &models.APIError{Errors: objects}
+	if len(objects) > 0 {
+		apiErr.ErrorCode = objects[0].ID
+		apiErr.ErrorMessage = objects[0].Detail
+	}
+
+	return apiErr
+}
+
+// newAPIError creates an ApiError with a single problem
+func newAPIError(ctx context.Context, status int, code, detail string) *models.APIError {
+	return newAPIErrors(newAPIErrorObject(ctx, status, code, detail))
+}
+
+// repoAPIError creates the ApiError sent to clients for an expected error
+// returned by the repository, such as ErrNoResults or ErrConflict
+func repoAPIError(ctx context.Context, err error) *models.APIError {
+	switch err.(type) {
+	case ErrNoResults:
+		return newAPIError(ctx, http.StatusNotFound, codeNotFound, errorMessage(err))
+	case ErrForbidden:
+		return newAPIError(ctx, http.StatusForbidden, codeForbidden, errorMessage(err))
+	case ErrConflict:
+		return newAPIError(ctx, http.StatusConflict, codeConflict, errorMessage(err))
+	case ErrVersionMismatch:
+		return newAPIError(ctx, http.StatusConflict, codeVersionMismatch, errorMessage(err))
+	case ErrBadOffsetLimit:
+		return newAPIError(ctx, http.StatusBadRequest, codeBadRequest, errorMessage(err))
+	}
+
+	return newAPIError(ctx, http.StatusInternalServerError, codeInternalError, internalErrorDetail)
+}
+
+// invalidAPIError creates the ApiError sent to clients when a document they
+// sent is not valid, with a problem for each invalid field. Any other error
+// found checking a document is unexpected and must go through internalError
+func invalidAPIError(ctx context.Context, errs invalidDocumentError) *models.APIError {
+	return newAPIErrors(fieldErrorObjects(ctx, errs)...)
+}
+
+// ifMatchAPIError creates the ApiError sent to clients when the value of the
+// If-Match header they sent is not valid
+func ifMatchAPIError(ctx context.Context, value string) *models.APIError {
+	detail := fmt.Sprintf("If-Match value %s doesn't match any version of the payment", value)
+	apiErr := newAPIError(ctx, http.StatusPreconditionFailed, codePreconditionFailed, detail)
+	apiErr.Errors[0].Source = &models.APIErrorSource{Header: "If-Match"}
+	return apiErr
+}
+
+// internalError logs an unexpected error that happened in op and returns the
+// ApiError sent to the client. The details of the error are only logged, as
+// they may reveal internals of the service such as SQL queries or driver errors.
+// The error code of the ApiError is the ID of the request, so that the details
+// can be found in the log from the error reported by the client. The error is
+// recorded in the span of the operation too
+func (papi *PaymentsService) internalError(ctx context.Context, op string, err error) *models.APIError {
+	papi.logger(ctx).WithError(err).Errorf("Error on %s", op)
+	tracing.FromContext(ctx).SetError(err)
+	return newAPIError(ctx, http.StatusInternalServerError, codeInternalError, internalErrorDetail)
+}
+
+// repoErrorPrefixes are the prefixes repositories add to the errors they return
+var repoErrorPrefixes = []string{"db: ", "mem: "}
+
+// errorMessage returns the message sent to clients for an expected error, such
+// as those returned by repositories when a payment is not found. Repositories
+// prefix errors with the name of the backend, which is an implementation detail
+func errorMessage(err error) string {
+	msg := err.Error()
+	for _, prefix := range repoErrorPrefixes {
+		msg = strings.TrimPrefix(msg, prefix)
+	}
+
+	return msg
+}
+
+// validationErrorObject creates the object that describes a problem found
+// validating a request. Problems found in query, path or header params point
+// to the invalid param, and those found in the body are left for the caller
+// to point to the invalid field, as the name of the field may not be complete
+func validationErrorObject(ctx context.Context, e *errors.Validation) *models.APIErrorObject {
+	status := int(e.Code())
+	code, ok := validationCodes[e.Code()]
+	if ok || status >= 600 {
+		status = http.StatusUnprocessableEntity
+	} else if code, ok = statusCodes[status]; !ok {
+		status, code = http.StatusUnprocessableEntity, codeInvalid
+	}
+
+	obj := newAPIErrorObject(ctx, status, code, e.Error())
+	switch e.In {
+	case "header":
+		obj.Source = &models.APIErrorSource{Header: e.Name}
+	case "query", "path":
+		obj.Source = &models.APIErrorSource{Parameter: e.Name}
+	}
+
+	return obj
+}
+
+// fieldErrorObjects creates the objects that describe the problems found
+// validating a document, each one pointing to the invalid field. Problems
+// must be named after the full path to the field, such as those returned by
+// schemaErrors
+func fieldErrorObjects(ctx context.Context, errs []*errors.Validation) []*models.APIErrorObject {
+	objects := make([]*models.APIErrorObject, 0, len(errs))
+	for _, e := range errs {
+		obj := validationErrorObject(ctx, e)
+		obj.Source = &models.APIErrorSource{Pointer: jsonPointer(fieldName(e))}
+		objects = append(objects, obj)
+	}
+
+	return objects
+}
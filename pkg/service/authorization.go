@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// requiredScopesExtension is the vendor extension that lists the scopes
+// required by an operation in the spec. Swagger 2.0 only allows scopes in the
+// security requirements of oauth2 schemes, so the API key and bearer schemes
+// of the API leave them empty
+const requiredScopesExtension = "x-required-scopes"
+
+// Authorize checks that the principal that sent r has been granted every scope
+// required by the operation r was routed to, as declared in the
+// x-required-scopes extension of the operation in the spec. Operations that
+// don't declare it are not authorized, so that a missing extension can't
+// leave an operation open to every client.
+//
+// Authorize is meant to be used as the authorizer of the API, which is called
+// once the request has been authenticated and the principal has been stored
+// in the context of r
+func Authorize(r *http.Request) error {
+	route := middleware.MatchedRouteFrom(r)
+	if route == nil || route.Operation == nil {
+		return fmt.Errorf("unable to find the scopes required by the operation")
+	}
+	scopes, ok := route.Operation.Extensions.GetStringSlice(requiredScopesExtension)
+	if !ok {
+		return fmt.Errorf("unable to find the scopes required by the operation")
+	}
+
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		return fmt.Errorf("unknown principal")
+	}
+
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			return fmt.Errorf("the %s scope is required", scope)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// HealthCheck checks whether a dependency of the service is working. It
+// returns an ErrDegraded error if the dependency works, but not at its best
+type HealthCheck func(ctx context.Context) error
+
+// ErrDegraded is returned by health checks that find a dependency that still
+// works but may soon stop doing so, such as a saturated connection pool
+type ErrDegraded string
+
+func (e ErrDegraded) Error() string {
+	return string(e)
+}
+
+// DBPingCheck returns a health check that pings db
+func DBPingCheck(db *sql.DB) HealthCheck {
+	return db.PingContext
+}
+
+// DBSchemaCheck returns a health check that compares the version of the schema
+// of db with the version of the latest migration in migrationsPath. It fails
+// if they differ or if the last migration failed half way, leaving the
+// schema dirty
+func DBSchemaCheck(db *sql.DB, migrationsPath string) (HealthCheck, error) {
+	want, err := latestMigration(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		// The table is managed by golang-migrate, see migrateDB
+		var version int64
+		var dirty bool
+		err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("the DB schema has not been migrated, want version %d", want)
+		}
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("the migration of the DB schema to version %d failed", version)
+		}
+		if uint(version) != want {
+			return fmt.Errorf("the DB schema is at version %d, want version %d", version, want)
+		}
+
+		return nil
+	}, nil
+}
+
+// latestMigration returns the version of the latest migration in migrationsPath
+func latestMigration(migrationsPath string) (uint, error) {
+	src, err := source.Open("file://" + migrationsPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("no migrations found in %s: %v", migrationsPath, err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if os.IsNotExist(err) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// DBPoolCheck returns a health check that reports the connection pool of db
+// as degraded when all of its connections are in use, or when queries had to
+// wait for a connection since the previous check
+func DBPoolCheck(db *sql.DB) HealthCheck {
+	var lastWaitCount int64
+	return func(ctx context.Context) error {
+		stats := db.Stats()
+		waited := stats.WaitCount - atomic.SwapInt64(&lastWaitCount, stats.WaitCount)
+
+		if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections {
+			msg := fmt.Sprintf("all of the %d connections to the DB are in use", stats.MaxOpenConnections)
+			return ErrDegraded(msg)
+		}
+		if waited > 0 {
+			return ErrDegraded(fmt.Sprintf("%d queries waited for a connection to the DB", waited))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDBSchemaCheck(t *testing.T) {
+	tests := map[string]struct {
+		version int64
+		dirty   bool
+		wantErr bool
+	}{
+		"up to date": {
+			version: 4,
+		},
+		"outdated": {
+			version: 3,
+			wantErr: true,
+		},
+		"dirty": {
+			version: 4,
+			dirty:   true,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock connection: %v", err)
+			}
+			defer db.Close()
+
+			check, err := DBSchemaCheck(db, "./migrations")
+			if err != nil {
+				t.Fatalf("Error creating schema check: %v", err)
+			}
+
+			rows := sqlmock.NewRows([]string{"version", "dirty"}).AddRow(tc.version, tc.dirty)
+			mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").WillReturnRows(rows)
+
+			if err := check(context.Background()); (err != nil) != tc.wantErr {
+				t.Errorf("want error: %v but got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := DBSchemaCheck(nil, "./no-such-dir"); err == nil {
+		t.Error("Expected an error creating a check without migrations")
+	}
+}
+
+func TestDBPoolCheck(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock connection: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	check := DBPoolCheck(db)
+	if err := check(context.Background()); err != nil {
+		t.Fatalf("want no error for an idle pool but got %v", err)
+	}
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Error holding a connection: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, ok := check(context.Background()).(ErrDegraded); !ok {
+		t.Error("want the pool reported as degraded with every connection in use")
+	}
+}
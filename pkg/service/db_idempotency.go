@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DBIdempotencyStore is an IdempotencyStore that keeps records in an external
+// database. The schema of the DB must have been migrated beforehand, as
+// NewDBPaymentRepository does
+type DBIdempotencyStore struct {
+	db           *tracedDB
+	ttl          time.Duration
+	queryTimeout time.Duration
+}
+
+// NewDBIdempotencyStore creates a new DBIdempotencyStore that uses a previously
+// configured sql.DB to connect to the DB. Records expire after ttl
+func NewDBIdempotencyStore(db *sql.DB, cfg *DBConfig, ttl time.Duration) *DBIdempotencyStore {
+	return &DBIdempotencyStore{db: &tracedDB{db}, ttl: ttl, queryTimeout: cfg.QueryTimeout}
+}
+
+// Get returns the record saved for the given key
+//
+// Get returns an error if there is no record for key or if it has expired
+func (dbis *DBIdempotencyStore) Get(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	selectStmt := `
+	SELECT fingerprint, response
+	FROM idempotency_keys
+	WHERE key = $1 AND expires_at > now()`
+
+	ctx, cancel := withQueryTimeout(ctx, dbis.queryTimeout)
+	defer cancel()
+	record := &IdempotencyRecord{Key: key}
+	err := dbis.db.QueryRowContext(ctx, selectStmt, key).Scan(&record.Fingerprint, &record.Response)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newErrNoResults(fmt.Sprintf("db: no record for idempotency key %q", key))
+		}
+
+		return nil, fmt.Errorf("db: error executing select: %v", err)
+	}
+	// Pending records have a NULL response
+	record.Pending = record.Response == nil
+
+	return record, nil
+}
+
+// Reserve saves a new pending record, without a response, which will expire
+// when the time to live of the store has passed. Expired records are removed
+// from the DB
+//
+// Reserve returns an error if a record that has not expired yet already
+// exists for the same key
+func (dbis *DBIdempotencyStore) Reserve(ctx context.Context, record *IdempotencyRecord) error {
+	ctx, cancel := withQueryTimeout(ctx, dbis.queryTimeout)
+	defer cancel()
+	_, err := dbis.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return fmt.Errorf("db: error executing delete: %v", err)
+	}
+
+	// The primary key makes sure that only one of several concurrent
+	// requests with the same key gets to reserve it
+	insertStmt := `
+	INSERT INTO idempotency_keys (key, fingerprint, response, expires_at)
+	VALUES ($1, $2, NULL, now() + make_interval(secs => $3))`
+
+	_, err = dbis.db.ExecContext(ctx, insertStmt, record.Key, record.Fingerprint, dbis.ttl.Seconds())
+	if err != nil {
+		if e, ok := err.(*pq.Error); ok && e.Code == "23505" {
+			return newErrConflict(fmt.Sprintf("db: a record for idempotency key %q already exists", record.Key))
+		}
+
+		return fmt.Errorf("db: error executing insert: %v", err)
+	}
+
+	return nil
+}
+
+// Complete saves the response of a pending record, which will expire when
+// the time to live of the store has passed from then on
+//
+// Complete returns an error if there is no pending record for the key
+func (dbis *DBIdempotencyStore) Complete(ctx context.Context, record *IdempotencyRecord) error {
+	updateStmt := `
+	UPDATE idempotency_keys
+	SET response = $2, expires_at = now() + make_interval(secs => $3)
+	WHERE key = $1 AND response IS NULL AND expires_at > now()`
+
+	ctx, cancel := withQueryTimeout(ctx, dbis.queryTimeout)
+	defer cancel()
+	res, err := dbis.db.ExecContext(ctx, updateStmt, record.Key, record.Response, dbis.ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("db: error executing update: %v", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("db: error getting rows affected by update: %v", err)
+	}
+	if count == 0 {
+		return newErrNoResults(fmt.Sprintf("db: no pending record for idempotency key %q", record.Key))
+	}
+
+	return nil
+}
+
+// Release removes the pending record for the given key, so that the key
+// can be used again. Records that have a response are kept
+func (dbis *DBIdempotencyStore) Release(ctx context.Context, key string) error {
+	ctx, cancel := withQueryTimeout(ctx, dbis.queryTimeout)
+	defer cancel()
+	_, err := dbis.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND response IS NULL`, key)
+	if err != nil {
+		return fmt.Errorf("db: error executing delete: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func setupIdempotencyStore(ttl time.Duration) (*DBIdempotencyStore, sqlmock.Sqlmock, error) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		return nil, mock, err
+	}
+
+	return NewDBIdempotencyStore(db, &DBConfig{}, ttl), mock, nil
+}
+
+func TestIdempotencyGet(t *testing.T) {
+	store, mock, err := setupIdempotencyStore(time.Hour)
+	if err != nil {
+		t.Fatalf("Error setting up test store: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"fingerprint", "response"}).AddRow("fingerprint", []byte(`{"data":{}}`))
+	mock.ExpectQuery(`^SELECT fingerprint, response FROM idempotency_keys WHERE key = \$1 AND expires_at > now\(\)$`).
+		WithArgs("key").
+		WillReturnRows(rows)
+
+	got, err := store.Get(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got.Key != "key" || got.Fingerprint != "fingerprint" || string(got.Response) != `{"data":{}}` {
+		t.Errorf("Wrong record: %+v", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestIdempotencyGetNoResults(t *testing.T) {
+	store, mock, err := setupIdempotencyStore(time.Hour)
+	if err != nil {
+		t.Fatalf("Error setting up test store: %v", err)
+	}
+
+	mock.ExpectQuery(`^SELECT fingerprint, response FROM idempotency_keys`).
+		WithArgs("key").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "response"}))
+
+	_, err = store.Get(context.Background(), "key")
+	if _, ok := err.(ErrNoResults); !ok {
+		t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestIdempotencyGetPending(t *testing.T) {
+	store, mock, err := setupIdempotencyStore(time.Hour)
+	if err != nil {
+		t.Fatalf("Error setting up test store: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"fingerprint", "response"}).AddRow("fingerprint", nil)
+	mock.ExpectQuery(`^SELECT fingerprint, response FROM idempotency_keys`).
+		WithArgs("key").
+		WillReturnRows(rows)
+
+	got, err := store.Get(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !got.Pending || got.Response != nil {
+		t.Errorf("Want a pending record without response but got %+v", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestIdempotencyReserve(t *testing.T) {
+	store, mock, err := setupIdempotencyStore(90 * time.Minute)
+	if err != nil {
+		t.Fatalf("Error setting up test store: %v", err)
+	}
+
+	mock.ExpectExec(`^DELETE FROM idempotency_keys WHERE expires_at <= now\(\)$`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`^INSERT INTO idempotency_keys \(key, fingerprint, response, expires_at\) VALUES \(\$1, \$2, NULL, now\(\) \+ make_interval\(secs => \$3\)\)$`).
+		WithArgs("key", "fingerprint", float64(5400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	record := &IdempotencyRecord{Key: "key", Fingerprint: "fingerprint"}
+	if err := store.Reserve(context.Background(), record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestIdempotencyReserveConflict(t *testing.T) {
+	store, mock, err := setupIdempotencyStore(time.Hour)
+	if err != nil {
+		t.Fatalf("Error setting up test store: %v", err)
+	}
+
+	mock.ExpectExec(`^DELETE FROM idempotency_keys`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`^INSERT INTO idempotency_keys`).
+		WillReturnError(&pq.Error{Code: pq.ErrorCode("23505")})
+
+	err = store.Reserve(context.Background(), &IdempotencyRecord{Key: "key", Fingerprint: "fingerprint"})
+	if _, ok := err.(ErrConflict); !ok {
+		t.Errorf("Expected ErrConflict but got %T (%v)", err, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestIdempotencyComplete(t *testing.T) {
+	tests := map[string]struct {
+		rowsAffected int64
+		wantErr      bool
+	}{
+		"pending record": {
+			rowsAffected: 1,
+		},
+		"no pending record": {
+			rowsAffected: 0,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			store, mock, err := setupIdempotencyStore(90 * time.Minute)
+			if err != nil {
+				t.Fatalf("Error setting up test store: %v", err)
+			}
+
+			mock.ExpectExec(`^UPDATE idempotency_keys SET response = \$2, expires_at = now\(\) \+ make_interval\(secs => \$3\) WHERE key = \$1 AND response IS NULL AND expires_at > now\(\)$`).
+				WithArgs("key", []byte(`{"data":{}}`), float64(5400)).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			record := &IdempotencyRecord{Key: "key", Fingerprint: "fingerprint", Response: []byte(`{"data":{}}`)}
+			err = store.Complete(context.Background(), record)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Want error: %v but got %v", tc.wantErr, err)
+			}
+			if _, ok := err.(ErrNoResults); err != nil && !ok {
+				t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Expectations were not met: %s", err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyRelease(t *testing.T) {
+	store, mock, err := setupIdempotencyStore(time.Hour)
+	if err != nil {
+		t.Fatalf("Error setting up test store: %v", err)
+	}
+
+	mock.ExpectExec(`^DELETE FROM idempotency_keys WHERE key = \$1 AND response IS NULL$`).
+		WithArgs("key").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := store.Release(context.Background(), "key"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-openapi/strfmt"
@@ -118,6 +122,11 @@ type DBConfig struct {
 
 	// Path to the folder that contains the migration files
 	MigrationsPath string
+
+	// Maximum time a single query is allowed to run. Queries are cancelled
+	// when this time is exceeded or when the request that triggered them is
+	// cancelled, whatever happens first. A zero value means no timeout
+	QueryTimeout time.Duration
 }
 
 // NewDB initializes a new DB connection object using the configuration paraemters provided
@@ -134,24 +143,26 @@ func NewDB(cfg *DBConfig) (*sql.DB, error) {
 // DBPaymentRepository stores a collection of payment resources using
 // an external database as data backend
 type DBPaymentRepository struct {
-	db *sql.DB
+	db           *tracedDB
+	queryTimeout time.Duration
 }
 
 // NewDBPaymentRepository creates a new DBPaymentRepository that uses a previously
-// configured sql.DB to connect to the DB
-func NewDBPaymentRepository(db *sql.DB, dbName, migrationsPath string) (*DBPaymentRepository, error) {
+// configured sql.DB to connect to the DB. The DB's schema will be migrated to
+// the latest version if both the DB name and the migrations path are set in cfg
+func NewDBPaymentRepository(db *sql.DB, cfg *DBConfig) (*DBPaymentRepository, error) {
 
 	if err := pingDB(db); err != nil {
-		return nil, fmt.Errorf("db: pinging the DB didn't work: %v", err)
+		return nil, fmt.Errorf("db: pinging the DB didn't work: %w", err)
 	}
 
-	if dbName != "" && migrationsPath != "" {
-		if err := migrateDB(db, dbName, migrationsPath); err != nil {
-			return nil, fmt.Errorf("db: migration failed: %v", err)
+	if cfg.Name != "" && cfg.MigrationsPath != "" {
+		if err := migrateDB(db, cfg.Name, cfg.MigrationsPath); err != nil {
+			return nil, fmt.Errorf("db: migration failed: %w", err)
 		}
 	}
 
-	return &DBPaymentRepository{db: db}, nil
+	return &DBPaymentRepository{db: &tracedDB{db}, queryTimeout: cfg.QueryTimeout}, nil
 }
 
 // pingDB tries to connect to a DB, doing some retries just in case
@@ -186,24 +197,48 @@ func migrateDB(db *sql.DB, dbName, migrationsPath string) error {
 	return nil
 }
 
+// withTimeout returns a copy of ctx that will be cancelled when the configured
+// query timeout expires
+func (dbpr *DBPaymentRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withQueryTimeout(ctx, dbpr.queryTimeout)
+}
+
+// withQueryTimeout returns a copy of ctx that will be cancelled when timeout
+// expires. The copy is never cancelled because of a timeout if timeout is not positive
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// dbError wraps an error returned by the DB driver while running a query with
+// ctx, adding msg to it. Drivers report queries interrupted because ctx is done
+// in their own way, such as PostgreSQL canceling the statement, so the error of
+// ctx is wrapped instead in that case, for callers to tell them apart with errors.Is
+func dbError(ctx context.Context, msg string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		return fmt.Errorf("db: %s: %v: %w", msg, err, ctxErr)
+	}
+
+	return fmt.Errorf("db: %s: %w", msg, err)
+}
+
 // Close closes the underlying db instance and frees its associated resources
 func (dbpr *DBPaymentRepository) Close() error {
 	if dbpr.db != nil {
 		if err := dbpr.db.Close(); err != nil {
-			return fmt.Errorf("db: error closing underlying DB connection: %v", err)
+			return fmt.Errorf("db: error closing underlying DB connection: %w", err)
 		}
 	}
 
 	return nil
 }
 
-// Add adds a new payment resource to the repository
-//
-// Add returns an error if a payment with the same ID as the one
-// to be added already exists
-func (dbpr *DBPaymentRepository) Add(payment *models.Payment) (*models.Payment, error) {
-	insertStmt := `
-	INSERT INTO payments (
+// paymentInsertColumns lists the columns written when payments are inserted,
+// in the same order as the values returned by insertValues
+const paymentInsertColumns = `
 		id,
 		organisation,
 		version,
@@ -245,20 +280,14 @@ func (dbpr *DBPaymentRepository) Add(payment *models.Payment) (*models.Payment,
 		scheme_payment_type,
 		sponsor_party.account_number,
 		sponsor_party.bank_id,
-		sponsor_party.bank_id_code
-	)
-	VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-		$11, $12, $13, $14, $15, $16::amount[], $17, $18, $19, $20,
-		$21, $22, $23, $24, $25, $26, $27, $28, $29, $30,
-		$31, $32, $33, $34, $35, $36, $37, $38, $39, $40,
-		$41, $42
-	)`
+		sponsor_party.bank_id_code`
 
-	version := int64(0)
-	attrs := payment.Attributes
+// insertValues returns the values of the columns in paymentInsertColumns for
+// a new payment with the given version
+func insertValues(payment *models.Payment, version int64) []interface{} {
+	attrs := fullAttributes(payment)
 	amounts := senderChargesToAmounts(attrs.ChargesInformation.SenderCharges)
-	_, err := dbpr.db.Exec(insertStmt,
+	return []interface{}{
 		payment.ID,                                       // id,
 		payment.OrganisationID,                           // organisation,
 		version,                                          // version,
@@ -301,14 +330,53 @@ func (dbpr *DBPaymentRepository) Add(payment *models.Payment) (*models.Payment,
 		attrs.SponsorParty.AccountNumber,                 // sponsor_party.account_number,
 		attrs.SponsorParty.BankID,                        // sponsor_party.bank_id,
 		attrs.SponsorParty.BankIDCode,                    // sponsor_party.bank_id_code
+	}
+}
+
+// insertPlaceholders returns the placeholders for the values of a payment in an
+// INSERT statement, numbered after the given number of preceding parameters
+func insertPlaceholders(offset int) string {
+	placeholders := make([]string, paymentInsertValues)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", offset+i+1)
+	}
+	// Sender charges need a cast for the driver to handle the array of amounts
+	placeholders[senderChargesValue] += "::amount[]"
+
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+// paymentInsertValues is the number of values inserted for every payment and
+// senderChargesValue is the position of sender charges among them
+const (
+	paymentInsertValues = 42
+	senderChargesValue  = 15
+)
+
+// Add adds a new payment resource to the repository
+//
+// Add returns an error if a payment with the same ID as the one
+// to be added already exists
+func (dbpr *DBPaymentRepository) Add(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	if err := checkOrganisation(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	insertStmt := `
+	INSERT INTO payments (` + paymentInsertColumns + `
 	)
+	VALUES ` + insertPlaceholders(0)
 
+	version := int64(0)
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	_, err := dbpr.db.ExecContext(ctx, insertStmt, insertValues(payment, version)...)
 	if err != nil {
 		if e, ok := err.(*pq.Error); ok && e.Code == "23505" {
 			return nil, newErrConflict(fmt.Sprintf("db: a payment with ID %s already exists", *payment.ID))
 		}
 
-		return nil, fmt.Errorf("db: error executing insert: %v", err)
+		return nil, dbError(ctx, "error executing insert", err)
 	}
 
 	added := copyPayment(payment)
@@ -318,43 +386,167 @@ func (dbpr *DBPaymentRepository) Add(payment *models.Payment) (*models.Payment,
 	return added, nil
 }
 
-// Delete deletes the payment resource associated to the given paymentID
+// batchInsertRows is the maximum number of payments inserted by a single
+// statement, which keeps the number of parameters under the PostgreSQL limit
+const batchInsertRows = 500
+
+// AddBatch adds several new payment resources to the repository at once
 //
-// Delete returns an error if the paymentID is not present in the respository
-func (dbpr *DBPaymentRepository) Delete(paymentID strfmt.UUID) error {
-	deleteStmt := `DELETE FROM payments WHERE id = $1`
-	res, err := dbpr.db.Exec(deleteStmt, paymentID.String())
+// If atomic is true, either every payment is added or none is. Otherwise,
+// the payments that can't be added are skipped and the rest are added
+//
+// AddBatch returns the added payments in the same order as the given ones,
+// with nil in place of those that were not added. It returns an
+// ErrBatchConflict if some payments have the same ID as an existing payment
+// or as a previous payment in the batch
+func (dbpr *DBPaymentRepository) AddBatch(ctx context.Context, payments []*models.Payment, atomic bool) ([]*models.Payment, error) {
+	for _, payment := range payments {
+		if err := checkOrganisation(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	tx, err := dbpr.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("db: error executing delete: %v", err)
+		return nil, dbError(ctx, "error starting transaction", err)
+	}
+	// Rolling back a committed transaction does nothing
+	defer tx.Rollback()
+
+	version := int64(0)
+	added := make([]*models.Payment, len(payments))
+	conflicts := []int{}
+	for start := 0; start < len(payments); start += batchInsertRows {
+		end := start + batchInsertRows
+		if end > len(payments) {
+			end = len(payments)
+		}
+
+		inserted, err := insertBatch(ctx, tx, payments[start:end], version)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, payment := range payments[start:end] {
+			key := strings.ToLower(payment.ID.String())
+			if !inserted[key] {
+				conflicts = append(conflicts, start+i)
+				continue
+			}
+
+			// Further payments with the same ID in this batch were not inserted
+			delete(inserted, key)
+			added[start+i] = copyPayment(payment)
+			added[start+i].Type = TYPE_PAYMENT
+			added[start+i].Version = &version
+		}
+	}
+
+	var conflictErr error
+	if len(conflicts) > 0 {
+		conflictErr = newErrBatchConflict(fmt.Sprintf("db: %d payments have the same ID as other payments", len(conflicts)), conflicts)
+		if atomic {
+			return nil, conflictErr
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, dbError(ctx, "error committing transaction", err)
 	}
 
-	count, err := res.RowsAffected()
+	return added, conflictErr
+}
+
+// insertBatch inserts the given payments with a single statement, skipping
+// those that have the same ID as an existing payment. It returns the set of
+// IDs, in lower case, of the payments that were actually inserted
+func insertBatch(ctx context.Context, tx *sql.Tx, payments []*models.Payment, version int64) (map[string]bool, error) {
+	rows := make([]string, len(payments))
+	args := make([]interface{}, 0, len(payments)*paymentInsertValues)
+	for i, payment := range payments {
+		rows[i] = insertPlaceholders(i * paymentInsertValues)
+		args = append(args, insertValues(payment, version)...)
+	}
+
+	insertStmt := `
+	INSERT INTO payments (` + paymentInsertColumns + `
+	)
+	VALUES ` + strings.Join(rows, ",\n\t") + `
+	ON CONFLICT (id) DO NOTHING
+	RETURNING id`
+
+	span := startStatementSpan(ctx, insertStmt)
+	res, err := tx.QueryContext(ctx, insertStmt, args...)
+	span.SetError(err)
+	span.End()
 	if err != nil {
-		return fmt.Errorf("db: error getting rows affected by delete: %v", err)
+		return nil, dbError(ctx, "error executing batch insert", err)
 	}
-	if count == 0 {
-		return newErrNoResults(fmt.Sprintf("db: payment with ID %s not found", paymentID))
+	defer res.Close()
+
+	inserted := make(map[string]bool, len(payments))
+	for res.Next() {
+		var id string
+		if err := res.Scan(&id); err != nil {
+			return nil, dbError(ctx, "error scanning inserted ID", err)
+		}
+		inserted[strings.ToLower(id)] = true
+	}
+	if err := res.Err(); err != nil {
+		return nil, dbError(ctx, "error reading inserted IDs", err)
 	}
 
-	return nil
+	return inserted, nil
+}
+
+// Delete deletes the payment resource associated to the given paymentID
+// and returns it as it was before being deleted
+//
+// Delete returns an error if the paymentID is not present in the respository
+func (dbpr *DBPaymentRepository) Delete(ctx context.Context, paymentID strfmt.UUID) (*models.Payment, error) {
+	args := []interface{}{paymentID.String()}
+	conditions := append([]string{"id = $1"}, organisationConditions(ctx, &args)...)
+	deleteStmt := `
+	DELETE FROM payments` + whereClause(conditions) + `
+	RETURNING` + paymentColumns
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	row := dbpr.db.QueryRowContext(ctx, deleteStmt, args...)
+	deleted, err := scanPayment(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newErrNoResults(fmt.Sprintf("db: payment with ID %s not found", paymentID))
+		}
+
+		return nil, dbError(ctx, "error executing delete", err)
+	}
+
+	return deleted, nil
 }
 
-// DeleteAll deletes every payment in the DB
-func (dbpr *DBPaymentRepository) DeleteAll() error {
-	_, err := dbpr.db.Exec(`DELETE FROM payments`)
+// DeleteAll deletes every payment in the DB, along with their version history
+func (dbpr *DBPaymentRepository) DeleteAll(ctx context.Context) error {
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	_, err := dbpr.db.ExecContext(ctx, `DELETE FROM payments`)
+	if err != nil {
+		return dbError(ctx, "error executing delete", err)
+	}
+
+	_, err = dbpr.db.ExecContext(ctx, `DELETE FROM payment_versions`)
 	if err != nil {
-		return fmt.Errorf("db: error executing delete: %v", err)
+		return dbError(ctx, "error executing delete", err)
 	}
 
 	return nil
 }
 
-// Get returns the payment resource associated with the given paymentID
-//
-// Get returns an error if the paymentID does not exist in the collection
-func (dbpr *DBPaymentRepository) Get(paymentID strfmt.UUID) (*models.Payment, error) {
-	selectStmt := `
-	SELECT
+// paymentColumns is the list of columns that make up a payment, in the order
+// expected by scanPayment
+const paymentColumns = `
 		id,
 		organisation,
 		version,
@@ -396,10 +588,16 @@ func (dbpr *DBPaymentRepository) Get(paymentID strfmt.UUID) (*models.Payment, er
 		scheme_payment_type,
 		(sponsor_party).account_number,
 		(sponsor_party).bank_id,
-		(sponsor_party).bank_id_code
-	FROM payments
-	WHERE id = $1`
+		(sponsor_party).bank_id_code`
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPayment reads a payment from a row that starts with paymentColumns.
+// Destinations for any columns selected after those can be passed in extra
+func scanPayment(row rowScanner, extra ...interface{}) (*models.Payment, error) {
 	payment := models.Payment{
 		ID:             new(strfmt.UUID),
 		OrganisationID: new(strfmt.UUID),
@@ -415,8 +613,7 @@ func (dbpr *DBPaymentRepository) Get(paymentID strfmt.UUID) (*models.Payment, er
 	}
 	var amounts []amount
 
-	row := dbpr.db.QueryRow(selectStmt, paymentID.String())
-	err := row.Scan(
+	dest := []interface{}{
 		payment.ID,                                        // id,
 		payment.OrganisationID,                            // organisation,
 		payment.Version,                                   // version,
@@ -459,239 +656,418 @@ func (dbpr *DBPaymentRepository) Get(paymentID strfmt.UUID) (*models.Payment, er
 		&attrs.SponsorParty.AccountNumber,                 // sponsor_party.account_number,
 		&attrs.SponsorParty.BankID,                        // sponsor_party.bank_id,
 		&attrs.SponsorParty.BankIDCode,                    // sponsor_party.bank_id_code
-	)
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
 
 	attrs.ChargesInformation.SenderCharges = amountsToSenderCharges(amounts)
 	payment.Attributes = &attrs
+	return &payment, nil
+}
+
+// Get returns the payment resource associated with the given paymentID
+//
+// Get returns an error if the paymentID does not exist in the collection
+func (dbpr *DBPaymentRepository) Get(ctx context.Context, paymentID strfmt.UUID) (*models.Payment, error) {
+	args := []interface{}{paymentID.String()}
+	conditions := append([]string{"id = $1"}, organisationConditions(ctx, &args)...)
+	selectStmt := `
+	SELECT` + paymentColumns + `
+	FROM payments` + whereClause(conditions)
 
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	row := dbpr.db.QueryRowContext(ctx, selectStmt, args...)
+	payment, err := scanPayment(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, newErrNoResults(fmt.Sprintf("db: payment with ID %s not found", paymentID))
 		}
 
-		return nil, fmt.Errorf("db: error executing select: %v", err)
+		return nil, dbError(ctx, "error executing select", err)
 	}
 
-	return &payment, nil
+	return payment, nil
 }
 
-// List returns a slice of payment resources. An empty slice will be returned
-// if no payment exists.
+// List returns a page of payment resources, sorted by the given fields and then by ID.
+//
+// Only payments that match the given filter are returned. A zero filter
+// matches every payment.
 //
-// List implements basic pagination by means of offset and limit parameters.
-// List will return an error if offset is beyond the number of elements available.
-// Limit must be between 1 and 100.
-func (dbpr *DBPaymentRepository) List(offset, limit int64) ([]*models.Payment, error) {
+// The page to return is selected either by offset or by the position of the
+// payments relative to a cursor, as set in pagination. Limit must be between 1 and 100.
+// List will return an error if the page contains no payments.
+func (dbpr *DBPaymentRepository) List(ctx context.Context, filter PaymentFilter, sortBy []SortField, pagination Pagination) (*PaymentPage, error) {
 	// Check params before anything else
+	limit := pagination.Limit
 	if limit <= 0 || limit > 100 {
 		return nil, newErrBadOffsetLimit(fmt.Sprintf("db: list limit %d is outside allowed range (0, 100]", limit))
 	}
 
-	if offset < 0 {
-		return nil, newErrBadOffsetLimit(fmt.Sprintf("db: list offset %d negative", offset))
+	if pagination.Offset < 0 {
+		return nil, newErrBadOffsetLimit(fmt.Sprintf("db: list offset %d negative", pagination.Offset))
 	}
 
-	listStmt := `
-	SELECT
-		id,
-		organisation,
-		version,
-		amount,
-		(beneficiary_party).name,
-		(beneficiary_party).number,
-		(beneficiary_party).number_code,
-		(beneficiary_party).type,
-		(beneficiary_party).address,
-		(beneficiary_party).bank_id,
-		(beneficiary_party).bank_id_code,
-		(beneficiary_party).client_name,
-		(charges_info).bearer_code,
-		(charges_info).receiver_charges.amount,
-		(charges_info).receiver_charges.currency,
-		(charges_info).sender_charges,
-		currency,
-		(debtor_party).name,
-		(debtor_party).number,
-		(debtor_party).number_code,
-		(debtor_party).type,
-		(debtor_party).address,
-		(debtor_party).bank_id,
-		(debtor_party).bank_id_code,
-		(debtor_party).client_name,
-		e2e_reference,
-		(fx).contract_ref,
-		(fx).rate,
-		(fx).original_amount.amount,
-		(fx).original_amount.currency,
-		numeric_reference,
-		payment_id,
-		payment_type,
-		processing_date,
-		purpose,
-		reference,
-		scheme,
-		scheme_payment_subtype,
-		scheme_payment_type,
-		(sponsor_party).account_number,
-		(sponsor_party).bank_id,
-		(sponsor_party).bank_id_code
-	FROM payments
-	ORDER BY id ASC
-	LIMIT $1
-	OFFSET $2`
+	if pagination.After != nil && pagination.Before != nil {
+		return nil, newErrBadOffsetLimit("db: list can't page both after and before a cursor")
+	}
+
+	for _, field := range sortBy {
+		if _, ok := sortColumns[field.Name]; !ok {
+			return nil, fmt.Errorf("db: unknown sort field %q", field.Name)
+		}
+	}
+
+	conditions, args := filterConditions(filter)
+	conditions = append(conditions, organisationConditions(ctx, &args)...)
+	backward := false
+	offset := pagination.Offset
+	switch {
+	case pagination.After != nil:
+		condition, err := keysetCondition(sortBy, pagination.After, false, &args)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		offset = 0
+
+	case pagination.Before != nil:
+		// Walk the list backwards from the cursor and reverse the results afterwards
+		condition, err := keysetCondition(sortBy, pagination.Before, true, &args)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		backward = true
+		offset = 0
+	}
 
-	rows, err := dbpr.db.Query(listStmt, limit, offset)
+	// Ask for one more payment than needed to know whether there are more
+	// payments beyond the page
+	listStmt := `
+	SELECT` + paymentColumns + `
+	FROM payments` + whereClause(conditions) + `
+	ORDER BY ` + orderByClause(sortBy, backward) + `
+	LIMIT $` + strconv.Itoa(len(args)+1) + `
+	OFFSET $` + strconv.Itoa(len(args)+2)
+	args = append(args, limit+1, offset)
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	rows, err := dbpr.db.QueryContext(ctx, listStmt, args...)
 	if err != nil {
-		return nil, fmt.Errorf("db: error executing list query: %v", err)
+		return nil, dbError(ctx, "error executing list query", err)
 	}
 	defer rows.Close()
 
-	payments := make([]*models.Payment, 0, limit)
+	payments := make([]*models.Payment, 0, limit+1)
 	for rows.Next() {
-		payment := models.Payment{
-			ID:             new(strfmt.UUID),
-			OrganisationID: new(strfmt.UUID),
-			Type:           TYPE_PAYMENT,
-			Version:        new(int64),
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, dbError(ctx, "error scanning row", err)
 		}
-		attrs := models.PaymentAttributes{
-			BeneficiaryParty:   &models.PaymentParty{},
-			ChargesInformation: &models.ChargesInformation{},
-			DebtorParty:        &models.PaymentParty{},
-			Fx:                 &models.PaymentAttributesFx{},
-			SponsorParty:       &models.PaymentAttributesSponsorParty{},
+
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, "error scanning rows", err)
+	}
+
+	if len(payments) == 0 {
+		return nil, newErrNoResults(fmt.Sprintf("db: no results with offset %d and limit %d", pagination.Offset, limit))
+	}
+
+	return newPaymentPage(payments, pagination), nil
+}
+
+// Count returns the number of payments that match the given filter.
+// A zero filter matches every payment
+func (dbpr *DBPaymentRepository) Count(ctx context.Context, filter PaymentFilter) (int64, error) {
+	conditions, args := filterConditions(filter)
+	conditions = append(conditions, organisationConditions(ctx, &args)...)
+	countStmt := `
+	SELECT count(*)
+	FROM payments` + whereClause(conditions)
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	var count int64
+	if err := dbpr.db.QueryRowContext(ctx, countStmt, args...).Scan(&count); err != nil {
+		return 0, dbError(ctx, "error executing count query", err)
+	}
+
+	return count, nil
+}
+
+// whereClause joins conditions into a WHERE clause. An empty clause is
+// returned if there are no conditions
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return `
+	WHERE ` + strings.Join(conditions, " AND ")
+}
+
+// sortColumns maps the name of every field in sortableFields to the expression
+// used to sort payments by it. Text is compared byte by byte, regardless of the
+// collation of the database, to match the ordering of MemPaymentRepository.
+// Columns are never NULL for payments written through the repository
+var sortColumns = map[string]string{
+	"amount":          "amount",
+	"currency":        `currency COLLATE "C"`,
+	"id":              "id",
+	"organisation_id": "organisation",
+	"payment_scheme":  `scheme::text COLLATE "C"`,
+	"payment_type":    `payment_type::text COLLATE "C"`,
+	"processing_date": "processing_date",
+	"reference":       `reference COLLATE "C"`,
+	"version":         "version",
+}
+
+// orderByClause builds the list of expressions to sort payments by the given
+// fields and then by ID. The order of every field is reversed if backward is true
+func orderByClause(sortBy []SortField, backward bool) string {
+	keys := sortKeys(sortBy)
+	terms := make([]string, 0, len(keys))
+	for _, key := range keys {
+		order := "ASC"
+		if key.Descending != backward {
+			order = "DESC"
 		}
-		var amounts []amount
-
-		err := rows.Scan(
-			payment.ID,                                        // id,
-			payment.OrganisationID,                            // organisation,
-			payment.Version,                                   // version,
-			&attrs.Amount,                                     // amount,
-			&attrs.BeneficiaryParty.AccountName,               // beneficiary_party.name,
-			&attrs.BeneficiaryParty.AccountNumber,             // beneficiary_party.number,
-			&attrs.BeneficiaryParty.AccountNumberCode,         // beneficiary_party.number_code,
-			&attrs.BeneficiaryParty.AccountType,               // beneficiary_party.type,
-			&attrs.BeneficiaryParty.Address,                   // beneficiary_party.address ,
-			&attrs.BeneficiaryParty.BankID,                    // beneficiary_party.bank_id,
-			&attrs.BeneficiaryParty.BankIDCode,                // beneficiary_party.bank_id_code,
-			&attrs.BeneficiaryParty.Name,                      // beneficiary_party.client_name,
-			&attrs.ChargesInformation.BearerCode,              // charges_info.bearer_code,
-			&attrs.ChargesInformation.ReceiverChargesAmount,   // charges_info.receiver_charges.amount,
-			&attrs.ChargesInformation.ReceiverChargesCurrency, // charges_info.receiver_charges.currency,
-			pq.Array(&amounts),                                // charges_info.sender_charges,
-			&attrs.Currency,                                   // currency,
-			&attrs.DebtorParty.AccountName,                    // debtor_party.name,
-			&attrs.DebtorParty.AccountNumber,                  // debtor_party.number,
-			&attrs.DebtorParty.AccountNumberCode,              // debtor_party.number_code,
-			&attrs.DebtorParty.AccountType,                    // debtor_party.type,
-			&attrs.DebtorParty.Address,                        // debtor_party.address ,
-			&attrs.DebtorParty.BankID,                         // debtor_party.bank_id,
-			&attrs.DebtorParty.BankIDCode,                     // debtor_party.bank_id_code,
-			&attrs.DebtorParty.Name,                           // debtor_party.client_name,
-			&attrs.EndToEndReference,                          // e2e_reference,
-			&attrs.Fx.ContractReference,                       // fx.contract_ref,
-			&attrs.Fx.ExchangeRate,                            // fx.rate,
-			&attrs.Fx.OriginalAmount,                          // fx.original_amount.amount,
-			&attrs.Fx.OriginalCurrency,                        // fx.original_amount.currency,
-			&attrs.NumericReference,                           // numeric_reference,
-			&attrs.PaymentID,                                  // payment_id,
-			&attrs.PaymentType,                                // payment_type,
-			&attrs.ProcessingDate,                             // processing_date,
-			&attrs.PaymentPurpose,                             // purpose,
-			&attrs.Reference,                                  // reference,
-			&attrs.PaymentScheme,                              // scheme,
-			&attrs.SchemePaymentSubType,                       // scheme_payment_subtype,
-			&attrs.SchemePaymentType,                          // scheme_payment_type,
-			&attrs.SponsorParty.AccountNumber,                 // sponsor_party.account_number,
-			&attrs.SponsorParty.BankID,                        // sponsor_party.bank_id,
-			&attrs.SponsorParty.BankIDCode,                    // sponsor_party.bank_id_code
-		)
+		terms = append(terms, sortColumns[key.Name]+" "+order)
+	}
+
+	return strings.Join(terms, ", ")
+}
 
+// keysetCondition builds the condition that selects the payments that come right
+// after the cursor in a list sorted by the given fields, or right before it if
+// backward is true. Values of the cursor are appended to args, as the
+// condition uses placeholders for them.
+//
+// As fields can be sorted in different directions, a row comparison can't be used.
+// The condition is expanded instead, so that for fields a, b and ID it reads:
+//
+//	a > $1 OR (a = $1 AND b > $2) OR (a = $1 AND b = $2 AND id > $3)
+func keysetCondition(sortBy []SortField, cursor *Cursor, backward bool, args *[]interface{}) (string, error) {
+	if len(cursor.Values) != len(sortBy) {
+		return "", newErrBadOffsetLimit(fmt.Sprintf("db: cursor has %d values for %d sort fields", len(cursor.Values), len(sortBy)))
+	}
+
+	keys := sortKeys(sortBy)
+	values := cursorValues(sortBy, cursor)
+	placeholders := make([]string, 0, len(keys))
+	for _, value := range values {
+		*args = append(*args, value)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(*args)))
+	}
+
+	terms := make([]string, 0, len(keys))
+	for i, key := range keys {
+		op := ">"
+		if key.Descending != backward {
+			op = "<"
+		}
+
+		comparisons := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			comparisons = append(comparisons, sortColumns[keys[j].Name]+" = "+placeholders[j])
+		}
+		comparisons = append(comparisons, sortColumns[key.Name]+" "+op+" "+placeholders[i])
+
+		term := strings.Join(comparisons, " AND ")
+		if len(keys) > 1 {
+			term = "(" + term + ")"
+		}
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")", nil
+}
+
+// filterConditions builds the conditions that select the payments matching
+// filter, along with the arguments for their placeholders, which are numbered from 1.
+// No conditions are returned if the filter doesn't set any criteria
+func filterConditions(filter PaymentFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.OrganisationID != nil {
+		addCondition("organisation = $%d", *filter.OrganisationID)
+	}
+	if filter.Currency != nil {
+		addCondition("currency = $%d", string(*filter.Currency))
+	}
+	if filter.PaymentScheme != nil {
+		// Compare as text, so that unknown schemes select no payments instead of
+		// failing to be converted to the enum type
+		addCondition("scheme::text = $%d", *filter.PaymentScheme)
+	}
+	if filter.ProcessingDateFrom != nil {
+		addCondition("processing_date >= $%d", *filter.ProcessingDateFrom)
+	}
+	if filter.ProcessingDateTo != nil {
+		addCondition("processing_date <= $%d", *filter.ProcessingDateTo)
+	}
+	if filter.AmountFrom != nil {
+		addCondition("amount >= $%d", string(*filter.AmountFrom))
+	}
+	if filter.AmountTo != nil {
+		addCondition("amount <= $%d", string(*filter.AmountTo))
+	}
+
+	return conditions, args
+}
+
+// organisationConditions builds the conditions that restrict a query to the
+// payments of the organisations the caller can access. The organisations are
+// appended to args, as the condition uses a placeholder for them.
+// No conditions are returned if the caller is not restricted
+func organisationConditions(ctx context.Context, args *[]interface{}) []string {
+	orgs, scoped := callerOrganisations(ctx)
+	if !scoped {
+		return nil
+	}
+
+	ids := make([]string, len(orgs))
+	for i, org := range orgs {
+		ids[i] = org.String()
+	}
+	*args = append(*args, pq.Array(ids))
+	return []string{"organisation = ANY($" + strconv.Itoa(len(*args)) + "::uuid[])"}
+}
+
+// scanPaymentVersion reads a payment version from a row that contains
+// paymentColumns followed by the operation and recorded_at columns
+func scanPaymentVersion(row rowScanner) (*models.PaymentVersion, error) {
+	var operation string
+	var recordedAt time.Time
+	payment, err := scanPayment(row, &operation, &recordedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	version := *payment.Version
+	recorded := strfmt.DateTime(recordedAt)
+	return &models.PaymentVersion{
+		Data:       payment,
+		Operation:  &operation,
+		RecordedAt: &recorded,
+		Version:    &version,
+	}, nil
+}
+
+// ListVersions returns every version recorded for the payment with the given
+// paymentID, oldest first. Versions are recorded by the DB itself every time
+// a payment is added, updated or deleted. If a deleted payment is added again,
+// versions of both the old and the new payment are returned.
+//
+// ListVersions returns an error if there are no versions for the paymentID
+func (dbpr *DBPaymentRepository) ListVersions(ctx context.Context, paymentID strfmt.UUID) ([]*models.PaymentVersion, error) {
+	// Versions are only shown to the organisation the payment belonged to
+	// when they were recorded
+	args := []interface{}{paymentID.String()}
+	conditions := append([]string{"id = $1"}, organisationConditions(ctx, &args)...)
+	listStmt := `
+	SELECT` + paymentColumns + `,
+		operation,
+		recorded_at
+	FROM payment_versions` + whereClause(conditions) + `
+	ORDER BY seq ASC`
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	rows, err := dbpr.db.QueryContext(ctx, listStmt, args...)
+	if err != nil {
+		return nil, dbError(ctx, "error executing versions query", err)
+	}
+	defer rows.Close()
+
+	versions := []*models.PaymentVersion{}
+	for rows.Next() {
+		version, err := scanPaymentVersion(rows)
 		if err != nil {
-			return nil, fmt.Errorf("db: error scanning row: %v", err)
+			return nil, dbError(ctx, "error scanning row", err)
 		}
 
-		attrs.ChargesInformation.SenderCharges = amountsToSenderCharges(amounts)
-		payment.Attributes = &attrs
-		payments = append(payments, &payment)
+		versions = append(versions, version)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("db: error scanning rows: %v", err)
+		return nil, dbError(ctx, "error scanning rows", err)
 	}
 
-	if len(payments) == 0 {
-		return nil, newErrNoResults(fmt.Sprintf("db: no results with offset %d and limit %d", offset, limit))
+	if len(versions) == 0 {
+		return nil, newErrNoResults(fmt.Sprintf("db: no versions found for payment with ID %s", paymentID))
 	}
 
-	return payments, nil
+	return versions, nil
 }
 
-// Update updates the details associated with the given paymentID. The current
-// implementation is a basic one that doesn't support updating fields selectively.
+// GetVersion returns the given version of the payment with paymentID. If a
+// deleted payment has been added again, the version of the newest one is returned
 //
-// Update returns an error if the paymentID does not exist in the collection
-func (dbpr *DBPaymentRepository) Update(paymentID strfmt.UUID, payment *models.Payment) (*models.Payment, error) {
-	// Look for the ID in the DB to check if the payment exists and get its version
-	original, err := dbpr.Get(paymentID)
+// GetVersion returns an error if the version does not exist
+func (dbpr *DBPaymentRepository) GetVersion(ctx context.Context, paymentID strfmt.UUID, version int64) (*models.PaymentVersion, error) {
+	args := []interface{}{paymentID.String(), version}
+	conditions := append([]string{"id = $1", "version = $2"}, organisationConditions(ctx, &args)...)
+	selectStmt := `
+	SELECT` + paymentColumns + `,
+		operation,
+		recorded_at
+	FROM payment_versions` + whereClause(conditions) + `
+	ORDER BY seq DESC
+	LIMIT 1`
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	row := dbpr.db.QueryRowContext(ctx, selectStmt, args...)
+	paymentVersion, err := scanPaymentVersion(row)
 	if err != nil {
-		return nil, err
+		if err == sql.ErrNoRows {
+			return nil, newErrNoResults(fmt.Sprintf("db: version %d of payment with ID %s not found", version, paymentID))
+		}
+
+		return nil, dbError(ctx, "error executing select", err)
 	}
 
-	updateStmt := `
-	UPDATE payments
-	SET
-		organisation = $2,
-		version = $3,
-		amount = $4,
-		beneficiary_party.name = $5,
-		beneficiary_party.number = $6,
-		beneficiary_party.number_code = $7,
-		beneficiary_party.type = $8,
-		beneficiary_party.address = $9,
-		beneficiary_party.bank_id = $10,
-		beneficiary_party.bank_id_code = $11,
-		beneficiary_party.client_name = $12,
-		charges_info.bearer_code = $13,
-		charges_info.receiver_charges.amount = $14,
-		charges_info.receiver_charges.currency = $15,
-		charges_info.sender_charges = $16,
-		currency = $17,
-		debtor_party.name = $18,
-		debtor_party.number = $19,
-		debtor_party.number_code = $20,
-		debtor_party.type = $21,
-		debtor_party.address = $22,
-		debtor_party.bank_id = $23,
-		debtor_party.bank_id_code = $24,
-		debtor_party.client_name = $25,
-		e2e_reference = $26,
-		fx.contract_ref = $27,
-		fx.rate = $28,
-		fx.original_amount.amount = $29,
-		fx.original_amount.currency = $30,
-		numeric_reference = $31,
-		payment_id = $32,
-		payment_type = $33,
-		processing_date = $34,
-		purpose = $35,
-		reference = $36,
-		scheme = $37,
-		scheme_payment_subtype = $38,
-		scheme_payment_type = $39,
-		sponsor_party.account_number = $40,
-		sponsor_party.bank_id = $41,
-		sponsor_party.bank_id_code = $42
-	WHERE id = $1`
+	return paymentVersion, nil
+}
+
+// Update updates the details associated with the given paymentID. Every detail
+// is written, use Patch to write only the details that changed.
+//
+// If the version of the given payment is set, the update is only applied if it
+// matches the version stored in the DB. The check and the write happen in
+// the same statement, so concurrent updates based on the same version
+// can't overwrite each other.
+//
+// Update returns an error if the paymentID does not exist in the collection
+// or if the version of the given payment is stale
+func (dbpr *DBPaymentRepository) Update(ctx context.Context, paymentID strfmt.UUID, payment *models.Payment) (*models.Payment, error) {
+	if err := checkOrganisation(ctx, payment); err != nil {
+		return nil, err
+	}
 
-	version := *original.Version + 1
 	attrs := payment.Attributes
 	amounts := senderChargesToAmounts(attrs.ChargesInformation.SenderCharges)
-	_, err = dbpr.db.Exec(updateStmt,
+	args := []interface{}{
 		paymentID,                                        // id,
 		payment.OrganisationID,                           // organisation,
-		version,                                          // version,
+		payment.Version,                                  // expected version,
 		attrs.Amount,                                     // amount,
 		attrs.BeneficiaryParty.AccountName,               // beneficiary_party.name,
 		attrs.BeneficiaryParty.AccountNumber,             // beneficiary_party.number,
@@ -731,9 +1107,67 @@ func (dbpr *DBPaymentRepository) Update(paymentID strfmt.UUID, payment *models.P
 		attrs.SponsorParty.AccountNumber,                 // sponsor_party.account_number,
 		attrs.SponsorParty.BankID,                        // sponsor_party.bank_id,
 		attrs.SponsorParty.BankIDCode,                    // sponsor_party.bank_id_code
-	)
+	}
+	conditions := append([]string{"id = $1", "($3::bigint IS NULL OR version = $3)"}, organisationConditions(ctx, &args)...)
+
+	updateStmt := `
+	UPDATE payments
+	SET
+		organisation = $2,
+		version = version + 1,
+		amount = $4,
+		beneficiary_party.name = $5,
+		beneficiary_party.number = $6,
+		beneficiary_party.number_code = $7,
+		beneficiary_party.type = $8,
+		beneficiary_party.address = $9,
+		beneficiary_party.bank_id = $10,
+		beneficiary_party.bank_id_code = $11,
+		beneficiary_party.client_name = $12,
+		charges_info.bearer_code = $13,
+		charges_info.receiver_charges.amount = $14,
+		charges_info.receiver_charges.currency = $15,
+		charges_info.sender_charges = $16,
+		currency = $17,
+		debtor_party.name = $18,
+		debtor_party.number = $19,
+		debtor_party.number_code = $20,
+		debtor_party.type = $21,
+		debtor_party.address = $22,
+		debtor_party.bank_id = $23,
+		debtor_party.bank_id_code = $24,
+		debtor_party.client_name = $25,
+		e2e_reference = $26,
+		fx.contract_ref = $27,
+		fx.rate = $28,
+		fx.original_amount.amount = $29,
+		fx.original_amount.currency = $30,
+		numeric_reference = $31,
+		payment_id = $32,
+		payment_type = $33,
+		processing_date = $34,
+		purpose = $35,
+		reference = $36,
+		scheme = $37,
+		scheme_payment_subtype = $38,
+		scheme_payment_type = $39,
+		sponsor_party.account_number = $40,
+		sponsor_party.bank_id = $41,
+		sponsor_party.bank_id_code = $42` + whereClause(conditions) + `
+	RETURNING version`
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	var version int64
+	row := dbpr.db.QueryRowContext(ctx, updateStmt, args...)
+	err := row.Scan(&version)
+	if err == sql.ErrNoRows {
+		// Nothing was updated, either because the payment doesn't exist
+		// or because its version has changed
+		return nil, dbpr.updateMismatch(ctx, paymentID, payment.Version)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("db: error executing update: %v", err)
+		return nil, dbError(ctx, "error executing update", err)
 	}
 
 	updated := copyPayment(payment)
@@ -743,24 +1177,203 @@ func (dbpr *DBPaymentRepository) Update(paymentID strfmt.UUID, payment *models.P
 	return updated, nil
 }
 
+// patchColumn maps a column of the payments table that holds an attribute of
+// payments to the value it takes from the attributes
+type patchColumn struct {
+	name  string
+	value func(attrs *models.PaymentAttributes) interface{}
+}
+
+// patchColumns lists the attribute columns that Patch compares and writes,
+// in the same order as the statement in Update
+var patchColumns = []patchColumn{
+	{"amount", func(a *models.PaymentAttributes) interface{} { return a.Amount }},
+	{"beneficiary_party.name", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.AccountName }},
+	{"beneficiary_party.number", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.AccountNumber }},
+	{"beneficiary_party.number_code", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.AccountNumberCode }},
+	{"beneficiary_party.type", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.AccountType }},
+	{"beneficiary_party.address", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.Address }},
+	{"beneficiary_party.bank_id", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.BankID }},
+	{"beneficiary_party.bank_id_code", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.BankIDCode }},
+	{"beneficiary_party.client_name", func(a *models.PaymentAttributes) interface{} { return a.BeneficiaryParty.Name }},
+	{"charges_info.bearer_code", func(a *models.PaymentAttributes) interface{} { return a.ChargesInformation.BearerCode }},
+	{"charges_info.receiver_charges.amount", func(a *models.PaymentAttributes) interface{} {
+		return a.ChargesInformation.ReceiverChargesAmount
+	}},
+	{"charges_info.receiver_charges.currency", func(a *models.PaymentAttributes) interface{} {
+		return a.ChargesInformation.ReceiverChargesCurrency
+	}},
+	{"charges_info.sender_charges", func(a *models.PaymentAttributes) interface{} {
+		return pq.Array(senderChargesToAmounts(a.ChargesInformation.SenderCharges))
+	}},
+	{"currency", func(a *models.PaymentAttributes) interface{} { return a.Currency }},
+	{"debtor_party.name", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.AccountName }},
+	{"debtor_party.number", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.AccountNumber }},
+	{"debtor_party.number_code", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.AccountNumberCode }},
+	{"debtor_party.type", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.AccountType }},
+	{"debtor_party.address", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.Address }},
+	{"debtor_party.bank_id", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.BankID }},
+	{"debtor_party.bank_id_code", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.BankIDCode }},
+	{"debtor_party.client_name", func(a *models.PaymentAttributes) interface{} { return a.DebtorParty.Name }},
+	{"e2e_reference", func(a *models.PaymentAttributes) interface{} { return a.EndToEndReference }},
+	{"fx.contract_ref", func(a *models.PaymentAttributes) interface{} { return a.Fx.ContractReference }},
+	{"fx.rate", func(a *models.PaymentAttributes) interface{} { return a.Fx.ExchangeRate }},
+	{"fx.original_amount.amount", func(a *models.PaymentAttributes) interface{} { return a.Fx.OriginalAmount }},
+	{"fx.original_amount.currency", func(a *models.PaymentAttributes) interface{} { return a.Fx.OriginalCurrency }},
+	{"numeric_reference", func(a *models.PaymentAttributes) interface{} { return a.NumericReference }},
+	{"payment_id", func(a *models.PaymentAttributes) interface{} { return a.PaymentID }},
+	{"payment_type", func(a *models.PaymentAttributes) interface{} { return a.PaymentType }},
+	{"processing_date", func(a *models.PaymentAttributes) interface{} { return a.ProcessingDate }},
+	{"purpose", func(a *models.PaymentAttributes) interface{} { return a.PaymentPurpose }},
+	{"reference", func(a *models.PaymentAttributes) interface{} { return a.Reference }},
+	{"scheme", func(a *models.PaymentAttributes) interface{} { return a.PaymentScheme }},
+	{"scheme_payment_subtype", func(a *models.PaymentAttributes) interface{} { return a.SchemePaymentSubType }},
+	{"scheme_payment_type", func(a *models.PaymentAttributes) interface{} { return a.SchemePaymentType }},
+	{"sponsor_party.account_number", func(a *models.PaymentAttributes) interface{} { return a.SponsorParty.AccountNumber }},
+	{"sponsor_party.bank_id", func(a *models.PaymentAttributes) interface{} { return a.SponsorParty.BankID }},
+	{"sponsor_party.bank_id_code", func(a *models.PaymentAttributes) interface{} { return a.SponsorParty.BankIDCode }},
+}
+
+// Patch updates the details of the payment with the given paymentID that
+// differ between original and patched. Only the columns that hold those details
+// are written, so details changed concurrently by other patches are kept.
+// The patched payment, as stored after the update, is returned.
+//
+// If the version of patched is set, the patch is only applied if it matches the
+// version stored in the DB. The check and the write happen in the same statement.
+//
+// Patch returns an error if the paymentID does not exist in the collection
+// or if the version of the patched payment is stale
+func (dbpr *DBPaymentRepository) Patch(ctx context.Context, paymentID strfmt.UUID, original, patched *models.Payment) (*models.Payment, error) {
+	if err := checkOrganisationChange(ctx, original, patched); err != nil {
+		return nil, err
+	}
+
+	originalAttrs := fullAttributes(original)
+	patchedAttrs := fullAttributes(patched)
+	sets := []string{"version = version + 1"}
+	args := []interface{}{paymentID, patched.Version}
+	if !reflect.DeepEqual(original.OrganisationID, patched.OrganisationID) {
+		args = append(args, patched.OrganisationID)
+		sets = append(sets, fmt.Sprintf("organisation = $%d", len(args)))
+	}
+	for _, column := range patchColumns {
+		value := column.value(patchedAttrs)
+		if sameColumnValue(column.value(originalAttrs), value) {
+			continue
+		}
+
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column.name, len(args)))
+	}
+	conditions := append([]string{"id = $1", "($2::bigint IS NULL OR version = $2)"}, organisationConditions(ctx, &args)...)
+
+	updateStmt := `
+	UPDATE payments
+	SET ` + strings.Join(sets, ", ") + whereClause(conditions) + `
+	RETURNING` + paymentColumns
+
+	ctx, cancel := dbpr.withTimeout(ctx)
+	defer cancel()
+	row := dbpr.db.QueryRowContext(ctx, updateStmt, args...)
+	updated, err := scanPayment(row)
+	if err == sql.ErrNoRows {
+		// Nothing was updated, either because the payment doesn't exist
+		// or because its version has changed
+		return nil, dbpr.updateMismatch(ctx, paymentID, patched.Version)
+	}
+	if err != nil {
+		return nil, dbError(ctx, "error executing update", err)
+	}
+
+	return updated, nil
+}
+
+// fullAttributes returns a copy of the attributes of payment in which every
+// nested object is set, so that columns can be read from it without checking
+// for nil values. Missing objects are replaced by empty ones
+func fullAttributes(payment *models.Payment) *models.PaymentAttributes {
+	attrs := *paymentAttributes(payment)
+	if attrs.BeneficiaryParty == nil {
+		attrs.BeneficiaryParty = &models.PaymentParty{}
+	}
+	if attrs.ChargesInformation == nil {
+		attrs.ChargesInformation = &models.ChargesInformation{}
+	}
+	if attrs.DebtorParty == nil {
+		attrs.DebtorParty = &models.PaymentParty{}
+	}
+	if attrs.Fx == nil {
+		attrs.Fx = &models.PaymentAttributesFx{}
+	}
+	if attrs.SponsorParty == nil {
+		attrs.SponsorParty = &models.PaymentAttributesSponsorParty{}
+	}
+
+	return &attrs
+}
+
+// sameColumnValue reports whether two values of a column are the same. Dates
+// are compared by their string representation, as they may have been read
+// with different locations
+func sameColumnValue(a, b interface{}) bool {
+	if dateA, ok := a.(strfmt.Date); ok {
+		dateB, ok := b.(strfmt.Date)
+		return ok && dateA.String() == dateB.String()
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// updateMismatch finds out why an update didn't affect any row and returns
+// the appropriate error
+func (dbpr *DBPaymentRepository) updateMismatch(ctx context.Context, paymentID strfmt.UUID, wantVersion *int64) error {
+	var current int64
+	args := []interface{}{paymentID.String()}
+	conditions := append([]string{"id = $1"}, organisationConditions(ctx, &args)...)
+	row := dbpr.db.QueryRowContext(ctx, "SELECT version FROM payments"+whereClause(conditions), args...)
+	err := row.Scan(&current)
+	if err == sql.ErrNoRows {
+		return newErrNoResults(fmt.Sprintf("db: payment with ID %s not found", paymentID))
+	}
+	if err != nil {
+		return dbError(ctx, "error checking version", err)
+	}
+
+	if wantVersion == nil {
+		// The payment exists and the update was unconditional, so it must have
+		// been a concurrent delete followed by an insert with the same ID
+		return fmt.Errorf("db: payment with ID %s could not be updated", paymentID)
+	}
+
+	return newErrVersionMismatch(fmt.Sprintf("db: payment with ID %s is at version %d, not %d",
+		paymentID, current, *wantVersion))
+}
+
+// registerCopiers guards the registration of custom copiers in copystructure
+var registerCopiers sync.Once
+
 // copyPayment performs a deep copy of a models.Payment structure
 func copyPayment(payment *models.Payment) *models.Payment {
 	// Configuration for copystructure package to correctly copy strfmt.Date
 	// Copy operation on this type fails if a custom copier function is not provided
 	// because of the strfmt.RFC3339FullDate custom format.
-	// This only needs to be done once
-	if _, ok := copystructure.Copiers[reflect.TypeOf(strfmt.Date{})]; !ok {
-		dateCopier := func(d interface{}) (interface{}, error) {
-			date, ok := d.(strfmt.Date)
-			if !ok {
-				return nil, fmt.Errorf("Wrong type: %T", d)
+	// This only needs to be done once, and it must be done only once, as
+	// copyPayment can be called concurrently and Copiers is a plain map
+	registerCopiers.Do(func() {
+		if _, ok := copystructure.Copiers[reflect.TypeOf(strfmt.Date{})]; !ok {
+			dateCopier := func(d interface{}) (interface{}, error) {
+				date, ok := d.(strfmt.Date)
+				if !ok {
+					return nil, fmt.Errorf("Wrong type: %T", d)
+				}
+
+				dup := date.DeepCopy()
+				return *dup, nil
 			}
-
-			dup := date.DeepCopy()
-			return *dup, nil
+			copystructure.Copiers[reflect.TypeOf(strfmt.Date{})] = dateCopier
 		}
-		copystructure.Copiers[reflect.TypeOf(strfmt.Date{})] = dateCopier
-	}
+	})
 
 	dup, _ := copystructure.Copy(*payment)
 	paymentDup := dup.(models.Payment)
@@ -3,11 +3,13 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-openapi/strfmt"
@@ -15,6 +17,7 @@ import (
 	"github.com/lib/pq"
 
 	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi"
 )
 
 var dbColumns = []string{
@@ -68,7 +71,7 @@ func setupRepo() (*DBPaymentRepository, sqlmock.Sqlmock, error) {
 		return nil, mock, fmt.Errorf("Error creating DB mock: %v", err)
 	}
 
-	testRepo, err := NewDBPaymentRepository(db, "", "")
+	testRepo, err := NewDBPaymentRepository(db, &DBConfig{})
 	if err != nil {
 		return nil, mock, fmt.Errorf("Unable to create test DB repo: %v", err)
 	}
@@ -106,55 +109,70 @@ func generateDummyPayments(howMany int) []*models.Payment {
 	return payments
 }
 
+func paymentValues(payment *models.Payment) []driver.Value {
+	attrs := payment.Attributes
+	amounts := senderChargesToAmounts(attrs.ChargesInformation.SenderCharges)
+	return []driver.Value{
+		payment.ID,                                       // id,
+		payment.OrganisationID,                           // organisation,
+		*payment.Version,                                 // version,
+		attrs.Amount,                                     // amount,
+		attrs.BeneficiaryParty.AccountName,               // beneficiary_party.name,
+		attrs.BeneficiaryParty.AccountNumber,             // beneficiary_party.number,
+		attrs.BeneficiaryParty.AccountNumberCode,         // beneficiary_party.number_code,
+		attrs.BeneficiaryParty.AccountType,               // beneficiary_party.type,
+		attrs.BeneficiaryParty.Address,                   // beneficiary_party.address ,
+		attrs.BeneficiaryParty.BankID,                    // beneficiary_party.bank_id,
+		attrs.BeneficiaryParty.BankIDCode,                // beneficiary_party.bank_id_code,
+		attrs.BeneficiaryParty.Name,                      // beneficiary_party.client_name,
+		attrs.ChargesInformation.BearerCode,              // charges_info.bearer_code,
+		attrs.ChargesInformation.ReceiverChargesAmount,   // charges_info.receiver_charges.amount,
+		attrs.ChargesInformation.ReceiverChargesCurrency, // charges_info.receiver_charges.currency,
+		pq.Array(amounts),                                // charges_info.sender_charges,
+		attrs.Currency,                                   // currency,
+		attrs.DebtorParty.AccountName,                    // debtor_party.name,
+		attrs.DebtorParty.AccountNumber,                  // debtor_party.number,
+		attrs.DebtorParty.AccountNumberCode,              // debtor_party.number_code,
+		attrs.DebtorParty.AccountType,                    // debtor_party.type,
+		attrs.DebtorParty.Address,                        // debtor_party.address ,
+		attrs.DebtorParty.BankID,                         // debtor_party.bank_id,
+		attrs.DebtorParty.BankIDCode,                     // debtor_party.bank_id_code,
+		attrs.DebtorParty.Name,                           // debtor_party.client_name,
+		attrs.EndToEndReference,                          // e2e_reference,
+		attrs.Fx.ContractReference,                       // fx.contract_ref,
+		attrs.Fx.ExchangeRate,                            // fx.rate,
+		attrs.Fx.OriginalAmount,                          // fx.original_amount.amount,
+		attrs.Fx.OriginalCurrency,                        // fx.original_amount.currency,
+		attrs.NumericReference,                           // numeric_reference,
+		attrs.PaymentID,                                  // payment_id,
+		attrs.PaymentType,                                // payment_type,
+		attrs.ProcessingDate,                             // processing_date,
+		attrs.PaymentPurpose,                             // purpose,
+		attrs.Reference,                                  // reference,
+		attrs.PaymentScheme,                              // scheme,
+		attrs.SchemePaymentSubType,                       // scheme_payment_subtype,
+		attrs.SchemePaymentType,                          // scheme_payment_type,
+		attrs.SponsorParty.AccountNumber,                 // sponsor_party.account_number,
+		attrs.SponsorParty.BankID,                        // sponsor_party.bank_id,
+		attrs.SponsorParty.BankIDCode,                    // sponsor_party.bank_id_code
+	}
+}
+
 func paymentsToRows(payments []*models.Payment) *sqlmock.Rows {
 	rows := sqlmock.NewRows(dbColumns)
 	for _, payment := range payments {
-		attrs := payment.Attributes
-		amounts := senderChargesToAmounts(attrs.ChargesInformation.SenderCharges)
-		rows.AddRow(
-			payment.ID,                                       // id,
-			payment.OrganisationID,                           // organisation,
-			*payment.Version,                                 // version,
-			attrs.Amount,                                     // amount,
-			attrs.BeneficiaryParty.AccountName,               // beneficiary_party.name,
-			attrs.BeneficiaryParty.AccountNumber,             // beneficiary_party.number,
-			attrs.BeneficiaryParty.AccountNumberCode,         // beneficiary_party.number_code,
-			attrs.BeneficiaryParty.AccountType,               // beneficiary_party.type,
-			attrs.BeneficiaryParty.Address,                   // beneficiary_party.address ,
-			attrs.BeneficiaryParty.BankID,                    // beneficiary_party.bank_id,
-			attrs.BeneficiaryParty.BankIDCode,                // beneficiary_party.bank_id_code,
-			attrs.BeneficiaryParty.Name,                      // beneficiary_party.client_name,
-			attrs.ChargesInformation.BearerCode,              // charges_info.bearer_code,
-			attrs.ChargesInformation.ReceiverChargesAmount,   // charges_info.receiver_charges.amount,
-			attrs.ChargesInformation.ReceiverChargesCurrency, // charges_info.receiver_charges.currency,
-			pq.Array(amounts),                                // charges_info.sender_charges,
-			attrs.Currency,                                   // currency,
-			attrs.DebtorParty.AccountName,                    // debtor_party.name,
-			attrs.DebtorParty.AccountNumber,                  // debtor_party.number,
-			attrs.DebtorParty.AccountNumberCode,              // debtor_party.number_code,
-			attrs.DebtorParty.AccountType,                    // debtor_party.type,
-			attrs.DebtorParty.Address,                        // debtor_party.address ,
-			attrs.DebtorParty.BankID,                         // debtor_party.bank_id,
-			attrs.DebtorParty.BankIDCode,                     // debtor_party.bank_id_code,
-			attrs.DebtorParty.Name,                           // debtor_party.client_name,
-			attrs.EndToEndReference,                          // e2e_reference,
-			attrs.Fx.ContractReference,                       // fx.contract_ref,
-			attrs.Fx.ExchangeRate,                            // fx.rate,
-			attrs.Fx.OriginalAmount,                          // fx.original_amount.amount,
-			attrs.Fx.OriginalCurrency,                        // fx.original_amount.currency,
-			attrs.NumericReference,                           // numeric_reference,
-			attrs.PaymentID,                                  // payment_id,
-			attrs.PaymentType,                                // payment_type,
-			attrs.ProcessingDate,                             // processing_date,
-			attrs.PaymentPurpose,                             // purpose,
-			attrs.Reference,                                  // reference,
-			attrs.PaymentScheme,                              // scheme,
-			attrs.SchemePaymentSubType,                       // scheme_payment_subtype,
-			attrs.SchemePaymentType,                          // scheme_payment_type,
-			attrs.SponsorParty.AccountNumber,                 // sponsor_party.account_number,
-			attrs.SponsorParty.BankID,                        // sponsor_party.bank_id,
-			attrs.SponsorParty.BankIDCode,                    // sponsor_party.bank_id_code
-		)
+		rows.AddRow(paymentValues(payment)...)
+	}
+
+	return rows
+}
+
+func versionsToRows(versions []*models.PaymentVersion) *sqlmock.Rows {
+	rows := sqlmock.NewRows(append(dbColumns, "operation", "recorded_at"))
+	for _, version := range versions {
+		values := paymentValues(version.Data)
+		values = append(values, *version.Operation, time.Time(*version.RecordedAt))
+		rows.AddRow(values...)
 	}
 
 	return rows
@@ -172,7 +190,7 @@ func TestAdd(t *testing.T) {
 	testPayment := generateDummyPayments(1)[0]
 	// Modify the test payment to check that it gets the right type
 	testPayment.Type = TYPE_PAYMENT + "BAD"
-	added, err := testRepo.Add(testPayment)
+	added, err := testRepo.Add(context.Background(), testPayment)
 	if err != nil {
 		t.Errorf("Unexpected error adding payment: %v", err)
 	}
@@ -198,7 +216,7 @@ func TestAddConflict(t *testing.T) {
 		WillReturnError(&pq.Error{Code: pq.ErrorCode("23505")})
 
 	testPayment := generateDummyPayments(1)[0]
-	_, err = testRepo.Add(testPayment)
+	_, err = testRepo.Add(context.Background(), testPayment)
 	e, ok := err.(ErrConflict)
 	if err == nil || !ok {
 		t.Errorf("Expected ErrConflict but got %v", e)
@@ -209,6 +227,113 @@ func TestAddConflict(t *testing.T) {
 	}
 }
 
+func TestAddBatch(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	testPayments := generateDummyPayments(batchInsertRows + 1)
+	// The last payment is sent with its own statement
+	firstIDs := sqlmock.NewRows([]string{"id"})
+	for _, payment := range testPayments[:batchInsertRows] {
+		firstIDs.AddRow(payment.ID.String())
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^INSERT INTO payments (.+) ON CONFLICT \(id\) DO NOTHING`).WillReturnRows(firstIDs)
+	mock.ExpectQuery(`^INSERT INTO payments (.+) ON CONFLICT \(id\) DO NOTHING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testPayments[batchInsertRows].ID.String()))
+	mock.ExpectCommit()
+
+	added, err := testRepo.AddBatch(context.Background(), testPayments, true)
+	if err != nil {
+		t.Fatalf("Unexpected error adding payments: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+
+	if len(added) != len(testPayments) {
+		t.Fatalf("Wanted %d payments but got %d", len(testPayments), len(added))
+	}
+	for i, payment := range added {
+		if payment == nil || *payment.ID != *testPayments[i].ID {
+			t.Fatalf("Wanted payment %d to be added", i)
+		}
+		if payment.Type != TYPE_PAYMENT || *payment.Version != 0 {
+			t.Errorf("Wanted payment %d to have type %s and version 0", i, TYPE_PAYMENT)
+		}
+	}
+}
+
+func TestAddBatchConflict(t *testing.T) {
+	testPayments := generateDummyPayments(3)
+	// The second payment conflicts with an existing one
+	insertedIDs := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id"}).
+			AddRow(testPayments[0].ID.String()).
+			AddRow(testPayments[2].ID.String())
+	}
+
+	t.Run("atomic", func(t *testing.T) {
+		testRepo, mock, err := setupRepo()
+		if err != nil {
+			t.Fatal("Error setting up test repo")
+		}
+		defer testRepo.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO payments`).WillReturnRows(insertedIDs())
+		mock.ExpectRollback()
+
+		added, err := testRepo.AddBatch(context.Background(), testPayments, true)
+		e, ok := err.(ErrBatchConflict)
+		if err == nil || !ok {
+			t.Fatalf("Expected ErrBatchConflict but got %v", err)
+		}
+		if !reflect.DeepEqual(e.Indexes, []int{1}) {
+			t.Errorf("Wanted conflicts at [1] but got %v", e.Indexes)
+		}
+		if added != nil {
+			t.Errorf("Wanted no payments to be added but got %v", added)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Expectations were not met: %s", err)
+		}
+	})
+
+	t.Run("not atomic", func(t *testing.T) {
+		testRepo, mock, err := setupRepo()
+		if err != nil {
+			t.Fatal("Error setting up test repo")
+		}
+		defer testRepo.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^INSERT INTO payments`).WillReturnRows(insertedIDs())
+		mock.ExpectCommit()
+
+		added, err := testRepo.AddBatch(context.Background(), testPayments, false)
+		e, ok := err.(ErrBatchConflict)
+		if err == nil || !ok {
+			t.Fatalf("Expected ErrBatchConflict but got %v", err)
+		}
+		if !reflect.DeepEqual(e.Indexes, []int{1}) {
+			t.Errorf("Wanted conflicts at [1] but got %v", e.Indexes)
+		}
+		if len(added) != 3 || added[0] == nil || added[1] != nil || added[2] == nil {
+			t.Errorf("Wanted payments 0 and 2 to be added but got %v", added)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Expectations were not met: %s", err)
+		}
+	})
+}
+
 func TestDelete(t *testing.T) {
 	testRepo, mock, err := setupRepo()
 	if err != nil {
@@ -217,17 +342,23 @@ func TestDelete(t *testing.T) {
 	defer testRepo.Close()
 
 	testPayment := generateDummyPayments(1)[0]
-	mock.ExpectExec(`DELETE FROM payments WHERE id = \$1`).
+	rows := paymentsToRows([]*models.Payment{testPayment})
+	mock.ExpectQuery(`^DELETE FROM payments WHERE id = \$1 RETURNING (.+)$`).
 		WithArgs(*testPayment.ID).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+		WillReturnRows(rows)
 
-	if err := testRepo.Delete(*testPayment.ID); err != nil {
+	deleted, err := testRepo.Delete(context.Background(), *testPayment.ID)
+	if err != nil {
 		t.Errorf("Unexpected error deleting payment: %v", err)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Expectations were not met: %s", err)
 	}
+
+	if deleted == nil || *deleted.ID != *testPayment.ID {
+		t.Errorf("Wanted the deleted payment but got %v", deleted)
+	}
 }
 
 func TestDeleteNonExistent(t *testing.T) {
@@ -238,11 +369,11 @@ func TestDeleteNonExistent(t *testing.T) {
 	defer testRepo.Close()
 
 	testPayment := generateDummyPayments(1)[0]
-	mock.ExpectExec(`DELETE FROM payments WHERE id = \$1`).
+	mock.ExpectQuery(`^DELETE FROM payments WHERE id = \$1 RETURNING (.+)$`).
 		WithArgs(*testPayment.ID).
-		WillReturnResult(sqlmock.NewResult(0, 0))
+		WillReturnError(sql.ErrNoRows)
 
-	err = testRepo.Delete(*testPayment.ID)
+	_, err = testRepo.Delete(context.Background(), *testPayment.ID)
 	e, ok := err.(ErrNoResults)
 	if err == nil || !ok {
 		t.Errorf("Expected ErrNoResults but got %v", e)
@@ -266,7 +397,7 @@ func TestGet(t *testing.T) {
 		WithArgs(*testPayment.ID).
 		WillReturnRows(rows)
 
-	got, err := testRepo.Get(*testPayment.ID)
+	got, err := testRepo.Get(context.Background(), *testPayment.ID)
 	if err != nil {
 		t.Errorf("Error getting payment: %v", err)
 	}
@@ -292,7 +423,7 @@ func TestGetNonExistent(t *testing.T) {
 		WithArgs(*testPayment.ID).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = testRepo.Get(*testPayment.ID)
+	_, err = testRepo.Get(context.Background(), *testPayment.ID)
 	e, ok := err.(ErrNoResults)
 	if err == nil || !ok {
 		t.Errorf("Expected ErrNoResults but got %v", e)
@@ -310,21 +441,29 @@ func TestList(t *testing.T) {
 		offset      int64
 		limit       int64
 		expectedLen int
+		wantNext    bool
+		wantPrev    bool
 	}{
 		"first 5": {
 			offset:      0,
 			limit:       5,
 			expectedLen: 5,
+			wantNext:    true,
+			wantPrev:    false,
 		},
 		"from 25 to 32": {
 			offset:      25,
 			limit:       6,
 			expectedLen: 6,
+			wantNext:    true,
+			wantPrev:    true,
 		},
 		"less than limit available": {
 			offset:      int64(len(testPayments) - 10),
 			limit:       20,
 			expectedLen: 10,
+			wantNext:    false,
+			wantPrev:    true,
 		},
 	}
 
@@ -336,23 +475,29 @@ func TestList(t *testing.T) {
 			}
 			defer testRepo.Close()
 
+			// One more payment than the limit is requested to know if there are more
 			from := tc.offset
-			to := tc.offset + tc.limit
+			to := tc.offset + tc.limit + 1
 			if to > int64(len(testPayments)) {
 				to = int64(len(testPayments))
 			}
 			rows := paymentsToRows(testPayments[from:to])
 			mock.ExpectQuery(`^SELECT (.+) FROM payments ORDER BY id ASC LIMIT \$1 OFFSET \$2$`).
-				WithArgs(tc.limit, tc.offset).
+				WithArgs(tc.limit+1, tc.offset).
 				WillReturnRows(rows)
 
-			payments, err := testRepo.List(tc.offset, tc.limit)
+			pagination := Pagination{Offset: tc.offset, Limit: tc.limit}
+			page, err := testRepo.List(context.Background(), PaymentFilter{}, nil, pagination)
 			if err != nil {
 				t.Fatalf("Unexpected error: %#v", err)
 			}
 
-			if len(payments) != tc.expectedLen {
-				t.Errorf("Want %d items but got %d", tc.expectedLen, len(payments))
+			if len(page.Payments) != tc.expectedLen {
+				t.Errorf("Want %d items but got %d", tc.expectedLen, len(page.Payments))
+			}
+
+			if page.HasNext != tc.wantNext || page.HasPrev != tc.wantPrev {
+				t.Errorf("Want next %t and prev %t but got %t and %t", tc.wantNext, tc.wantPrev, page.HasNext, page.HasPrev)
 			}
 
 			if err := mock.ExpectationsWereMet(); err != nil {
@@ -362,30 +507,270 @@ func TestList(t *testing.T) {
 	}
 }
 
-func TestListBadParams(t *testing.T) {
+func TestListFiltered(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	orgID := strfmt.UUID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb")
+	currency := models.Currency("GBP")
+	scheme := "FPS"
+	from := strfmt.Date(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	to := strfmt.Date(time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC))
+	minAmount := models.Amount("10.00")
+	maxAmount := models.Amount("99.99")
+	filter := PaymentFilter{
+		OrganisationID:     &orgID,
+		Currency:           &currency,
+		PaymentScheme:      &scheme,
+		ProcessingDateFrom: &from,
+		ProcessingDateTo:   &to,
+		AmountFrom:         &minAmount,
+		AmountTo:           &maxAmount,
+	}
+
+	offset := int64(0)
+	limit := int64(10)
+	testPayments := generateDummyPayments(3)
+	mock.ExpectQuery(`^SELECT (.+) FROM payments ` +
+		`WHERE organisation = \$1 AND currency = \$2 AND scheme::text = \$3 ` +
+		`AND processing_date >= \$4 AND processing_date <= \$5 AND amount >= \$6 AND amount <= \$7 ` +
+		`ORDER BY id ASC LIMIT \$8 OFFSET \$9$`).
+		WithArgs(orgID, "GBP", scheme, from, to, "10.00", "99.99", limit+1, offset).
+		WillReturnRows(paymentsToRows(testPayments))
+
+	page, err := testRepo.List(context.Background(), filter, nil, Pagination{Offset: offset, Limit: limit})
+	if err != nil {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	if len(page.Payments) != len(testPayments) {
+		t.Errorf("Want %d items but got %d", len(testPayments), len(page.Payments))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestListCursor(t *testing.T) {
+	testPayments := generateDummyPayments(10)
+	cursor := &Cursor{ID: *testPayments[0].ID}
+	limit := int64(3)
+
+	tests := map[string]struct {
+		pagination Pagination
+		query      string
+		rows       []*models.Payment
+		wantIDs    []*strfmt.UUID
+		wantNext   bool
+		wantPrev   bool
+	}{
+		"after with more": {
+			// Offset must be ignored when a cursor is set
+			pagination: Pagination{Offset: 5, Limit: limit, After: cursor},
+			query:      `^SELECT (.+) FROM payments WHERE id > \$1 ORDER BY id ASC LIMIT \$2 OFFSET \$3$`,
+			rows:       testPayments[1:5],
+			wantIDs:    []*strfmt.UUID{testPayments[1].ID, testPayments[2].ID, testPayments[3].ID},
+			wantNext:   true,
+			wantPrev:   true,
+		},
+		"after last page": {
+			pagination: Pagination{Limit: limit, After: cursor},
+			query:      `^SELECT (.+) FROM payments WHERE id > \$1 ORDER BY id ASC LIMIT \$2 OFFSET \$3$`,
+			rows:       testPayments[1:3],
+			wantIDs:    []*strfmt.UUID{testPayments[1].ID, testPayments[2].ID},
+			wantNext:   false,
+			wantPrev:   true,
+		},
+		"before with more": {
+			pagination: Pagination{Limit: limit, Before: cursor},
+			query:      `^SELECT (.+) FROM payments WHERE id < \$1 ORDER BY id DESC LIMIT \$2 OFFSET \$3$`,
+			rows:       []*models.Payment{testPayments[4], testPayments[3], testPayments[2], testPayments[1]},
+			wantIDs:    []*strfmt.UUID{testPayments[2].ID, testPayments[3].ID, testPayments[4].ID},
+			wantNext:   true,
+			wantPrev:   true,
+		},
+		"before first page": {
+			pagination: Pagination{Limit: limit, Before: cursor},
+			query:      `^SELECT (.+) FROM payments WHERE id < \$1 ORDER BY id DESC LIMIT \$2 OFFSET \$3$`,
+			rows:       []*models.Payment{testPayments[2], testPayments[1]},
+			wantIDs:    []*strfmt.UUID{testPayments[1].ID, testPayments[2].ID},
+			wantNext:   true,
+			wantPrev:   false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testRepo, mock, err := setupRepo()
+			if err != nil {
+				t.Fatal("Error setting up test repo")
+			}
+			defer testRepo.Close()
+
+			mock.ExpectQuery(tc.query).
+				WithArgs(cursor.ID, limit+1, 0).
+				WillReturnRows(paymentsToRows(tc.rows))
+
+			page, err := testRepo.List(context.Background(), PaymentFilter{}, nil, tc.pagination)
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+
+			if len(page.Payments) != len(tc.wantIDs) {
+				t.Fatalf("Want %d items but got %d", len(tc.wantIDs), len(page.Payments))
+			}
+
+			for i, payment := range page.Payments {
+				if *payment.ID != *tc.wantIDs[i] {
+					t.Errorf("Want payment %s at position %d but got %s", *tc.wantIDs[i], i, *payment.ID)
+				}
+			}
+
+			if page.HasNext != tc.wantNext || page.HasPrev != tc.wantPrev {
+				t.Errorf("Want next %t and prev %t but got %t and %t", tc.wantNext, tc.wantPrev, page.HasNext, page.HasPrev)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Expectations were not met: %s", err)
+			}
+		})
+	}
+}
+
+func TestListSorted(t *testing.T) {
+	testPayments := generateDummyPayments(3)
+	sortBy := []SortField{{Name: "processing_date", Descending: true}, {Name: "amount"}}
+	cursor := &Cursor{ID: *testPayments[0].ID, Values: []string{"2019-03-01", "10.00"}}
+	limit := int64(5)
+
 	tests := map[string]struct {
-		offset int64
-		limit  int64
+		pagination Pagination
+		query      string
+		args       []driver.Value
 	}{
+		"offset": {
+			pagination: Pagination{Offset: 5, Limit: limit},
+			query:      `^SELECT (.+) FROM payments ORDER BY processing_date DESC, amount ASC, id ASC LIMIT \$1 OFFSET \$2$`,
+			args:       []driver.Value{limit + 1, 5},
+		},
+		"after": {
+			pagination: Pagination{Limit: limit, After: cursor},
+			query: `^SELECT (.+) FROM payments ` +
+				`WHERE \(\(processing_date < \$1\) OR \(processing_date = \$1 AND amount > \$2\) OR \(processing_date = \$1 AND amount = \$2 AND id > \$3\)\) ` +
+				`ORDER BY processing_date DESC, amount ASC, id ASC LIMIT \$4 OFFSET \$5$`,
+			args: []driver.Value{"2019-03-01", "10.00", cursor.ID.String(), limit + 1, 0},
+		},
+		"before": {
+			pagination: Pagination{Limit: limit, Before: cursor},
+			query: `^SELECT (.+) FROM payments ` +
+				`WHERE \(\(processing_date > \$1\) OR \(processing_date = \$1 AND amount < \$2\) OR \(processing_date = \$1 AND amount = \$2 AND id < \$3\)\) ` +
+				`ORDER BY processing_date ASC, amount DESC, id DESC LIMIT \$4 OFFSET \$5$`,
+			args: []driver.Value{"2019-03-01", "10.00", cursor.ID.String(), limit + 1, 0},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testRepo, mock, err := setupRepo()
+			if err != nil {
+				t.Fatal("Error setting up test repo")
+			}
+			defer testRepo.Close()
+
+			mock.ExpectQuery(tc.query).
+				WithArgs(tc.args...).
+				WillReturnRows(paymentsToRows(testPayments))
+
+			page, err := testRepo.List(context.Background(), PaymentFilter{}, sortBy, tc.pagination)
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+
+			if len(page.Payments) != len(testPayments) {
+				t.Errorf("Want %d items but got %d", len(testPayments), len(page.Payments))
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Expectations were not met: %s", err)
+			}
+		})
+	}
+}
+
+func TestListBadSort(t *testing.T) {
+	testRepo, _, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	sortBy := []SortField{{Name: "beneficiary_party"}}
+	if _, err := testRepo.List(context.Background(), PaymentFilter{}, sortBy, Pagination{Limit: 10}); err == nil {
+		t.Error("Test should've failed but no error was produced")
+	}
+}
+
+func TestCount(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	currency := models.Currency("GBP")
+	mock.ExpectQuery(`^SELECT count\(\*\) FROM payments WHERE currency = \$1$`).
+		WithArgs("GBP").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	count, err := testRepo.Count(context.Background(), PaymentFilter{Currency: &currency})
+	if err != nil {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	if count != 42 {
+		t.Errorf("Want count 42 but got %d", count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestListBadParams(t *testing.T) {
+	cursor := &Cursor{ID: *generateDummyPayments(1)[0].ID}
+	tests := map[string]Pagination{
 		"offset negative": {
-			offset: -1,
-			limit:  5,
+			Offset: -1,
+			Limit:  5,
 		},
 		"limit 0": {
-			offset: 0,
-			limit:  0,
+			Offset: 0,
+			Limit:  0,
 		},
 		"limit negative": {
-			offset: 0,
-			limit:  -1,
+			Offset: 0,
+			Limit:  -1,
 		},
 		"limit too high": {
-			offset: 0,
-			limit:  101,
+			Offset: 0,
+			Limit:  101,
+		},
+		"both cursors": {
+			Limit:  5,
+			After:  cursor,
+			Before: cursor,
+		},
+		"cursor for other sort": {
+			Limit: 5,
+			After: &Cursor{ID: cursor.ID, Values: []string{"GBP"}},
 		},
 	}
 
-	for name, tc := range tests {
+	for name, pagination := range tests {
 		t.Run(name, func(t *testing.T) {
 			testRepo, _, err := setupRepo()
 			if err != nil {
@@ -393,7 +778,7 @@ func TestListBadParams(t *testing.T) {
 			}
 			defer testRepo.Close()
 
-			_, err = testRepo.List(tc.offset, tc.limit)
+			_, err = testRepo.List(context.Background(), PaymentFilter{}, nil, pagination)
 			if err == nil {
 				t.Fatal("Test should've failed but no error was produced")
 			}
@@ -415,10 +800,10 @@ func TestListNoResults(t *testing.T) {
 	offset := int64(0)
 	limit := int64(10)
 	mock.ExpectQuery(`^SELECT (.+) FROM payments ORDER BY id ASC LIMIT \$1 OFFSET \$2$`).
-		WithArgs(limit, offset).
+		WithArgs(limit+1, offset).
 		WillReturnRows(paymentsToRows([]*models.Payment{}))
 
-	_, err = testRepo.List(offset, limit)
+	_, err = testRepo.List(context.Background(), PaymentFilter{}, nil, Pagination{Offset: offset, Limit: limit})
 	if err == nil {
 		t.Error("Test should've failed but no error was produced")
 	} else if _, ok := err.(ErrNoResults); !ok {
@@ -440,20 +825,17 @@ func TestUpdate(t *testing.T) {
 	testPayment := generateDummyPayments(1)[0]
 	// Modify the test payment to check that it gets the right type
 	testPayment.Type = TYPE_PAYMENT + "BAD"
-	rows := paymentsToRows([]*models.Payment{testPayment})
-	mock.ExpectQuery(`^SELECT (.+) FROM payments WHERE id = \$1$`).
-		WithArgs(*testPayment.ID).
-		WillReturnRows(rows)
 
 	args := make([]driver.Value, 42)
 	for i := range args {
 		args[i] = sqlmock.AnyArg()
 	}
-	mock.ExpectExec(`^UPDATE payments SET (.+) WHERE id = \$1$`).
+	rows := sqlmock.NewRows([]string{"version"}).AddRow(*testPayment.Version + 1)
+	mock.ExpectQuery(`^UPDATE payments SET (.+) WHERE id = \$1 AND (.+) RETURNING version$`).
 		WithArgs(args...).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+		WillReturnRows(rows)
 
-	updated, err := testRepo.Update(*testPayment.ID, testPayment)
+	updated, err := testRepo.Update(context.Background(), *testPayment.ID, testPayment)
 	if err != nil {
 		t.Fatalf("Unexpected error updating payment: %v", err)
 	}
@@ -480,11 +862,18 @@ func TestUpdateNonExistent(t *testing.T) {
 	defer testRepo.Close()
 
 	testPayment := generateDummyPayments(1)[0]
-	mock.ExpectQuery(`^SELECT (.+) FROM payments WHERE id = \$1$`).
+	args := make([]driver.Value, 42)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	mock.ExpectQuery(`^UPDATE payments SET (.+) RETURNING version$`).
+		WithArgs(args...).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`^SELECT version FROM payments WHERE id = \$1$`).
 		WithArgs(*testPayment.ID).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err = testRepo.Update(*testPayment.ID, testPayment)
+	_, err = testRepo.Update(context.Background(), *testPayment.ID, testPayment)
 	e, ok := err.(ErrNoResults)
 	if err == nil || !ok {
 		t.Errorf("Expected ErrNoResults but got %v", e)
@@ -495,6 +884,245 @@ func TestUpdateNonExistent(t *testing.T) {
 	}
 }
 
+func TestUpdateStaleVersion(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatalf("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	testPayment := generateDummyPayments(1)[0]
+	args := make([]driver.Value, 42)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[2] = *testPayment.Version
+	mock.ExpectQuery(`^UPDATE payments SET (.+) RETURNING version$`).
+		WithArgs(args...).
+		WillReturnError(sql.ErrNoRows)
+	rows := sqlmock.NewRows([]string{"version"}).AddRow(*testPayment.Version + 1)
+	mock.ExpectQuery(`^SELECT version FROM payments WHERE id = \$1$`).
+		WithArgs(*testPayment.ID).
+		WillReturnRows(rows)
+
+	_, err = testRepo.Update(context.Background(), *testPayment.ID, testPayment)
+	if _, ok := err.(ErrVersionMismatch); !ok {
+		t.Errorf("Expected ErrVersionMismatch but got %T (%v)", err, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatalf("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	original := generateDummyPayments(1)[0]
+	patched := copyPayment(original)
+	patched.Version = nil
+	patched.Attributes.Amount = "150.00"
+	patched.Attributes.Fx.ContractReference = "FX456"
+
+	stored := copyPayment(patched)
+	version := *original.Version + 1
+	stored.Version = &version
+	// Only the columns that changed must be written
+	mock.ExpectQuery(`^UPDATE payments ` +
+		`SET version = version \+ 1, amount = \$3, fx.contract_ref = \$4 ` +
+		`WHERE id = \$1 AND (.+) RETURNING (.+)$`).
+		WithArgs(*original.ID, nil, patched.Attributes.Amount, patched.Attributes.Fx.ContractReference).
+		WillReturnRows(paymentsToRows([]*models.Payment{stored}))
+
+	updated, err := testRepo.Patch(context.Background(), *original.ID, original, patched)
+	if err != nil {
+		t.Fatalf("Unexpected error patching payment: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+
+	if updated.Type != TYPE_PAYMENT {
+		t.Errorf("Wanted type to be %s but got %s", TYPE_PAYMENT, updated.Type)
+	}
+
+	if *updated.Version != version {
+		t.Errorf("Patched payment should have its version number incremented by one (want %d, got %d)",
+			version, *updated.Version)
+	}
+
+	if updated.Attributes.Amount != patched.Attributes.Amount {
+		t.Errorf("Wanted amount to be %s but got %s", patched.Attributes.Amount, updated.Attributes.Amount)
+	}
+}
+
+func TestPatchStaleVersion(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatalf("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	original := generateDummyPayments(1)[0]
+	patched := copyPayment(original)
+	patched.Attributes.Reference = "New reference"
+	mock.ExpectQuery(`^UPDATE payments SET version = version \+ 1, reference = \$3 (.+)$`).
+		WithArgs(*original.ID, *original.Version, patched.Attributes.Reference).
+		WillReturnError(sql.ErrNoRows)
+	rows := sqlmock.NewRows([]string{"version"}).AddRow(*original.Version + 1)
+	mock.ExpectQuery(`^SELECT version FROM payments WHERE id = \$1$`).
+		WithArgs(*original.ID).
+		WillReturnRows(rows)
+
+	_, err = testRepo.Patch(context.Background(), *original.ID, original, patched)
+	if _, ok := err.(ErrVersionMismatch); !ok {
+		t.Errorf("Expected ErrVersionMismatch but got %T (%v)", err, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func generateDummyVersions(payment *models.Payment, howMany int) []*models.PaymentVersion {
+	versions := []*models.PaymentVersion{}
+	for i := 0; i < howMany; i++ {
+		data := copyPayment(payment)
+		number := int64(i)
+		data.Version = &number
+		operation := models.PaymentVersionOperationUpdate
+		if i == 0 {
+			operation = models.PaymentVersionOperationCreate
+		}
+		recordedAt := strfmt.DateTime(time.Now())
+		versions = append(versions, &models.PaymentVersion{
+			Data:       data,
+			Operation:  &operation,
+			RecordedAt: &recordedAt,
+			Version:    &number,
+		})
+	}
+
+	return versions
+}
+
+func TestListVersions(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	testPayment := generateDummyPayments(1)[0]
+	testVersions := generateDummyVersions(testPayment, 3)
+	mock.ExpectQuery(`^SELECT (.+) FROM payment_versions WHERE id = \$1 ORDER BY seq ASC$`).
+		WithArgs(*testPayment.ID).
+		WillReturnRows(versionsToRows(testVersions))
+
+	versions, err := testRepo.ListVersions(context.Background(), *testPayment.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing versions: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+
+	if len(versions) != len(testVersions) {
+		t.Fatalf("Want %d versions but got %d", len(testVersions), len(versions))
+	}
+
+	for i, version := range versions {
+		if *version.Version != int64(i) || *version.Data.Version != int64(i) {
+			t.Errorf("Want version %d at position %d but got %d", i, i, *version.Version)
+		}
+
+		if *version.Operation != *testVersions[i].Operation {
+			t.Errorf("Want operation %s for version %d but got %s", *testVersions[i].Operation, i, *version.Operation)
+		}
+	}
+}
+
+func TestListVersionsNoResults(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	testPayment := generateDummyPayments(1)[0]
+	mock.ExpectQuery(`^SELECT (.+) FROM payment_versions WHERE id = \$1 ORDER BY seq ASC$`).
+		WithArgs(*testPayment.ID).
+		WillReturnRows(versionsToRows(nil))
+
+	_, err = testRepo.ListVersions(context.Background(), *testPayment.ID)
+	if _, ok := err.(ErrNoResults); !ok {
+		t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	testPayment := generateDummyPayments(1)[0]
+	testVersion := generateDummyVersions(testPayment, 3)[2]
+	mock.ExpectQuery(`^SELECT (.+) FROM payment_versions WHERE id = \$1 AND version = \$2 (.+)$`).
+		WithArgs(*testPayment.ID, *testVersion.Version).
+		WillReturnRows(versionsToRows([]*models.PaymentVersion{testVersion}))
+
+	got, err := testRepo.GetVersion(context.Background(), *testPayment.ID, *testVersion.Version)
+	if err != nil {
+		t.Fatalf("Unexpected error getting version: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+
+	if *got.Version != *testVersion.Version {
+		t.Errorf("Want version %d but got %d", *testVersion.Version, *got.Version)
+	}
+
+	if *got.Operation != models.PaymentVersionOperationUpdate {
+		t.Errorf("Want operation %s but got %s", models.PaymentVersionOperationUpdate, *got.Operation)
+	}
+}
+
+func TestGetVersionNonExistent(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	testPayment := generateDummyPayments(1)[0]
+	mock.ExpectQuery(`^SELECT (.+) FROM payment_versions WHERE id = \$1 AND version = \$2 (.+)$`).
+		WithArgs(*testPayment.ID, int64(5)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = testRepo.GetVersion(context.Background(), *testPayment.ID, 5)
+	if _, ok := err.(ErrNoResults); !ok {
+		t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
+
 func TestAmountScan(t *testing.T) {
 	tests := map[string]struct {
 		input      string
@@ -587,3 +1215,101 @@ func TestCopyPayment(t *testing.T) {
 		t.Error("Original and copied payments don't match")
 	}
 }
+
+func TestQueryTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating DB mock: %v", err)
+	}
+
+	testRepo, err := NewDBPaymentRepository(db, &DBConfig{QueryTimeout: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unable to create test DB repo: %v", err)
+	}
+	defer testRepo.Close()
+
+	testPayment := generateDummyPayments(1)[0]
+	mock.ExpectQuery(`^SELECT (.+) FROM payments WHERE id = \$1$`).
+		WithArgs(*testPayment.ID).
+		WillDelayFor(time.Second).
+		WillReturnRows(paymentsToRows([]*models.Payment{testPayment}))
+
+	start := time.Now()
+	_, err = testRepo.Get(context.Background(), *testPayment.ID)
+	if err == nil {
+		t.Fatal("Test should've failed but no error was produced")
+	}
+
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Query was not cancelled after timeout (took %v)", elapsed)
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	testPayment := generateDummyPayments(1)[0]
+	mock.ExpectQuery(`DELETE FROM payments WHERE id = \$1`).
+		WithArgs(*testPayment.ID).
+		WillDelayFor(time.Second).
+		WillReturnRows(paymentsToRows([]*models.Payment{testPayment}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if _, err := testRepo.Delete(ctx, *testPayment.ID); err == nil {
+		t.Fatal("Test should've failed but no error was produced")
+	}
+
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Query was not cancelled with the context (took %v)", elapsed)
+	}
+}
+
+func TestTenancy(t *testing.T) {
+	testRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatal("Error setting up test repo")
+	}
+	defer testRepo.Close()
+
+	ownOrg := strfmt.UUID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb")
+	otherOrg := strfmt.UUID("2f1f6f9e-6b0c-4a3e-9d55-5b1f4d4c3e2a")
+	principal := &Principal{Subject: "client", Organisations: []strfmt.UUID{ownOrg}}
+	ctx := context.WithValue(context.Background(), restapi.AuthKey, principal)
+	orgs := pq.Array([]string{ownOrg.String()})
+
+	testPayment := generateDummyPayments(1)[0]
+	mock.ExpectQuery(`^SELECT (.+) FROM payments WHERE id = \$1 AND organisation = ANY\(\$2::uuid\[\]\)$`).
+		WithArgs(*testPayment.ID, orgs).
+		WillReturnError(sql.ErrNoRows)
+	if _, err := testRepo.Get(ctx, *testPayment.ID); !isErrNoResults(err) {
+		t.Errorf("Expected ErrNoResults but got %v", err)
+	}
+
+	limit := int64(10)
+	mock.ExpectQuery(`^SELECT (.+) FROM payments WHERE organisation = ANY\(\$1::uuid\[\]\) ORDER BY id ASC LIMIT \$2 OFFSET \$3$`).
+		WithArgs(orgs, limit+1, 0).
+		WillReturnRows(paymentsToRows([]*models.Payment{testPayment}))
+	if _, err := testRepo.List(ctx, PaymentFilter{}, nil, Pagination{Limit: limit}); err != nil {
+		t.Errorf("Unexpected error listing payments: %v", err)
+	}
+
+	// Payments for other organisations are rejected without querying the DB
+	testPayment.OrganisationID = &otherOrg
+	if _, err := testRepo.Add(ctx, testPayment); !isErrForbidden(err) {
+		t.Errorf("Expected ErrForbidden but got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Expectations were not met: %s", err)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/volmedo/pAPI/pkg/tracing"
+)
+
+// tracedDB is a sql.DB that measures every statement it runs as a span of
+// the trace of the request that triggered it
+type tracedDB struct {
+	*sql.DB
+}
+
+// maxStatementLength is the length statements are truncated to in spans, as
+// batch inserts take a set of placeholders for each payment
+const maxStatementLength = 1024
+
+// startStatementSpan starts a span for running stmt, named after the kind of
+// statement. Statements are recorded with their placeholders, so the values
+// of their arguments are never recorded
+func startStatementSpan(ctx context.Context, stmt string) *tracing.Span {
+	// Don't bother formatting the statement if the request is not traced
+	if tracing.FromContext(ctx) == nil {
+		return nil
+	}
+
+	name := "SQL"
+	fields := strings.Fields(stmt)
+	if len(fields) > 0 {
+		name += " " + strings.ToUpper(fields[0])
+	}
+	_, span := tracing.StartSpan(ctx, name)
+
+	statement := strings.Join(fields, " ")
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength] + "..."
+	}
+
+	span.SetAttribute("db.system", "postgresql")
+	span.SetAttribute("db.statement", statement)
+	return span
+}
+
+// ExecContext runs a statement that returns no rows
+func (db *tracedDB) ExecContext(ctx context.Context, stmt string, args ...interface{}) (sql.Result, error) {
+	span := startStatementSpan(ctx, stmt)
+	defer span.End()
+
+	res, err := db.DB.ExecContext(ctx, stmt, args...)
+	span.SetError(err)
+	return res, err
+}
+
+// QueryContext runs a statement that returns rows. The span only measures
+// the time until the first rows are available
+func (db *tracedDB) QueryContext(ctx context.Context, stmt string, args ...interface{}) (*sql.Rows, error) {
+	span := startStatementSpan(ctx, stmt)
+	defer span.End()
+
+	rows, err := db.DB.QueryContext(ctx, stmt, args...)
+	span.SetError(err)
+	return rows, err
+}
+
+// QueryRowContext runs a statement that returns at most one row. Errors are
+// only known once the row is scanned, so they are not recorded in the span
+func (db *tracedDB) QueryRowContext(ctx context.Context, stmt string, args ...interface{}) *sql.Row {
+	span := startStatementSpan(ctx, stmt)
+	defer span.End()
+
+	return db.DB.QueryRowContext(ctx, stmt, args...)
+}
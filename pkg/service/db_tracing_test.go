@@ -0,0 +1,64 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/volmedo/pAPI/pkg/tracing"
+)
+
+// spanRecorder is an exporter that keeps the spans it gets
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*tracing.SpanData
+}
+
+func (sr *spanRecorder) ExportSpan(span *tracing.SpanData) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	sr.spans = append(sr.spans, span)
+	return nil
+}
+
+func TestTracedDB(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock connection: %v", err)
+	}
+	defer conn.Close()
+	db := &tracedDB{conn}
+
+	mock.ExpectExec("DELETE FROM payments").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Statements outside traced requests work as usual
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM payments"); err != nil {
+		t.Fatalf("Error executing statement: %v", err)
+	}
+
+	rec := &spanRecorder{}
+	tracer := tracing.NewTracer(tracing.Config{Exporter: rec, SampleRatio: 1})
+	ctx, root := tracer.StartSpan(context.Background(), "request", tracing.SpanContext{})
+
+	mock.ExpectExec("DELETE FROM payments").WithArgs("secret").WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := db.ExecContext(ctx, "DELETE  FROM payments\n\tWHERE id = $1", "secret"); err != nil {
+		t.Fatalf("Error executing statement: %v", err)
+	}
+	root.End()
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("want a span for the statement and the request but got %d", len(rec.spans))
+	}
+	span := rec.spans[0]
+	if span.Name != "SQL DELETE" || span.ParentSpanID != root.Context().SpanID.String() {
+		t.Errorf("want a SQL DELETE span as a child of the request but got %+v", span)
+	}
+	if span.Attributes["db.statement"] != "DELETE FROM payments WHERE id = $1" {
+		t.Errorf("want the statement without its arguments but got %v", span.Attributes["db.statement"])
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// IdempotencyStore keeps the responses sent to requests that carry an
+// idempotency key, so that they can be sent again when the requests are retried.
+// A key is reserved before its request is handled, so that concurrent retries
+// can't handle the request again while the first one is still running.
+// Records expire after a time to live set when the store is created.
+// Implementations must be safe for concurrent use
+type IdempotencyStore interface {
+	// Get returns the record saved for the given key, which is pending if
+	// its request is still being handled
+	//
+	// Get returns an error if there is no record for key or if it has expired
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+
+	// Reserve saves a new pending record, without a response, which will
+	// expire when the time to live of the store has passed
+	//
+	// Reserve returns an error if a record that has not expired yet already
+	// exists for the same key
+	Reserve(ctx context.Context, record *IdempotencyRecord) error
+
+	// Complete saves the response of a pending record, which will expire when
+	// the time to live of the store has passed from then on
+	//
+	// Complete returns an error if there is no pending record for the key
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+
+	// Release removes the pending record for the given key, so that the key
+	// can be used again. Records that have a response are kept
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyRecord is the response sent to a request that carried an
+// idempotency key
+type IdempotencyRecord struct {
+	// Key is the idempotency key sent by the client
+	Key string
+
+	// Fingerprint identifies the request the key was first used with. Retries
+	// must have the same fingerprint for the response to be sent again
+	Fingerprint string
+
+	// Pending is true while the request the key was first used with is
+	// being handled, in which case there is no response yet
+	Pending bool
+
+	// Response is the body of the response
+	Response []byte
+}
+
+// requestFingerprint returns a fingerprint that identifies a request by its body
+func requestFingerprint(body interface{}) (string, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/volmedo/pAPI/pkg/models"
+)
+
+// InstrumentedPaymentRepository is a PaymentRepository that records the latency
+// and the errors of the calls to another repository as Prometheus metrics,
+// labelled by the method called
+type InstrumentedPaymentRepository struct {
+	repo     PaymentRepository
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewInstrumentedPaymentRepository creates an InstrumentedPaymentRepository
+// that wraps repo and registers its metrics with reg
+func NewInstrumentedPaymentRepository(repo PaymentRepository, reg prometheus.Registerer) (*InstrumentedPaymentRepository, error) {
+	ipr := &InstrumentedPaymentRepository{
+		repo: repo,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "pAPI",
+			Subsystem: "repository",
+			Name:      "call_duration_seconds",
+			Help:      "The latency of the calls to the payment repository.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pAPI",
+			Subsystem: "repository",
+			Name:      "errors_total",
+			Help:      "The number of calls to the payment repository that returned an error, by kind of error.",
+		}, []string{"method", "kind"}),
+	}
+
+	for _, c := range []prometheus.Collector{ipr.duration, ipr.errors} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return ipr, nil
+}
+
+// observe records a call to method that started at start and returned the
+// error pointed to by err. ctx is the context the call was made with
+func (ipr *InstrumentedPaymentRepository) observe(ctx context.Context, method string, start time.Time, err *error) {
+	ipr.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		ipr.errors.WithLabelValues(method, repoErrorKind(ctx, *err)).Inc()
+	}
+}
+
+// repoErrorKind returns the kind of an error returned by a repository, which
+// tells expected errors, such as payments not being found, from failures
+func repoErrorKind(ctx context.Context, err error) string {
+	switch err.(type) {
+	case ErrNoResults:
+		return "not_found"
+	case ErrForbidden:
+		return "forbidden"
+	case ErrConflict, ErrBatchConflict:
+		return "conflict"
+	case ErrVersionMismatch:
+		return "version_mismatch"
+	case ErrBadOffsetLimit:
+		return "bad_request"
+	}
+
+	// Repositories wrap the errors of the contexts they run queries with, such
+	// as the timeout of the DB repository. The context of the call is checked
+	// too for those that don't
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled), ctx.Err() != nil:
+		return "canceled"
+	}
+
+	return "internal"
+}
+
+// Add adds a new payment resource to the repository
+func (ipr *InstrumentedPaymentRepository) Add(ctx context.Context, payment *models.Payment) (added *models.Payment, err error) {
+	defer ipr.observe(ctx, "Add", time.Now(), &err)
+	return ipr.repo.Add(ctx, payment)
+}
+
+// AddBatch adds several new payment resources to the repository at once
+func (ipr *InstrumentedPaymentRepository) AddBatch(ctx context.Context, payments []*models.Payment, atomic bool) (added []*models.Payment, err error) {
+	defer ipr.observe(ctx, "AddBatch", time.Now(), &err)
+	return ipr.repo.AddBatch(ctx, payments, atomic)
+}
+
+// Delete deletes the payment resource associated to the given paymentID
+func (ipr *InstrumentedPaymentRepository) Delete(ctx context.Context, paymentID strfmt.UUID) (deleted *models.Payment, err error) {
+	defer ipr.observe(ctx, "Delete", time.Now(), &err)
+	return ipr.repo.Delete(ctx, paymentID)
+}
+
+// Get returns the payment resource associated with the given paymentID
+func (ipr *InstrumentedPaymentRepository) Get(ctx context.Context, paymentID strfmt.UUID) (payment *models.Payment, err error) {
+	defer ipr.observe(ctx, "Get", time.Now(), &err)
+	return ipr.repo.Get(ctx, paymentID)
+}
+
+// List returns a page of payment resources, sorted by the given fields
+func (ipr *InstrumentedPaymentRepository) List(ctx context.Context, filter PaymentFilter, sortBy []SortField, pagination Pagination) (page *PaymentPage, err error) {
+	defer ipr.observe(ctx, "List", time.Now(), &err)
+	return ipr.repo.List(ctx, filter, sortBy, pagination)
+}
+
+// Count returns the number of payments that match the given filter
+func (ipr *InstrumentedPaymentRepository) Count(ctx context.Context, filter PaymentFilter) (count int64, err error) {
+	defer ipr.observe(ctx, "Count", time.Now(), &err)
+	return ipr.repo.Count(ctx, filter)
+}
+
+// ListVersions returns every version recorded for the payment with the given paymentID
+func (ipr *InstrumentedPaymentRepository) ListVersions(ctx context.Context, paymentID strfmt.UUID) (versions []*models.PaymentVersion, err error) {
+	defer ipr.observe(ctx, "ListVersions", time.Now(), &err)
+	return ipr.repo.ListVersions(ctx, paymentID)
+}
+
+// GetVersion returns the given version of the payment with paymentID
+func (ipr *InstrumentedPaymentRepository) GetVersion(ctx context.Context, paymentID strfmt.UUID, version int64) (got *models.PaymentVersion, err error) {
+	defer ipr.observe(ctx, "GetVersion", time.Now(), &err)
+	return ipr.repo.GetVersion(ctx, paymentID, version)
+}
+
+// Update updates the details associated with the given paymentID
+func (ipr *InstrumentedPaymentRepository) Update(ctx context.Context, paymentID strfmt.UUID, payment *models.Payment) (updated *models.Payment, err error) {
+	defer ipr.observe(ctx, "Update", time.Now(), &err)
+	return ipr.repo.Update(ctx, paymentID, payment)
+}
+
+// Patch updates the details of the payment with the given paymentID that
+// differ between original and patched
+func (ipr *InstrumentedPaymentRepository) Patch(ctx context.Context, paymentID strfmt.UUID, original, patched *models.Payment) (updated *models.Payment, err error) {
+	defer ipr.observe(ctx, "Patch", time.Now(), &err)
+	return ipr.repo.Patch(ctx, paymentID, original, patched)
+}
+
+// Close frees the resources held by the wrapped repository
+func (ipr *InstrumentedPaymentRepository) Close() error {
+	return ipr.repo.Close()
+}
@@ -0,0 +1,141 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentedPaymentRepository(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	repo, err := NewInstrumentedPaymentRepository(NewMemPaymentRepository(), reg)
+	if err != nil {
+		t.Fatalf("Error creating instrumented repository: %v", err)
+	}
+
+	ctx := context.Background()
+	payment := generateDummyPayments(1)[0]
+	if _, err := repo.Add(ctx, payment); err != nil {
+		t.Fatalf("Error adding payment: %v", err)
+	}
+	if _, err := repo.Get(ctx, *payment.ID); err != nil {
+		t.Fatalf("Error getting payment: %v", err)
+	}
+	if _, err := repo.Get(ctx, strfmt.UUID("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")); err == nil {
+		t.Fatal("Expected an error getting a missing payment")
+	}
+
+	if got := testutil.ToFloat64(repo.errors.WithLabelValues("Get", "not_found")); got != 1 {
+		t.Errorf("Want 1 not found error but got %v", got)
+	}
+	if got := testutil.ToFloat64(repo.errors.WithLabelValues("Add", "not_found")); got != 0 {
+		t.Errorf("Want no errors for Add but got %v", got)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Error gathering metrics: %v", err)
+	}
+	counts := make(map[string]uint64)
+	for _, family := range families {
+		if family.GetName() != "pAPI_repository_call_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			counts[m.GetLabel()[0].GetValue()] = m.GetHistogram().GetSampleCount()
+		}
+	}
+	if counts["Add"] != 1 || counts["Get"] != 2 {
+		t.Errorf("Want 1 call to Add and 2 calls to Get measured but got %v", counts)
+	}
+}
+
+func TestInstrumentedPaymentRepositoryCanceled(t *testing.T) {
+	dbRepo, mock, err := setupRepo()
+	if err != nil {
+		t.Fatalf("Error setting up test repo: %v", err)
+	}
+	reg := prometheus.NewRegistry()
+	repo, err := NewInstrumentedPaymentRepository(dbRepo, reg)
+	if err != nil {
+		t.Fatalf("Error creating instrumented repository: %v", err)
+	}
+
+	// The DB repository wraps the error of the driver, so the error returned
+	// is not context.Canceled itself
+	mock.ExpectQuery(`^SELECT`).WillReturnError(errors.New("pq: canceling statement due to user request"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := repo.Get(ctx, strfmt.UUID("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")); err == nil {
+		t.Fatal("Expected an error getting a payment with a canceled context")
+	}
+
+	if got := testutil.ToFloat64(repo.errors.WithLabelValues("Get", "canceled")); got != 1 {
+		t.Errorf("Want 1 canceled call but got %v", got)
+	}
+	if got := testutil.ToFloat64(repo.errors.WithLabelValues("Get", "internal")); got != 0 {
+		t.Errorf("Want no internal errors but got %v", got)
+	}
+}
+
+func TestInstrumentedPaymentRepositoryTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating DB mock: %v", err)
+	}
+	dbRepo, err := NewDBPaymentRepository(db, &DBConfig{QueryTimeout: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unable to create test DB repo: %v", err)
+	}
+	defer dbRepo.Close()
+	reg := prometheus.NewRegistry()
+	repo, err := NewInstrumentedPaymentRepository(dbRepo, reg)
+	if err != nil {
+		t.Fatalf("Error creating instrumented repository: %v", err)
+	}
+
+	// The timeout of the query is not seen by the context of the call
+	mock.ExpectQuery(`^SELECT`).WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows(nil))
+	if _, err := repo.Get(context.Background(), strfmt.UUID("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")); err == nil {
+		t.Fatal("Expected an error getting a payment with a query timeout")
+	}
+
+	if got := testutil.ToFloat64(repo.errors.WithLabelValues("Get", "timeout")); got != 1 {
+		t.Errorf("Want 1 timed out call but got %v", got)
+	}
+	if got := testutil.ToFloat64(repo.errors.WithLabelValues("Get", "internal")); got != 0 {
+		t.Errorf("Want no internal errors but got %v", got)
+	}
+}
+
+func TestDBStatsCollector(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock connection: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(7)
+
+	want := `
+		# HELP pAPI_db_max_open_connections Maximum number of open connections to the DB.
+		# TYPE pAPI_db_max_open_connections gauge
+		pAPI_db_max_open_connections 7
+		# HELP pAPI_db_in_use_connections The number of connections to the DB currently in use.
+		# TYPE pAPI_db_in_use_connections gauge
+		pAPI_db_in_use_connections 0
+	`
+	err = testutil.CollectAndCompare(NewDBStatsCollector(db), strings.NewReader(want),
+		"pAPI_db_max_open_connections", "pAPI_db_in_use_connections")
+	if err != nil {
+		t.Error(err)
+	}
+}
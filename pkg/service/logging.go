@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/volmedo/pAPI/pkg/tracing"
+)
+
+// requestIDKey is the key under which the ID of a request is stored in its context
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx that carries the ID of the request
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the ID of the request stored in ctx, if any
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// RequestLogger returns a logger that adds the ID of the request stored in ctx
+// to every entry, so that all the entries logged while handling a request can
+// be told apart from the rest. The ID of the trace of the request is added too,
+// if it is being traced. The standard logger is used if logger is nil
+func RequestLogger(ctx context.Context, logger logrus.FieldLogger) logrus.FieldLogger {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+
+	if id, ok := RequestIDFromContext(ctx); ok {
+		logger = logger.WithField("request_id", id)
+	}
+	if span := tracing.FromContext(ctx); span != nil {
+		logger = logger.WithField("trace_id", span.Context().TraceID.String())
+	}
+
+	return logger
+}
@@ -0,0 +1,98 @@
+// +build !integration
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/sirupsen/logrus"
+
+	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi/operations/payments"
+)
+
+// brokenRepo is a repository whose Get fails with err
+type brokenRepo struct {
+	*MemPaymentRepository
+	err error
+}
+
+func (br *brokenRepo) Get(ctx context.Context, paymentID strfmt.UUID) (*models.Payment, error) {
+	return nil, br.err
+}
+
+func TestErrorCorrelation(t *testing.T) {
+	const requestID = "req-42"
+	driverErr := errors.New(`pq: relation "payments" does not exist`)
+	paymentID := strfmt.UUID("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+
+	tests := map[string]struct {
+		err         error
+		wantMessage string
+		wantLogged  bool
+	}{
+		"unexpected error": {
+			err:         driverErr,
+			wantMessage: "An unexpected error occurred, please report it along with the error code",
+			wantLogged:  true,
+		},
+		"expected error": {
+			err:         newErrNoResults("db: payment with ID " + paymentID.String() + " not found"),
+			wantMessage: "payment with ID " + paymentID.String() + " not found",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := logrus.New()
+			logger.Out = buf
+			logger.Formatter = &logrus.JSONFormatter{}
+
+			papi := &PaymentsService{
+				Repo:   &brokenRepo{MemPaymentRepository: NewMemPaymentRepository(), err: tc.err},
+				Logger: logger,
+			}
+			ctx := ContextWithRequestID(context.Background(), requestID)
+			resp := papi.GetPayment(ctx, payments.GetPaymentParams{ID: paymentID})
+
+			var apiErr *models.APIError
+			switch r := resp.(type) {
+			case *payments.GetPaymentInternalServerError:
+				apiErr = r.Payload
+			case *payments.GetPaymentNotFound:
+				apiErr = r.Payload
+			default:
+				t.Fatalf("Unexpected response %T", resp)
+			}
+
+			if apiErr.ErrorCode != requestID {
+				t.Errorf("Want the request ID as error code but got %q", apiErr.ErrorCode)
+			}
+			if apiErr.ErrorMessage != tc.wantMessage {
+				t.Errorf("Want message %q but got %q", tc.wantMessage, apiErr.ErrorMessage)
+			}
+
+			if !tc.wantLogged {
+				if buf.Len() > 0 {
+					t.Errorf("Expected errors shouldn't be logged but got %s", buf)
+				}
+				return
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Want a JSON log entry but got %q", buf)
+			}
+			if entry["request_id"] != requestID || !strings.Contains(entry["error"].(string), "pq:") {
+				t.Errorf("Want the details of the error logged under the request ID but got %v", entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemIdempotencyStore is an IdempotencyStore that keeps records in memory.
+// It mimics DBIdempotencyStore and is meant to be used along with MemPaymentRepository
+type MemIdempotencyStore struct {
+	ttl     time.Duration
+	records map[string]memIdempotencyRecord
+	mu      sync.Mutex
+
+	// now returns the current time. It can be replaced in tests
+	now func() time.Time
+}
+
+type memIdempotencyRecord struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemIdempotencyStore creates a new, empty store whose records expire after ttl
+func NewMemIdempotencyStore(ttl time.Duration) *MemIdempotencyStore {
+	return &MemIdempotencyStore{
+		ttl:     ttl,
+		records: make(map[string]memIdempotencyRecord),
+		now:     time.Now,
+	}
+}
+
+// Get returns the record saved for the given key
+//
+// Get returns an error if there is no record for key or if it has expired
+func (mis *MemIdempotencyStore) Get(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	saved, ok := mis.records[key]
+	if !ok || !mis.now().Before(saved.expiresAt) {
+		return nil, newErrNoResults(fmt.Sprintf("mem: no record for idempotency key %q", key))
+	}
+
+	record := saved.record
+	record.Response = append([]byte(nil), saved.record.Response...)
+	return &record, nil
+}
+
+// Reserve saves a new pending record, without a response, which will expire
+// when the time to live of the store has passed. Expired records are removed
+// from the store
+//
+// Reserve returns an error if a record that has not expired yet already
+// exists for the same key
+func (mis *MemIdempotencyStore) Reserve(ctx context.Context, record *IdempotencyRecord) error {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	now := mis.now()
+	for key, saved := range mis.records {
+		if !now.Before(saved.expiresAt) {
+			delete(mis.records, key)
+		}
+	}
+
+	if _, ok := mis.records[record.Key]; ok {
+		return newErrConflict(fmt.Sprintf("mem: a record for idempotency key %q already exists", record.Key))
+	}
+
+	saved := memIdempotencyRecord{record: *record, expiresAt: now.Add(mis.ttl)}
+	saved.record.Pending = true
+	saved.record.Response = nil
+	mis.records[record.Key] = saved
+
+	return nil
+}
+
+// Complete saves the response of a pending record, which will expire when
+// the time to live of the store has passed from then on
+//
+// Complete returns an error if there is no pending record for the key
+func (mis *MemIdempotencyStore) Complete(ctx context.Context, record *IdempotencyRecord) error {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	now := mis.now()
+	saved, ok := mis.records[record.Key]
+	if !ok || !saved.record.Pending || !now.Before(saved.expiresAt) {
+		return newErrNoResults(fmt.Sprintf("mem: no pending record for idempotency key %q", record.Key))
+	}
+
+	saved.record.Pending = false
+	saved.record.Response = append([]byte(nil), record.Response...)
+	saved.expiresAt = now.Add(mis.ttl)
+	mis.records[record.Key] = saved
+
+	return nil
+}
+
+// Release removes the pending record for the given key, so that the key
+// can be used again. Records that have a response are kept
+func (mis *MemIdempotencyStore) Release(ctx context.Context, key string) error {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	if saved, ok := mis.records[key]; ok && saved.record.Pending {
+		delete(mis.records, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemIdempotencyReserveComplete(t *testing.T) {
+	store := NewMemIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	if err := store.Reserve(ctx, &IdempotencyRecord{Key: "key", Fingerprint: "fingerprint"}); err != nil {
+		t.Fatalf("Unexpected error reserving key: %v", err)
+	}
+
+	got, err := store.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Unexpected error getting record: %v", err)
+	}
+	if !got.Pending || got.Response != nil {
+		t.Errorf("Want a pending record without response but got %+v", got)
+	}
+
+	record := &IdempotencyRecord{Key: "key", Fingerprint: "fingerprint", Response: []byte(`{"data":{}}`)}
+	if err := store.Complete(ctx, record); err != nil {
+		t.Fatalf("Unexpected error completing record: %v", err)
+	}
+
+	// Modifying the saved record must not affect the store
+	record.Response[0] = '['
+
+	got, err = store.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Unexpected error getting record: %v", err)
+	}
+
+	if got.Key != "key" || got.Fingerprint != "fingerprint" || got.Pending || string(got.Response) != `{"data":{}}` {
+		t.Errorf("Wrong record: %+v", got)
+	}
+
+	// Records can only be completed once
+	if err := store.Complete(ctx, record); err == nil {
+		t.Error("Completing a record twice should've failed")
+	}
+
+	if _, err := store.Get(ctx, "other key"); err == nil {
+		t.Error("Test should've failed but no error was produced")
+	} else if _, ok := err.(ErrNoResults); !ok {
+		t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+	}
+}
+
+func TestMemIdempotencyConflict(t *testing.T) {
+	store := NewMemIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	if err := store.Reserve(ctx, &IdempotencyRecord{Key: "key", Fingerprint: "first"}); err != nil {
+		t.Fatalf("Unexpected error reserving key: %v", err)
+	}
+
+	err := store.Reserve(ctx, &IdempotencyRecord{Key: "key", Fingerprint: "second"})
+	if _, ok := err.(ErrConflict); !ok {
+		t.Fatalf("Expected ErrConflict but got %T (%v)", err, err)
+	}
+
+	got, err := store.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Unexpected error getting record: %v", err)
+	}
+	if got.Fingerprint != "first" {
+		t.Errorf("Want the first record to be kept but got %+v", got)
+	}
+}
+
+func TestMemIdempotencyRelease(t *testing.T) {
+	store := NewMemIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	if err := store.Reserve(ctx, &IdempotencyRecord{Key: "pending", Fingerprint: "fingerprint"}); err != nil {
+		t.Fatalf("Unexpected error reserving key: %v", err)
+	}
+	if err := store.Reserve(ctx, &IdempotencyRecord{Key: "completed", Fingerprint: "fingerprint"}); err != nil {
+		t.Fatalf("Unexpected error reserving key: %v", err)
+	}
+	if err := store.Complete(ctx, &IdempotencyRecord{Key: "completed", Response: []byte(`{}`)}); err != nil {
+		t.Fatalf("Unexpected error completing record: %v", err)
+	}
+
+	for _, key := range []string{"pending", "completed"} {
+		if err := store.Release(ctx, key); err != nil {
+			t.Fatalf("Unexpected error releasing %s key: %v", key, err)
+		}
+	}
+
+	// Released keys can be reserved again
+	if err := store.Reserve(ctx, &IdempotencyRecord{Key: "pending", Fingerprint: "fingerprint"}); err != nil {
+		t.Errorf("Unexpected error reserving released key: %v", err)
+	}
+	if _, err := store.Get(ctx, "completed"); err != nil {
+		t.Errorf("Want completed records to be kept but got %v", err)
+	}
+}
+
+func TestMemIdempotencyExpiry(t *testing.T) {
+	store := NewMemIdempotencyStore(time.Hour)
+	ctx := context.Background()
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	if err := store.Reserve(ctx, &IdempotencyRecord{Key: "key", Fingerprint: "first"}); err != nil {
+		t.Fatalf("Unexpected error reserving key: %v", err)
+	}
+
+	// Completed records expire when the time to live has passed since they
+	// were completed
+	now = now.Add(30 * time.Minute)
+	if err := store.Complete(ctx, &IdempotencyRecord{Key: "key", Response: []byte(`{}`)}); err != nil {
+		t.Fatalf("Unexpected error completing record: %v", err)
+	}
+
+	now = now.Add(59 * time.Minute)
+	if _, err := store.Get(ctx, "key"); err != nil {
+		t.Fatalf("Unexpected error getting record before it expires: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := store.Get(ctx, "key"); err == nil {
+		t.Fatal("Expired record should not be returned")
+	}
+
+	// Keys can be used again once their records have expired
+	if err := store.Reserve(ctx, &IdempotencyRecord{Key: "key", Fingerprint: "second"}); err != nil {
+		t.Fatalf("Unexpected error reserving key again: %v", err)
+	}
+	got, err := store.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Unexpected error getting record: %v", err)
+	}
+	if got.Fingerprint != "second" {
+		t.Errorf("Want the new record but got %+v", got)
+	}
+}
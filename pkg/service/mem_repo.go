@@ -0,0 +1,580 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/volmedo/pAPI/pkg/models"
+)
+
+// MemPaymentRepository stores a collection of payment resources in memory.
+// It is safe for concurrent use and mimics the behaviour of DBPaymentRepository,
+// so it can be used in place of it when a database is not available or needed
+// (e.g. local runs, demos or test pipelines).
+// Operations on memory never block, so contexts passed to its methods are only
+// used to find out the organisations the caller can access
+type MemPaymentRepository struct {
+	mu       sync.RWMutex
+	payments map[strfmt.UUID]*models.Payment
+	versions map[strfmt.UUID][]*models.PaymentVersion
+}
+
+// NewMemPaymentRepository creates a new, empty MemPaymentRepository
+func NewMemPaymentRepository() *MemPaymentRepository {
+	return &MemPaymentRepository{
+		payments: make(map[strfmt.UUID]*models.Payment),
+		versions: make(map[strfmt.UUID][]*models.PaymentVersion),
+	}
+}
+
+// Close does nothing, as there are no resources to free. It is provided
+// for parity with DBPaymentRepository
+func (mpr *MemPaymentRepository) Close() error {
+	return nil
+}
+
+// memKey normalizes a payment ID so that the same UUID is always stored under
+// the same key, no matter the case used to write it
+func memKey(paymentID strfmt.UUID) strfmt.UUID {
+	return strfmt.UUID(strings.ToLower(paymentID.String()))
+}
+
+// recordVersion appends a new version of a payment to its history.
+// It must be called with the write lock held
+func (mpr *MemPaymentRepository) recordVersion(key strfmt.UUID, operation string, payment *models.Payment) {
+	version := *payment.Version
+	recordedAt := strfmt.DateTime(time.Now())
+	mpr.versions[key] = append(mpr.versions[key], &models.PaymentVersion{
+		Data:       copyPayment(payment),
+		Operation:  &operation,
+		RecordedAt: &recordedAt,
+		Version:    &version,
+	})
+}
+
+// Add adds a new payment resource to the repository
+//
+// Add returns an error if a payment with the same ID as the one
+// to be added already exists
+func (mpr *MemPaymentRepository) Add(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	if err := checkOrganisation(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+
+	key := memKey(*payment.ID)
+	if _, ok := mpr.payments[key]; ok {
+		return nil, newErrConflict(fmt.Sprintf("mem: a payment with ID %s already exists", *payment.ID))
+	}
+
+	added := copyPayment(payment)
+	// Add type and version attributes
+	version := int64(0)
+	added.Type = TYPE_PAYMENT
+	added.Version = &version
+	mpr.payments[key] = added
+	mpr.recordVersion(key, models.PaymentVersionOperationCreate, added)
+
+	return copyPayment(added), nil
+}
+
+// AddBatch adds several new payment resources to the repository at once
+//
+// If atomic is true, either every payment is added or none is. Otherwise,
+// the payments that can't be added are skipped and the rest are added
+//
+// AddBatch returns the added payments in the same order as the given ones,
+// with nil in place of those that were not added. It returns an
+// ErrBatchConflict if some payments have the same ID as an existing payment
+// or as a previous payment in the batch
+func (mpr *MemPaymentRepository) AddBatch(ctx context.Context, payments []*models.Payment, atomic bool) ([]*models.Payment, error) {
+	for _, payment := range payments {
+		if err := checkOrganisation(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+
+	conflicts := []int{}
+	seen := make(map[strfmt.UUID]bool, len(payments))
+	for i, payment := range payments {
+		key := memKey(*payment.ID)
+		if _, ok := mpr.payments[key]; ok || seen[key] {
+			conflicts = append(conflicts, i)
+			continue
+		}
+		seen[key] = true
+	}
+
+	var conflictErr error
+	if len(conflicts) > 0 {
+		conflictErr = newErrBatchConflict(fmt.Sprintf("mem: %d payments have the same ID as other payments", len(conflicts)), conflicts)
+		if atomic {
+			return nil, conflictErr
+		}
+	}
+
+	added := make([]*models.Payment, len(payments))
+	for i, payment := range payments {
+		key := memKey(*payment.ID)
+		if !seen[key] {
+			continue
+		}
+		// Only the first payment with a given ID is added
+		delete(seen, key)
+
+		stored := copyPayment(payment)
+		// Add type and version attributes
+		version := int64(0)
+		stored.Type = TYPE_PAYMENT
+		stored.Version = &version
+		mpr.payments[key] = stored
+		mpr.recordVersion(key, models.PaymentVersionOperationCreate, stored)
+		added[i] = copyPayment(stored)
+	}
+
+	return added, conflictErr
+}
+
+// Delete deletes the payment resource associated to the given paymentID
+// and returns it as it was before being deleted
+//
+// Delete returns an error if the paymentID is not present in the respository
+func (mpr *MemPaymentRepository) Delete(ctx context.Context, paymentID strfmt.UUID) (*models.Payment, error) {
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+
+	key := memKey(paymentID)
+	stored, ok := mpr.payments[key]
+	if !ok || !canAccessOrganisation(ctx, stored.OrganisationID) {
+		return nil, newErrNoResults(fmt.Sprintf("mem: payment with ID %s not found", paymentID))
+	}
+
+	// The last version keeps the details the payment had when it was deleted
+	deleted := copyPayment(stored)
+	version := *deleted.Version + 1
+	deleted.Version = &version
+	mpr.recordVersion(key, models.PaymentVersionOperationDelete, deleted)
+
+	delete(mpr.payments, key)
+	return copyPayment(stored), nil
+}
+
+// DeleteAll deletes every payment in the repository, along with their version history
+func (mpr *MemPaymentRepository) DeleteAll(ctx context.Context) error {
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+
+	mpr.payments = make(map[strfmt.UUID]*models.Payment)
+	mpr.versions = make(map[strfmt.UUID][]*models.PaymentVersion)
+	return nil
+}
+
+// Get returns the payment resource associated with the given paymentID
+//
+// Get returns an error if the paymentID does not exist in the collection
+func (mpr *MemPaymentRepository) Get(ctx context.Context, paymentID strfmt.UUID) (*models.Payment, error) {
+	mpr.mu.RLock()
+	defer mpr.mu.RUnlock()
+
+	payment, ok := mpr.payments[memKey(paymentID)]
+	if !ok || !canAccessOrganisation(ctx, payment.OrganisationID) {
+		return nil, newErrNoResults(fmt.Sprintf("mem: payment with ID %s not found", paymentID))
+	}
+
+	return copyPayment(payment), nil
+}
+
+// List returns a page of payment resources, sorted by the given fields and then
+// by ID, as DBPaymentRepository does.
+//
+// Only payments that match the given filter are returned. A zero filter
+// matches every payment.
+//
+// The page to return is selected either by offset or by the position of the
+// payments relative to a cursor, as set in pagination. Limit must be between 1 and 100.
+// List will return an error if the page contains no payments.
+func (mpr *MemPaymentRepository) List(ctx context.Context, filter PaymentFilter, sortBy []SortField, pagination Pagination) (*PaymentPage, error) {
+	// Check params before anything else
+	limit := pagination.Limit
+	if limit <= 0 || limit > 100 {
+		return nil, newErrBadOffsetLimit(fmt.Sprintf("mem: list limit %d is outside allowed range (0, 100]", limit))
+	}
+
+	if pagination.Offset < 0 {
+		return nil, newErrBadOffsetLimit(fmt.Sprintf("mem: list offset %d negative", pagination.Offset))
+	}
+
+	if pagination.After != nil && pagination.Before != nil {
+		return nil, newErrBadOffsetLimit("mem: list can't page both after and before a cursor")
+	}
+
+	for _, field := range sortBy {
+		if _, ok := sortableFields[field.Name]; !ok {
+			return nil, fmt.Errorf("mem: unknown sort field %q", field.Name)
+		}
+	}
+
+	for _, cursor := range []*Cursor{pagination.After, pagination.Before} {
+		if cursor != nil && len(cursor.Values) != len(sortBy) {
+			return nil, newErrBadOffsetLimit(fmt.Sprintf("mem: cursor has %d values for %d sort fields", len(cursor.Values), len(sortBy)))
+		}
+	}
+
+	mpr.mu.RLock()
+	defer mpr.mu.RUnlock()
+
+	keys := sortKeys(sortBy)
+	type entry struct {
+		payment *models.Payment
+		values  []string
+	}
+	entries := make([]entry, 0, len(mpr.payments))
+	for _, payment := range mpr.payments {
+		if matchesFilter(payment, filter) && canAccessOrganisation(ctx, payment.OrganisationID) {
+			entries = append(entries, entry{payment, sortValues(payment, keys)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return compareSortValues(keys, entries[i].values, entries[j].values) < 0
+	})
+
+	// Select one more payment than needed to know whether there are more
+	// payments beyond the page, as DBPaymentRepository does
+	var selected []entry
+	switch {
+	case pagination.After != nil:
+		values := cursorValues(sortBy, pagination.After)
+		from := sort.Search(len(entries), func(i int) bool {
+			return compareSortValues(keys, entries[i].values, values) > 0
+		})
+		selected = entries[from:]
+		if int64(len(selected)) > limit+1 {
+			selected = selected[:limit+1]
+		}
+
+	case pagination.Before != nil:
+		values := cursorValues(sortBy, pagination.Before)
+		to := sort.Search(len(entries), func(i int) bool {
+			return compareSortValues(keys, entries[i].values, values) >= 0
+		})
+		from := int64(to) - (limit + 1)
+		if from < 0 {
+			from = 0
+		}
+		// Payments must be in reverse order when paging backward
+		for i := to - 1; i >= int(from); i-- {
+			selected = append(selected, entries[i])
+		}
+
+	default:
+		if pagination.Offset < int64(len(entries)) {
+			selected = entries[pagination.Offset:]
+		}
+		if int64(len(selected)) > limit+1 {
+			selected = selected[:limit+1]
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, newErrNoResults(fmt.Sprintf("mem: no results with offset %d and limit %d", pagination.Offset, limit))
+	}
+
+	payments := make([]*models.Payment, 0, len(selected))
+	for _, e := range selected {
+		payments = append(payments, copyPayment(e.payment))
+	}
+
+	return newPaymentPage(payments, pagination), nil
+}
+
+// sortValues returns the values of the given sort keys for payment
+func sortValues(payment *models.Payment, keys []SortField) []string {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, sortableFields[key.Name].value(payment))
+	}
+
+	return values
+}
+
+// compareSortValues compares two sets of values of the given sort keys,
+// as DBPaymentRepository would do when sorting payments by them.
+// The result is negative if a comes first, positive if b does and 0 if they are equal
+func compareSortValues(keys []SortField, a, b []string) int {
+	for i, key := range keys {
+		cmp := compareSortValue(sortableFields[key.Name].kind, a[i], b[i])
+		if key.Descending {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+
+	return 0
+}
+
+// compareSortValue compares two values of a field of the given kind
+func compareSortValue(kind sortKind, a, b string) int {
+	if kind == sortNumber {
+		x, okA := new(big.Rat).SetString(a)
+		y, okB := new(big.Rat).SetString(b)
+		if okA && okB {
+			return x.Cmp(y)
+		}
+	}
+
+	// Dates in full-date format and lowercase UUIDs sort the same as text
+	return strings.Compare(a, b)
+}
+
+// Count returns the number of payments that match the given filter.
+// A zero filter matches every payment
+func (mpr *MemPaymentRepository) Count(ctx context.Context, filter PaymentFilter) (int64, error) {
+	mpr.mu.RLock()
+	defer mpr.mu.RUnlock()
+
+	count := int64(0)
+	for _, payment := range mpr.payments {
+		if matchesFilter(payment, filter) && canAccessOrganisation(ctx, payment.OrganisationID) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// matchesFilter reports whether payment meets every criteria set in filter
+func matchesFilter(payment *models.Payment, filter PaymentFilter) bool {
+	attrs := payment.Attributes
+	if attrs == nil {
+		attrs = &models.PaymentAttributes{}
+	}
+
+	if filter.OrganisationID != nil {
+		if payment.OrganisationID == nil || !strings.EqualFold(payment.OrganisationID.String(), filter.OrganisationID.String()) {
+			return false
+		}
+	}
+
+	if filter.Currency != nil && attrs.Currency != *filter.Currency {
+		return false
+	}
+
+	if filter.PaymentScheme != nil && attrs.PaymentScheme != *filter.PaymentScheme {
+		return false
+	}
+
+	date := time.Time(attrs.ProcessingDate)
+	if filter.ProcessingDateFrom != nil && date.Before(time.Time(*filter.ProcessingDateFrom)) {
+		return false
+	}
+
+	if filter.ProcessingDateTo != nil && date.After(time.Time(*filter.ProcessingDateTo)) {
+		return false
+	}
+
+	if filter.AmountFrom != nil || filter.AmountTo != nil {
+		amount, ok := parseAmount(attrs.Amount)
+		if !ok {
+			return false
+		}
+
+		if filter.AmountFrom != nil {
+			from, ok := parseAmount(*filter.AmountFrom)
+			if !ok || amount.Cmp(from) < 0 {
+				return false
+			}
+		}
+
+		if filter.AmountTo != nil {
+			to, ok := parseAmount(*filter.AmountTo)
+			if !ok || amount.Cmp(to) > 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// parseAmount converts an amount to a number that can be compared exactly.
+// It returns false if the amount is not a valid decimal number
+func parseAmount(amount models.Amount) (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(amount))
+}
+
+// Update updates the details associated with the given paymentID. Every detail
+// is replaced, use Patch to change only some of them.
+//
+// If the version of the given payment is set, the update is only applied if it
+// matches the stored version.
+//
+// Update returns an error if the paymentID does not exist in the collection
+// or if the version of the given payment is stale
+func (mpr *MemPaymentRepository) Update(ctx context.Context, paymentID strfmt.UUID, payment *models.Payment) (*models.Payment, error) {
+	if err := checkOrganisation(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+
+	key := memKey(paymentID)
+	original, ok := mpr.payments[key]
+	if !ok || !canAccessOrganisation(ctx, original.OrganisationID) {
+		return nil, newErrNoResults(fmt.Sprintf("mem: payment with ID %s not found", paymentID))
+	}
+
+	if payment.Version != nil && *payment.Version != *original.Version {
+		return nil, newErrVersionMismatch(fmt.Sprintf("mem: payment with ID %s is at version %d, not %d",
+			paymentID, *original.Version, *payment.Version))
+	}
+
+	updated := copyPayment(payment)
+	// Keep the ID the payment is stored with and add type and version attributes
+	id := *original.ID
+	version := *original.Version + 1
+	updated.ID = &id
+	updated.Type = TYPE_PAYMENT
+	updated.Version = &version
+	mpr.payments[key] = updated
+	mpr.recordVersion(key, models.PaymentVersionOperationUpdate, updated)
+
+	return copyPayment(updated), nil
+}
+
+// Patch updates the details of the payment with the given paymentID that
+// differ between original and patched, leaving the rest of the details as they
+// are currently stored, as DBPaymentRepository does.
+//
+// If the version of patched is set, the patch is only applied if it matches
+// the stored version.
+//
+// Patch returns an error if the paymentID does not exist in the collection
+// or if the version of the patched payment is stale
+func (mpr *MemPaymentRepository) Patch(ctx context.Context, paymentID strfmt.UUID, original, patched *models.Payment) (*models.Payment, error) {
+	if err := checkOrganisationChange(ctx, original, patched); err != nil {
+		return nil, err
+	}
+
+	mpr.mu.Lock()
+	defer mpr.mu.Unlock()
+
+	key := memKey(paymentID)
+	stored, ok := mpr.payments[key]
+	if !ok || !canAccessOrganisation(ctx, stored.OrganisationID) {
+		return nil, newErrNoResults(fmt.Sprintf("mem: payment with ID %s not found", paymentID))
+	}
+
+	if patched.Version != nil && *patched.Version != *stored.Version {
+		return nil, newErrVersionMismatch(fmt.Sprintf("mem: payment with ID %s is at version %d, not %d",
+			paymentID, *stored.Version, *patched.Version))
+	}
+
+	updated, err := applyPaymentChanges(stored, original, patched)
+	if err != nil {
+		return nil, fmt.Errorf("mem: error patching payment with ID %s: %v", paymentID, err)
+	}
+
+	// Keep the ID the payment is stored with and add type and version attributes
+	id := *stored.ID
+	version := *stored.Version + 1
+	updated.ID = &id
+	updated.Type = TYPE_PAYMENT
+	updated.Version = &version
+	mpr.payments[key] = updated
+	mpr.recordVersion(key, models.PaymentVersionOperationUpdate, updated)
+
+	return copyPayment(updated), nil
+}
+
+// applyPaymentChanges returns a copy of stored with the details that differ
+// between original and patched changed as they are in patched
+func applyPaymentChanges(stored, original, patched *models.Payment) (*models.Payment, error) {
+	docs := make([]interface{}, 3)
+	for i, payment := range []*models.Payment{stored, original, patched} {
+		doc, err := toJSONValue(payment)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = doc
+	}
+
+	changes := createMergePatch(docs[1], docs[2])
+	var updated models.Payment
+	if err := fromJSONValue(applyMergePatch(docs[0], changes), &updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// ListVersions returns every version recorded for the payment with the given
+// paymentID, oldest first. If a deleted payment is added again, versions of
+// both the old and the new payment are returned, as DBPaymentRepository does.
+//
+// ListVersions returns an error if there are no versions for the paymentID
+func (mpr *MemPaymentRepository) ListVersions(ctx context.Context, paymentID strfmt.UUID) ([]*models.PaymentVersion, error) {
+	mpr.mu.RLock()
+	defer mpr.mu.RUnlock()
+
+	history := mpr.versions[memKey(paymentID)]
+	versions := make([]*models.PaymentVersion, 0, len(history))
+	for _, version := range history {
+		// Versions are only shown to the organisation the payment belonged to
+		// when they were recorded, as DBPaymentRepository does
+		if canAccessOrganisation(ctx, version.Data.OrganisationID) {
+			versions = append(versions, copyPaymentVersion(version))
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, newErrNoResults(fmt.Sprintf("mem: no versions found for payment with ID %s", paymentID))
+	}
+
+	return versions, nil
+}
+
+// GetVersion returns the given version of the payment with paymentID. If a
+// deleted payment has been added again, the version of the newest one is returned
+//
+// GetVersion returns an error if the version does not exist
+func (mpr *MemPaymentRepository) GetVersion(ctx context.Context, paymentID strfmt.UUID, version int64) (*models.PaymentVersion, error) {
+	mpr.mu.RLock()
+	defer mpr.mu.RUnlock()
+
+	history := mpr.versions[memKey(paymentID)]
+	for i := len(history) - 1; i >= 0; i-- {
+		if *history[i].Version == version && canAccessOrganisation(ctx, history[i].Data.OrganisationID) {
+			return copyPaymentVersion(history[i]), nil
+		}
+	}
+
+	return nil, newErrNoResults(fmt.Sprintf("mem: version %d of payment with ID %s not found", version, paymentID))
+}
+
+// copyPaymentVersion performs a deep copy of a models.PaymentVersion structure
+func copyPaymentVersion(version *models.PaymentVersion) *models.PaymentVersion {
+	operation := *version.Operation
+	recordedAt := *version.RecordedAt
+	number := *version.Version
+	return &models.PaymentVersion{
+		Data:       copyPayment(version.Data),
+		Operation:  &operation,
+		RecordedAt: &recordedAt,
+		Version:    &number,
+	}
+}
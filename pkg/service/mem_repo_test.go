@@ -0,0 +1,934 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi"
+)
+
+func TestMemAdd(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	// Modify the test payment to check that it gets the right type
+	testPayment.Type = TYPE_PAYMENT + "BAD"
+	added, err := testRepo.Add(ctx, testPayment)
+	if err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	if added.Type != TYPE_PAYMENT {
+		t.Errorf("Wanted type to be %s but got %s", TYPE_PAYMENT, added.Type)
+	}
+
+	if *added.Version != 0 {
+		t.Errorf("Wanted version to be 0 but got %d", *added.Version)
+	}
+
+	if added == testPayment {
+		t.Error("Added payment points to the same value as the original one")
+	}
+}
+
+func TestMemAddConflict(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	// IDs that only differ in case are the same UUID
+	dup := copyPayment(testPayment)
+	upperID := strfmt.UUID(strings.ToUpper(testPayment.ID.String()))
+	dup.ID = &upperID
+	_, err := testRepo.Add(ctx, dup)
+	e, ok := err.(ErrConflict)
+	if err == nil || !ok {
+		t.Errorf("Expected ErrConflict but got %v", e)
+	}
+}
+
+func TestMemAddBatch(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayments := generateDummyPayments(3)
+	added, err := testRepo.AddBatch(ctx, testPayments, true)
+	if err != nil {
+		t.Fatalf("Unexpected error adding payments: %v", err)
+	}
+
+	for i, payment := range added {
+		if *payment.ID != *testPayments[i].ID {
+			t.Errorf("Wanted payment %d to have ID %s but got %s", i, *testPayments[i].ID, *payment.ID)
+		}
+		if payment.Type != TYPE_PAYMENT || *payment.Version != 0 {
+			t.Errorf("Wanted payment %d to have type %s and version 0", i, TYPE_PAYMENT)
+		}
+		if _, err := testRepo.Get(ctx, *payment.ID); err != nil {
+			t.Errorf("Unexpected error getting added payment: %v", err)
+		}
+	}
+}
+
+func TestMemAddBatchConflict(t *testing.T) {
+	ctx := context.Background()
+	existing := generateDummyPayments(1)[0]
+	testPayments := generateDummyPayments(3)
+	// The first payment conflicts with an existing one and the last one
+	// with the second one
+	testPayments[0] = copyPayment(existing)
+	upperID := strfmt.UUID(strings.ToUpper(testPayments[1].ID.String()))
+	testPayments[2].ID = &upperID
+
+	for _, atomic := range []bool{true, false} {
+		testRepo := NewMemPaymentRepository()
+		if _, err := testRepo.Add(ctx, existing); err != nil {
+			t.Fatalf("Unexpected error adding payment: %v", err)
+		}
+
+		added, err := testRepo.AddBatch(ctx, testPayments, atomic)
+		e, ok := err.(ErrBatchConflict)
+		if err == nil || !ok {
+			t.Fatalf("Expected ErrBatchConflict but got %v", err)
+		}
+		if !reflect.DeepEqual(e.Indexes, []int{0, 2}) {
+			t.Errorf("Wanted conflicts at [0 2] but got %v", e.Indexes)
+		}
+
+		count, err := testRepo.Count(ctx, PaymentFilter{})
+		if err != nil {
+			t.Fatalf("Unexpected error counting payments: %v", err)
+		}
+
+		if atomic {
+			if added != nil || count != 1 {
+				t.Errorf("Wanted no payments to be added but %d are stored", count)
+			}
+			continue
+		}
+
+		if len(added) != 3 || added[0] != nil || added[1] == nil || added[2] != nil {
+			t.Errorf("Wanted only payment 1 to be added but got %v", added)
+		}
+		if count != 2 {
+			t.Errorf("Wanted 2 payments to be stored but got %d", count)
+		}
+	}
+}
+
+func TestMemDelete(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	deleted, err := testRepo.Delete(ctx, *testPayment.ID)
+	if err != nil {
+		t.Errorf("Unexpected error deleting payment: %v", err)
+	}
+	if deleted == nil || *deleted.ID != *testPayment.ID {
+		t.Errorf("Wanted the deleted payment but got %v", deleted)
+	}
+
+	if _, err := testRepo.Get(ctx, *testPayment.ID); err == nil {
+		t.Error("Payment is still present after being deleted")
+	}
+}
+
+func TestMemDeleteNonExistent(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	_, err := testRepo.Delete(ctx, *testPayment.ID)
+	e, ok := err.(ErrNoResults)
+	if err == nil || !ok {
+		t.Errorf("Expected ErrNoResults but got %v", e)
+	}
+}
+
+func TestMemGet(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	got, err := testRepo.Get(ctx, *testPayment.ID)
+	if err != nil {
+		t.Fatalf("Error getting payment: %v", err)
+	}
+
+	if got.Type != TYPE_PAYMENT {
+		t.Errorf("Wanted type to be %s but got %s", TYPE_PAYMENT, got.Type)
+	}
+
+	// Modifying the returned payment must not modify the stored one
+	got.Attributes.Amount = "1000.00"
+	again, _ := testRepo.Get(ctx, *testPayment.ID)
+	if again.Attributes.Amount == got.Attributes.Amount {
+		t.Error("Stored payment was modified through a returned value")
+	}
+}
+
+func TestMemGetNonExistent(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	_, err := testRepo.Get(ctx, *testPayment.ID)
+	e, ok := err.(ErrNoResults)
+	if err == nil || !ok {
+		t.Errorf("Expected ErrNoResults but got %v", e)
+	}
+}
+
+func TestMemList(t *testing.T) {
+	testPayments := generateDummyPayments(200)
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+	for _, payment := range testPayments {
+		if _, err := testRepo.Add(ctx, payment); err != nil {
+			t.Fatalf("Unexpected error adding payment: %v", err)
+		}
+	}
+
+	// Payments must be listed in the same order DBPaymentRepository uses
+	sortedIDs := make([]string, 0, len(testPayments))
+	for _, payment := range testPayments {
+		sortedIDs = append(sortedIDs, payment.ID.String())
+	}
+	sort.Strings(sortedIDs)
+
+	tests := map[string]struct {
+		offset      int64
+		limit       int64
+		expectedLen int
+	}{
+		"first 5": {
+			offset:      0,
+			limit:       5,
+			expectedLen: 5,
+		},
+		"from 25 to 32": {
+			offset:      25,
+			limit:       6,
+			expectedLen: 6,
+		},
+		"less than limit available": {
+			offset:      int64(len(testPayments) - 10),
+			limit:       20,
+			expectedLen: 10,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			page, err := testRepo.List(ctx, PaymentFilter{}, nil, Pagination{Offset: tc.offset, Limit: tc.limit})
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+
+			if len(page.Payments) != tc.expectedLen {
+				t.Fatalf("Want %d items but got %d", tc.expectedLen, len(page.Payments))
+			}
+
+			if wantNext := tc.offset+tc.limit < int64(len(testPayments)); page.HasNext != wantNext {
+				t.Errorf("Want next %t but got %t", wantNext, page.HasNext)
+			}
+
+			for i, payment := range page.Payments {
+				if want := sortedIDs[tc.offset+int64(i)]; payment.ID.String() != want {
+					t.Errorf("Want payment %s at position %d but got %s", want, i, payment.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestMemListCursor(t *testing.T) {
+	testPayments := generateDummyPayments(10)
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+	for _, payment := range testPayments {
+		if _, err := testRepo.Add(ctx, payment); err != nil {
+			t.Fatalf("Unexpected error adding payment: %v", err)
+		}
+	}
+
+	sortedIDs := make([]string, 0, len(testPayments))
+	for _, payment := range testPayments {
+		sortedIDs = append(sortedIDs, payment.ID.String())
+	}
+	sort.Strings(sortedIDs)
+	cursorAt := func(i int) *Cursor {
+		return &Cursor{ID: strfmt.UUID(sortedIDs[i])}
+	}
+
+	tests := map[string]struct {
+		pagination Pagination
+		from, to   int
+		wantNext   bool
+		wantPrev   bool
+	}{
+		"after with more": {
+			pagination: Pagination{Offset: 5, Limit: 3, After: cursorAt(2)},
+			from:       3,
+			to:         6,
+			wantNext:   true,
+			wantPrev:   true,
+		},
+		"after last page": {
+			pagination: Pagination{Limit: 3, After: cursorAt(6)},
+			from:       7,
+			to:         10,
+			wantNext:   false,
+			wantPrev:   true,
+		},
+		"before with more": {
+			pagination: Pagination{Limit: 3, Before: cursorAt(7)},
+			from:       4,
+			to:         7,
+			wantNext:   true,
+			wantPrev:   true,
+		},
+		"before first page": {
+			pagination: Pagination{Limit: 3, Before: cursorAt(2)},
+			from:       0,
+			to:         2,
+			wantNext:   true,
+			wantPrev:   false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			page, err := testRepo.List(ctx, PaymentFilter{}, nil, tc.pagination)
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+
+			if len(page.Payments) != tc.to-tc.from {
+				t.Fatalf("Want %d items but got %d", tc.to-tc.from, len(page.Payments))
+			}
+
+			for i, payment := range page.Payments {
+				if want := sortedIDs[tc.from+i]; payment.ID.String() != want {
+					t.Errorf("Want payment %s at position %d but got %s", want, i, payment.ID)
+				}
+			}
+
+			if page.HasNext != tc.wantNext || page.HasPrev != tc.wantPrev {
+				t.Errorf("Want next %t and prev %t but got %t and %t", tc.wantNext, tc.wantPrev, page.HasNext, page.HasPrev)
+			}
+		})
+	}
+
+	// A cursor doesn't need to point to an existing payment
+	if _, err := testRepo.Delete(ctx, strfmt.UUID(sortedIDs[3])); err != nil {
+		t.Fatalf("Unexpected error deleting payment: %v", err)
+	}
+	page, err := testRepo.List(ctx, PaymentFilter{}, nil, Pagination{Limit: 1, After: cursorAt(3)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+	if got := page.Payments[0].ID.String(); got != sortedIDs[4] {
+		t.Errorf("Want payment %s after deleted cursor but got %s", sortedIDs[4], got)
+	}
+}
+
+func TestMemListSorted(t *testing.T) {
+	testPayments := generateDummyPayments(6)
+	setAttrs := func(payment *models.Payment, currency, amount string) {
+		payment.Attributes.Currency = models.Currency(currency)
+		payment.Attributes.Amount = models.Amount(amount)
+	}
+	setAttrs(testPayments[0], "GBP", "10.00")
+	setAttrs(testPayments[1], "EUR", "5.00")
+	setAttrs(testPayments[2], "GBP", "9.99")
+	setAttrs(testPayments[3], "GBP", "100.00")
+	setAttrs(testPayments[4], "EUR", "5.00")
+	setAttrs(testPayments[5], "USD", "1.00")
+
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+	for _, payment := range testPayments {
+		if _, err := testRepo.Add(ctx, payment); err != nil {
+			t.Fatalf("Unexpected error adding payment: %v", err)
+		}
+	}
+
+	// Payments with the same currency and amount are sorted by ID
+	first, second := testPayments[1], testPayments[4]
+	if first.ID.String() > second.ID.String() {
+		first, second = second, first
+	}
+	var wantIDs []string
+	for _, payment := range []*models.Payment{first, second, testPayments[3], testPayments[0], testPayments[2], testPayments[5]} {
+		wantIDs = append(wantIDs, payment.ID.String())
+	}
+	sortBy := []SortField{{Name: "currency"}, {Name: "amount", Descending: true}}
+
+	checkIDs := func(t *testing.T, got []string) {
+		if len(got) != len(wantIDs) {
+			t.Fatalf("Want %d items but got %d", len(wantIDs), len(got))
+		}
+
+		for i := range wantIDs {
+			if got[i] != wantIDs[i] {
+				t.Errorf("Want payment %s at position %d but got %s", wantIDs[i], i, got[i])
+			}
+		}
+	}
+
+	t.Run("offset", func(t *testing.T) {
+		var got []string
+		for offset := int64(0); offset < int64(len(wantIDs)); offset += 4 {
+			page, err := testRepo.List(ctx, PaymentFilter{}, sortBy, Pagination{Offset: offset, Limit: 4})
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+			for _, payment := range page.Payments {
+				got = append(got, payment.ID.String())
+			}
+		}
+		checkIDs(t, got)
+	})
+
+	t.Run("after", func(t *testing.T) {
+		var got []string
+		pagination := Pagination{Limit: 2}
+		for {
+			page, err := testRepo.List(ctx, PaymentFilter{}, sortBy, pagination)
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+			for _, payment := range page.Payments {
+				got = append(got, payment.ID.String())
+			}
+			if !page.HasNext {
+				break
+			}
+			cursor := paymentCursor(page.Payments[len(page.Payments)-1], sortBy)
+			pagination.After = &cursor
+		}
+		checkIDs(t, got)
+	})
+
+	t.Run("before", func(t *testing.T) {
+		var got []string
+		pagination := Pagination{Offset: 4, Limit: 2}
+		for {
+			page, err := testRepo.List(ctx, PaymentFilter{}, sortBy, pagination)
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+			var ids []string
+			for _, payment := range page.Payments {
+				ids = append(ids, payment.ID.String())
+			}
+			got = append(ids, got...)
+			if !page.HasPrev {
+				break
+			}
+			cursor := paymentCursor(page.Payments[0], sortBy)
+			pagination.Before = &cursor
+		}
+		checkIDs(t, got)
+	})
+}
+
+func TestMemListFiltered(t *testing.T) {
+	orgID := strfmt.UUID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb")
+	otherOrgID := strfmt.UUID("5c1e3f4e-5f8a-4c6e-a1cb-9b8e7f1f6e2a")
+	testPayments := generateDummyPayments(4)
+	setAttrs := func(payment *models.Payment, org *strfmt.UUID, currency, amount string, day int) {
+		payment.OrganisationID = org
+		payment.Attributes.Currency = models.Currency(currency)
+		payment.Attributes.Amount = models.Amount(amount)
+		payment.Attributes.PaymentScheme = "FPS"
+		payment.Attributes.ProcessingDate = strfmt.Date(time.Date(2019, 1, day, 0, 0, 0, 0, time.UTC))
+	}
+	setAttrs(testPayments[0], &orgID, "GBP", "5.00", 1)
+	setAttrs(testPayments[1], &orgID, "GBP", "10.00", 10)
+	setAttrs(testPayments[2], &orgID, "EUR", "100.50", 20)
+	setAttrs(testPayments[3], &otherOrgID, "GBP", "9.99", 31)
+
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+	for _, payment := range testPayments {
+		if _, err := testRepo.Add(ctx, payment); err != nil {
+			t.Fatalf("Unexpected error adding payment: %v", err)
+		}
+	}
+
+	upperOrgID := strfmt.UUID(strings.ToUpper(orgID.String()))
+	gbp := models.Currency("GBP")
+	fps := "FPS"
+	bacs := "BACS"
+	from := strfmt.Date(time.Date(2019, 1, 10, 0, 0, 0, 0, time.UTC))
+	to := strfmt.Date(time.Date(2019, 1, 20, 0, 0, 0, 0, time.UTC))
+	minAmount := models.Amount("9.99")
+	maxAmount := models.Amount("10")
+
+	tests := map[string]struct {
+		filter  PaymentFilter
+		wantIdx []int
+	}{
+		"by organisation": {
+			filter:  PaymentFilter{OrganisationID: &upperOrgID},
+			wantIdx: []int{0, 1, 2},
+		},
+		"by currency": {
+			filter:  PaymentFilter{Currency: &gbp},
+			wantIdx: []int{0, 1, 3},
+		},
+		"by scheme": {
+			filter:  PaymentFilter{PaymentScheme: &fps},
+			wantIdx: []int{0, 1, 2, 3},
+		},
+		"by processing date range": {
+			filter:  PaymentFilter{ProcessingDateFrom: &from, ProcessingDateTo: &to},
+			wantIdx: []int{1, 2},
+		},
+		"by amount range": {
+			filter:  PaymentFilter{AmountFrom: &minAmount, AmountTo: &maxAmount},
+			wantIdx: []int{1, 3},
+		},
+		"combined": {
+			filter:  PaymentFilter{OrganisationID: &orgID, Currency: &gbp, AmountFrom: &minAmount},
+			wantIdx: []int{1},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			page, err := testRepo.List(ctx, tc.filter, nil, Pagination{Limit: 10})
+			if err != nil {
+				t.Fatalf("Unexpected error: %#v", err)
+			}
+
+			want := make(map[strfmt.UUID]bool)
+			for _, i := range tc.wantIdx {
+				want[*testPayments[i].ID] = true
+			}
+
+			if len(page.Payments) != len(want) {
+				t.Fatalf("Want %d items but got %d", len(want), len(page.Payments))
+			}
+
+			for _, payment := range page.Payments {
+				if !want[*payment.ID] {
+					t.Errorf("Payment %s should not have been listed", payment.ID)
+				}
+			}
+
+			count, err := testRepo.Count(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error counting payments: %v", err)
+			}
+			if count != int64(len(want)) {
+				t.Errorf("Want count %d but got %d", len(want), count)
+			}
+		})
+	}
+
+	_, err := testRepo.List(ctx, PaymentFilter{PaymentScheme: &bacs}, nil, Pagination{Limit: 10})
+	if _, ok := err.(ErrNoResults); !ok {
+		t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+	}
+}
+
+func TestMemListBadParams(t *testing.T) {
+	cursor := &Cursor{ID: *generateDummyPayments(1)[0].ID}
+	tests := map[string]Pagination{
+		"offset negative": {
+			Offset: -1,
+			Limit:  5,
+		},
+		"limit 0": {
+			Offset: 0,
+			Limit:  0,
+		},
+		"limit too high": {
+			Offset: 0,
+			Limit:  101,
+		},
+		"both cursors": {
+			Limit:  5,
+			After:  cursor,
+			Before: cursor,
+		},
+	}
+
+	for name, pagination := range tests {
+		t.Run(name, func(t *testing.T) {
+			testRepo := NewMemPaymentRepository()
+			ctx := context.Background()
+
+			_, err := testRepo.List(ctx, PaymentFilter{}, nil, pagination)
+			if _, ok := err.(ErrBadOffsetLimit); !ok {
+				t.Fatalf("Expected ErrBadOffsetLimit but got %T (%v)", err, err)
+			}
+		})
+	}
+}
+
+func TestMemListNoResults(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	_, err := testRepo.List(ctx, PaymentFilter{}, nil, Pagination{Limit: 10})
+	if _, ok := err.(ErrNoResults); !ok {
+		t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+	}
+}
+
+func TestMemUpdate(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	newDetails := copyPayment(testPayment)
+	newDetails.Type = TYPE_PAYMENT + "BAD"
+	newDetails.Attributes.Amount = "150.00"
+	updated, err := testRepo.Update(ctx, *testPayment.ID, newDetails)
+	if err != nil {
+		t.Fatalf("Unexpected error updating payment: %v", err)
+	}
+
+	if updated.Type != TYPE_PAYMENT {
+		t.Errorf("Wanted type to be %s but got %s", TYPE_PAYMENT, updated.Type)
+	}
+
+	if *updated.Version != *testPayment.Version+1 {
+		t.Errorf("Updated payment should have its version number incremented by one (want %d, got %d)",
+			*testPayment.Version+1, *updated.Version)
+	}
+
+	got, _ := testRepo.Get(ctx, *testPayment.ID)
+	if got.Attributes.Amount != newDetails.Attributes.Amount {
+		t.Errorf("Want amount %s but got %s", newDetails.Attributes.Amount, got.Attributes.Amount)
+	}
+}
+
+func TestMemUpdateNonExistent(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	_, err := testRepo.Update(ctx, *testPayment.ID, testPayment)
+	e, ok := err.(ErrNoResults)
+	if err == nil || !ok {
+		t.Errorf("Expected ErrNoResults but got %v", e)
+	}
+}
+
+func TestMemUpdateStaleVersion(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	// The first update is based on the current version, so it must succeed
+	if _, err := testRepo.Update(ctx, *testPayment.ID, testPayment); err != nil {
+		t.Fatalf("Unexpected error updating payment: %v", err)
+	}
+
+	// The second one is based on the same version, which is now stale
+	newDetails := copyPayment(testPayment)
+	newDetails.Attributes.Amount = "150.00"
+	_, err := testRepo.Update(ctx, *testPayment.ID, newDetails)
+	if _, ok := err.(ErrVersionMismatch); !ok {
+		t.Fatalf("Expected ErrVersionMismatch but got %T (%v)", err, err)
+	}
+
+	got, _ := testRepo.Get(ctx, *testPayment.ID)
+	if got.Attributes.Amount == newDetails.Attributes.Amount {
+		t.Error("Stale update was applied")
+	}
+
+	// Updates without a version are applied unconditionally
+	newDetails.Version = nil
+	if _, err := testRepo.Update(ctx, *testPayment.ID, newDetails); err != nil {
+		t.Errorf("Unexpected error updating payment without version: %v", err)
+	}
+}
+
+func TestMemPatch(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	original, _ := testRepo.Get(ctx, *testPayment.ID)
+	patched := copyPayment(original)
+	patched.Version = nil
+	patched.Attributes.Amount = "150.00"
+
+	// Details changed by others after the patched payment was fetched must be kept
+	concurrent := copyPayment(original)
+	concurrent.Attributes.Reference = "Concurrent reference"
+	if _, err := testRepo.Update(ctx, *testPayment.ID, concurrent); err != nil {
+		t.Fatalf("Unexpected error updating payment: %v", err)
+	}
+
+	updated, err := testRepo.Patch(ctx, *testPayment.ID, original, patched)
+	if err != nil {
+		t.Fatalf("Unexpected error patching payment: %v", err)
+	}
+
+	if *updated.Version != *original.Version+2 {
+		t.Errorf("Patched payment should have its version number incremented by one (want %d, got %d)",
+			*original.Version+2, *updated.Version)
+	}
+
+	got, _ := testRepo.Get(ctx, *testPayment.ID)
+	if got.Attributes.Amount != patched.Attributes.Amount {
+		t.Errorf("Want amount %s but got %s", patched.Attributes.Amount, got.Attributes.Amount)
+	}
+	if got.Attributes.Reference != concurrent.Attributes.Reference {
+		t.Errorf("Want reference %q but got %q", concurrent.Attributes.Reference, got.Attributes.Reference)
+	}
+
+	// Patches based on a stale version must be rejected
+	patched.Version = original.Version
+	_, err = testRepo.Patch(ctx, *testPayment.ID, original, patched)
+	if _, ok := err.(ErrVersionMismatch); !ok {
+		t.Errorf("Expected ErrVersionMismatch but got %T (%v)", err, err)
+	}
+}
+
+func TestMemPatchNonExistent(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	_, err := testRepo.Patch(ctx, *testPayment.ID, testPayment, testPayment)
+	e, ok := err.(ErrNoResults)
+	if err == nil || !ok {
+		t.Errorf("Expected ErrNoResults but got %v", e)
+	}
+}
+
+func TestMemVersions(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	newDetails := copyPayment(testPayment)
+	newDetails.Attributes.Amount = "150.00"
+	if _, err := testRepo.Update(ctx, *testPayment.ID, newDetails); err != nil {
+		t.Fatalf("Unexpected error updating payment: %v", err)
+	}
+
+	if _, err := testRepo.Delete(ctx, *testPayment.ID); err != nil {
+		t.Fatalf("Unexpected error deleting payment: %v", err)
+	}
+
+	// History must survive the deletion of the payment
+	versions, err := testRepo.ListVersions(ctx, *testPayment.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing versions: %v", err)
+	}
+
+	wantOperations := []string{
+		models.PaymentVersionOperationCreate,
+		models.PaymentVersionOperationUpdate,
+		models.PaymentVersionOperationDelete,
+	}
+	if len(versions) != len(wantOperations) {
+		t.Fatalf("Want %d versions but got %d", len(wantOperations), len(versions))
+	}
+
+	for i, version := range versions {
+		if *version.Version != int64(i) {
+			t.Errorf("Want version %d at position %d but got %d", i, i, *version.Version)
+		}
+
+		if *version.Operation != wantOperations[i] {
+			t.Errorf("Want operation %s for version %d but got %s", wantOperations[i], i, *version.Operation)
+		}
+	}
+
+	got, err := testRepo.GetVersion(ctx, *testPayment.ID, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error getting version: %v", err)
+	}
+
+	if got.Data.Attributes.Amount != testPayment.Attributes.Amount {
+		t.Errorf("Want amount %s in version 0 but got %s", testPayment.Attributes.Amount, got.Data.Attributes.Amount)
+	}
+
+	// Modifying a returned version must not modify the stored one
+	got.Data.Attributes.Amount = "1000.00"
+	again, _ := testRepo.GetVersion(ctx, *testPayment.ID, 0)
+	if again.Data.Attributes.Amount == got.Data.Attributes.Amount {
+		t.Error("Stored version was modified through a returned value")
+	}
+}
+
+func TestMemVersionsNonExistent(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+
+	testPayment := generateDummyPayments(1)[0]
+	if _, err := testRepo.ListVersions(ctx, *testPayment.ID); err == nil {
+		t.Error("Expected an error listing versions of a non-existent payment")
+	}
+
+	if _, err := testRepo.Add(ctx, testPayment); err != nil {
+		t.Fatalf("Unexpected error adding payment: %v", err)
+	}
+
+	_, err := testRepo.GetVersion(ctx, *testPayment.ID, 1)
+	if _, ok := err.(ErrNoResults); !ok {
+		t.Errorf("Expected ErrNoResults but got %T (%v)", err, err)
+	}
+}
+
+func TestMemConcurrentAccess(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	ctx := context.Background()
+	testPayments := generateDummyPayments(50)
+
+	var wg sync.WaitGroup
+	for _, payment := range testPayments {
+		wg.Add(1)
+		go func(payment *models.Payment) {
+			defer wg.Done()
+			if _, err := testRepo.Add(ctx, payment); err != nil {
+				t.Errorf("Unexpected error adding payment: %v", err)
+			}
+			if _, err := testRepo.Update(ctx, *payment.ID, payment); err != nil {
+				t.Errorf("Unexpected error updating payment: %v", err)
+			}
+			if _, err := testRepo.List(ctx, PaymentFilter{}, nil, Pagination{Limit: 10}); err != nil {
+				t.Errorf("Unexpected error listing payments: %v", err)
+			}
+		}(payment)
+	}
+	wg.Wait()
+
+	page, err := testRepo.List(ctx, PaymentFilter{}, nil, Pagination{Limit: 100})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page.Payments) != len(testPayments) {
+		t.Errorf("Want %d items but got %d", len(testPayments), len(page.Payments))
+	}
+}
+
+func TestMemTenancy(t *testing.T) {
+	testRepo := NewMemPaymentRepository()
+	testPayments := generateDummyPayments(2)
+	ownOrg := strfmt.UUID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb")
+	otherOrg := strfmt.UUID("2f1f6f9e-6b0c-4a3e-9d55-5b1f4d4c3e2a")
+	testPayments[0].OrganisationID = &ownOrg
+	testPayments[1].OrganisationID = &otherOrg
+
+	// Payments are added without a principal, as maintenance tasks would do
+	for _, payment := range testPayments {
+		if _, err := testRepo.Add(context.Background(), payment); err != nil {
+			t.Fatalf("Unexpected error adding payment: %v", err)
+		}
+	}
+
+	principal := &Principal{Subject: "client", Organisations: []strfmt.UUID{ownOrg}}
+	ctx := context.WithValue(context.Background(), restapi.AuthKey, principal)
+	own, other := testPayments[0], testPayments[1]
+
+	if _, err := testRepo.Get(ctx, *own.ID); err != nil {
+		t.Errorf("Unexpected error getting own payment: %v", err)
+	}
+	if _, err := testRepo.Get(ctx, *other.ID); !isErrNoResults(err) {
+		t.Errorf("Expected ErrNoResults getting other payment but got %v", err)
+	}
+	// Payments of other organisations can't be overwritten, even with a body
+	// for the caller's organisation
+	hijack := copyPayment(other)
+	hijack.OrganisationID = &ownOrg
+	if _, err := testRepo.Update(ctx, *other.ID, hijack); !isErrNoResults(err) {
+		t.Errorf("Expected ErrNoResults updating other payment but got %v", err)
+	}
+	if _, err := testRepo.ListVersions(ctx, *other.ID); !isErrNoResults(err) {
+		t.Errorf("Expected ErrNoResults listing versions of other payment but got %v", err)
+	}
+	if _, err := testRepo.Delete(ctx, *other.ID); !isErrNoResults(err) {
+		t.Errorf("Expected ErrNoResults deleting other payment but got %v", err)
+	}
+
+	page, err := testRepo.List(ctx, PaymentFilter{}, nil, Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("Unexpected error listing payments: %v", err)
+	}
+	if len(page.Payments) != 1 || *page.Payments[0].ID != *own.ID {
+		t.Errorf("Want only payment %s but got %v", *own.ID, page.Payments)
+	}
+	count, err := testRepo.Count(ctx, PaymentFilter{})
+	if err != nil {
+		t.Fatalf("Unexpected error counting payments: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Want count 1 but got %d", count)
+	}
+
+	// Payments can't be written for, or moved to, other organisations
+	newPayment := generateDummyPayments(1)[0]
+	newPayment.OrganisationID = &otherOrg
+	if _, err := testRepo.Add(ctx, newPayment); !isErrForbidden(err) {
+		t.Errorf("Expected ErrForbidden adding payment but got %v", err)
+	}
+	if _, err := testRepo.AddBatch(ctx, []*models.Payment{newPayment}, false); !isErrForbidden(err) {
+		t.Errorf("Expected ErrForbidden adding batch but got %v", err)
+	}
+	moved := copyPayment(own)
+	moved.OrganisationID = &otherOrg
+	if _, err := testRepo.Update(ctx, *own.ID, moved); !isErrForbidden(err) {
+		t.Errorf("Expected ErrForbidden moving payment but got %v", err)
+	}
+}
+
+func isErrNoResults(err error) bool {
+	_, ok := err.(ErrNoResults)
+	return ok
+}
+
+func isErrForbidden(err error) bool {
+	_, ok := err.(ErrForbidden)
+	return ok
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// applyMergePatch applies a JSON Merge Patch, as defined in RFC 7386, to a
+// document. Both the document and the patch are generic JSON values, as
+// decoded by encoding/json into an interface{}. The document is not modified
+func applyMergePatch(doc, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		// A patch that is not an object replaces the whole document
+		return patch
+	}
+
+	docObj, ok := doc.(map[string]interface{})
+	if !ok {
+		docObj = make(map[string]interface{})
+	}
+
+	merged := make(map[string]interface{}, len(docObj))
+	for name, value := range docObj {
+		merged[name] = value
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(merged, name)
+			continue
+		}
+
+		merged[name] = applyMergePatch(merged[name], value)
+	}
+
+	return merged
+}
+
+// createMergePatch returns the JSON Merge Patch that turns the original
+// document into the modified one. Both documents are generic JSON values, as
+// decoded by encoding/json into an interface{}
+func createMergePatch(original, modified interface{}) interface{} {
+	originalObj, ok := original.(map[string]interface{})
+	if !ok {
+		return modified
+	}
+	modifiedObj, ok := modified.(map[string]interface{})
+	if !ok {
+		return modified
+	}
+
+	patch := make(map[string]interface{})
+	for name := range originalObj {
+		if _, ok := modifiedObj[name]; !ok {
+			patch[name] = nil
+		}
+	}
+	for name, value := range modifiedObj {
+		originalValue, ok := originalObj[name]
+		if ok && reflect.DeepEqual(originalValue, value) {
+			continue
+		}
+
+		patch[name] = createMergePatch(originalValue, value)
+	}
+
+	return patch
+}
+
+// toJSONValue converts v into a generic JSON value by encoding and decoding it.
+// Numbers are kept as json.Number so that they don't lose precision
+func toJSONValue(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// fromJSONValue stores a generic JSON value in the value pointed to by v
+func fromJSONValue(value interface{}, v interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
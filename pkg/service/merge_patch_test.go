@@ -0,0 +1,73 @@
+// +build !integration
+
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7386, appendix A
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tc := range tests {
+		var doc, patch, want interface{}
+		mustUnmarshal(t, tc.doc, &doc)
+		mustUnmarshal(t, tc.patch, &patch)
+		mustUnmarshal(t, tc.want, &want)
+
+		got := applyMergePatch(doc, patch)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Applying %s to %s: got %v, want %v", tc.patch, tc.doc, got, want)
+		}
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		original, modified string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`},
+		{`{"a":"b","b":"c"}`, `{"b":"c"}`},
+		{`{"a":{"b":"c","d":"e"}}`, `{"a":{"b":"f"}}`},
+		{`{"a":["b"]}`, `{"a":["b","c"]}`},
+		{`{"a":"b"}`, `{"a":"b"}`},
+	}
+
+	for _, tc := range tests {
+		var original, modified interface{}
+		mustUnmarshal(t, tc.original, &original)
+		mustUnmarshal(t, tc.modified, &modified)
+
+		patch := createMergePatch(original, modified)
+		if got := applyMergePatch(original, patch); !reflect.DeepEqual(got, modified) {
+			t.Errorf("Patch %v turns %s into %v, want %s", patch, tc.original, got, tc.modified)
+		}
+	}
+}
+
+func mustUnmarshal(t *testing.T, raw string, v interface{}) {
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		t.Fatalf("Malformed JSON %s: %v", raw, err)
+	}
+}
@@ -0,0 +1,4 @@
+DROP TRIGGER record_payment_version ON payments;
+DROP FUNCTION record_payment_version();
+
+DROP TABLE payment_versions;
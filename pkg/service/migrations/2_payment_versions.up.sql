@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS payment_versions (
+    seq             BIGSERIAL PRIMARY KEY,
+    operation       TEXT NOT NULL,
+    recorded_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    LIKE payments
+);
+
+CREATE INDEX payment_versions_id_idx ON payment_versions (id, seq);
+
+-- Every change to a payment is recorded as a new entry in payment_versions.
+-- Deleting a payment records a final version that holds the details the
+-- payment had when it was deleted
+CREATE FUNCTION record_payment_version() RETURNS TRIGGER AS $$
+DECLARE
+    recorded    payments%ROWTYPE;
+    op          TEXT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        recorded := OLD;
+        recorded.version := OLD.version + 1;
+        op := 'delete';
+    ELSIF TG_OP = 'UPDATE' THEN
+        recorded := NEW;
+        op := 'update';
+    ELSE
+        recorded := NEW;
+        op := 'create';
+    END IF;
+
+    INSERT INTO payment_versions
+    SELECT nextval(pg_get_serial_sequence('payment_versions', 'seq')), op, now(), (recorded).*;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_payment_version
+    AFTER INSERT OR UPDATE OR DELETE ON payments
+    FOR EACH ROW EXECUTE PROCEDURE record_payment_version();
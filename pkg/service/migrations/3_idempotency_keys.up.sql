@@ -0,0 +1,10 @@
+-- Responses to requests that carry an idempotency key are kept until they
+-- expire, so that they can be replayed when the request is retried
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key             TEXT PRIMARY KEY,
+    fingerprint     TEXT NOT NULL,
+    response        BYTEA NOT NULL,
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
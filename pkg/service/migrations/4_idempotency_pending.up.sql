@@ -0,0 +1,3 @@
+-- Keys are reserved, without a response, before their requests are handled,
+-- so that concurrent retries don't handle the same request twice
+ALTER TABLE idempotency_keys ALTER COLUMN response DROP NOT NULL;
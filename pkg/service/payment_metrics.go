@@ -0,0 +1,162 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/volmedo/pAPI/pkg/models"
+)
+
+const (
+	// otherLabelValue replaces the values of a label once its limit is reached
+	otherLabelValue = "other"
+
+	// unknownLabelValue replaces the values of a label that are missing
+	unknownLabelValue = "unknown"
+)
+
+// PaymentMetricsConfig holds the limits to the cardinality of the labels of the
+// payment metrics. Once the limit of a label is reached, new values are
+// replaced by "other". A limit of 0 means that there is no limit
+type PaymentMetricsConfig struct {
+	// MaxOrganisations is the number of distinct organisations payments are labelled with
+	MaxOrganisations int
+
+	// MaxLabelValues is the number of distinct values each of the payment_scheme,
+	// currency and scheme_payment_type labels can take
+	MaxLabelValues int
+}
+
+// PaymentMetrics records the payments created, updated and deleted through the
+// service as Prometheus metrics, labelled by organisation, payment scheme,
+// currency and scheme payment type. Their amounts are recorded by currency.
+//
+// A nil *PaymentMetrics records nothing
+type PaymentMetrics struct {
+	events  *prometheus.CounterVec
+	amounts *prometheus.HistogramVec
+
+	organisations *labelLimiter
+	schemes       *labelLimiter
+	currencies    *labelLimiter
+	types         *labelLimiter
+}
+
+// NewPaymentMetrics creates PaymentMetrics with the given limits and registers them with reg
+func NewPaymentMetrics(reg prometheus.Registerer, conf PaymentMetricsConfig) (*PaymentMetrics, error) {
+	pm := &PaymentMetrics{
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pAPI",
+			Subsystem: "payments",
+			Name:      "events_total",
+			Help:      "The number of payments created, updated and deleted.",
+		}, []string{"event", "organisation", "payment_scheme", "currency", "scheme_payment_type"}),
+		amounts: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "pAPI",
+			Subsystem: "payments",
+			Name:      "amount",
+			Help:      "The amounts of the payments created, updated and deleted, in units of their currency.",
+			Buckets:   prometheus.ExponentialBuckets(1, 10, 8),
+		}, []string{"event", "currency"}),
+		organisations: newLabelLimiter(conf.MaxOrganisations),
+		schemes:       newLabelLimiter(conf.MaxLabelValues),
+		currencies:    newLabelLimiter(conf.MaxLabelValues),
+		types:         newLabelLimiter(conf.MaxLabelValues),
+	}
+
+	for _, c := range []prometheus.Collector{pm.events, pm.amounts} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return pm, nil
+}
+
+// Created records a payment that has been created
+func (pm *PaymentMetrics) Created(payment *models.Payment) {
+	pm.record("created", payment)
+}
+
+// Updated records a payment that has been updated, with its new details
+func (pm *PaymentMetrics) Updated(payment *models.Payment) {
+	pm.record("updated", payment)
+}
+
+// Deleted records a payment that has been deleted. The payment may be nil if
+// its details are not known, then it is recorded with unknown labels
+func (pm *PaymentMetrics) Deleted(payment *models.Payment) {
+	pm.record("deleted", payment)
+}
+
+// record records an event that happened to payment
+func (pm *PaymentMetrics) record(event string, payment *models.Payment) {
+	if pm == nil {
+		return
+	}
+
+	var org, scheme, currency, schemeType, amount string
+	if payment != nil {
+		if payment.OrganisationID != nil {
+			org = payment.OrganisationID.String()
+		}
+		if attrs := payment.Attributes; attrs != nil {
+			scheme = attrs.PaymentScheme
+			currency = string(attrs.Currency)
+			schemeType = attrs.SchemePaymentType
+			amount = string(attrs.Amount)
+		}
+	}
+
+	currency = pm.currencies.value(currency)
+	pm.events.WithLabelValues(event,
+		pm.organisations.value(org),
+		pm.schemes.value(scheme),
+		currency,
+		pm.types.value(schemeType),
+	).Inc()
+
+	if value, err := strconv.ParseFloat(amount, 64); err == nil {
+		pm.amounts.WithLabelValues(event, currency).Observe(value)
+	}
+}
+
+// labelLimiter limits the number of distinct values a label can take
+type labelLimiter struct {
+	max int
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// newLabelLimiter creates a labelLimiter that allows up to max distinct
+// values, or any number of them if max is 0
+func newLabelLimiter(max int) *labelLimiter {
+	return &labelLimiter{max: max, seen: make(map[string]struct{})}
+}
+
+// value returns the value a label must take instead of v, which is v itself
+// unless it is missing or the limit of values has been reached
+func (ll *labelLimiter) value(v string) string {
+	if v == "" {
+		return unknownLabelValue
+	}
+	if ll.max <= 0 {
+		return v
+	}
+
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	if _, ok := ll.seen[v]; ok {
+		return v
+	}
+	if len(ll.seen) >= ll.max {
+		return otherLabelValue
+	}
+
+	ll.seen[v] = struct{}{}
+	return v
+}
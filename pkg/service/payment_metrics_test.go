@@ -0,0 +1,86 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/volmedo/pAPI/pkg/restapi/operations/payments"
+)
+
+func TestPaymentMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics, err := NewPaymentMetrics(reg, PaymentMetricsConfig{MaxOrganisations: 1})
+	if err != nil {
+		t.Fatalf("Error creating payment metrics: %v", err)
+	}
+
+	orgs := []strfmt.UUID{"743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb", "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"}
+	for i, payment := range generateDummyPayments(2) {
+		payment.OrganisationID = &orgs[i]
+		payment.Attributes.Amount = "10.25"
+		payment.Attributes.Currency = "GBP"
+		payment.Attributes.PaymentScheme = "FPS"
+		payment.Attributes.SchemePaymentType = "ImmediatePayment"
+		metrics.Created(payment)
+	}
+	metrics.Deleted(nil)
+
+	want := `
+		# HELP pAPI_payments_events_total The number of payments created, updated and deleted.
+		# TYPE pAPI_payments_events_total counter
+		pAPI_payments_events_total{currency="GBP",event="created",organisation="743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb",payment_scheme="FPS",scheme_payment_type="ImmediatePayment"} 1
+		pAPI_payments_events_total{currency="GBP",event="created",organisation="other",payment_scheme="FPS",scheme_payment_type="ImmediatePayment"} 1
+		pAPI_payments_events_total{currency="unknown",event="deleted",organisation="unknown",payment_scheme="unknown",scheme_payment_type="unknown"} 1
+	`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "pAPI_payments_events_total"); err != nil {
+		t.Error(err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Error gathering metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "pAPI_payments_amount" {
+			continue
+		}
+		if got := family.GetMetric()[0].GetHistogram().GetSampleSum(); got != 20.5 {
+			t.Errorf("Want a total amount of 20.5 GBP but got %v", got)
+		}
+	}
+}
+
+func TestDeletePaymentMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics, err := NewPaymentMetrics(reg, PaymentMetricsConfig{})
+	if err != nil {
+		t.Fatalf("Error creating payment metrics: %v", err)
+	}
+
+	papi := &PaymentsService{Repo: NewMemPaymentRepository(), Metrics: metrics}
+	payment := generateDummyPayments(1)[0]
+	orgID := strfmt.UUID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb")
+	payment.OrganisationID = &orgID
+	payment.Attributes.Currency = "EUR"
+	if _, err := papi.Repo.Add(context.Background(), payment); err != nil {
+		t.Fatalf("Error adding payment: %v", err)
+	}
+
+	resp := papi.DeletePayment(context.Background(), payments.DeletePaymentParams{ID: *payment.ID})
+	if _, ok := resp.(*payments.DeletePaymentNoContent); !ok {
+		t.Fatalf("Want the payment deleted but got %T", resp)
+	}
+
+	// The payment was fetched before deleting it to label the metric
+	deleted := metrics.events.WithLabelValues("deleted", orgID.String(), unknownLabelValue, "EUR", unknownLabelValue)
+	if got := testutil.ToFloat64(deleted); got != 1 {
+		t.Errorf("Want 1 deleted payment in EUR but got %v", got)
+	}
+}
@@ -2,15 +2,26 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
 	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
 
 	"github.com/volmedo/pAPI/pkg/models"
 	"github.com/volmedo/pAPI/pkg/restapi/operations/payments"
+	"github.com/volmedo/pAPI/pkg/tracing"
 )
 
 // PaymentsService implements the business logic needed to fulfill the API's requirements
@@ -18,124 +29,982 @@ type PaymentsService struct {
 	// Repo is a repository for payments
 	Repo PaymentRepository
 
-	// Logger will be use to write logs. Only unexpected errors will be logged
-	Logger *log.Logger
+	// Idempotency keeps the responses to create requests that carry an
+	// Idempotency-Key header. The header is ignored if Idempotency is nil
+	Idempotency IdempotencyStore
+
+	// BaseURL is the public URL of the API, such as https://api.example.com/v1.
+	// Links in responses are built from it, as the URL requests are sent to may
+	// not be reachable by clients (e.g. when the service is behind a proxy).
+	// If it is nil, links are built from the scheme and host of each request
+	// and the base path of the API
+	BaseURL *url.URL
+
+	// Metrics records the payments created, updated and deleted. Nothing is
+	// recorded if Metrics is nil
+	Metrics *PaymentMetrics
+
+	// Logger will be use to write logs. Only unexpected errors will be logged,
+	// along with the ID of the request they happened in
+	Logger logrus.FieldLogger
 }
 
-// CreatePayment Adds a new payment with the data included in params
+// CreatePayment Adds a new payment with the data included in params. A new ID
+// is generated for the payment if params don't include it.
+//
+// If the request carries an idempotency key, the key is reserved before the
+// payment is created and the response is saved afterwards, so that retries of
+// the request get it again instead of creating the payment twice
 func (papi *PaymentsService) CreatePayment(ctx context.Context, params payments.CreatePaymentParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.CreatePayment")
+	defer span.End()
+
+	if params.IdempotencyKey == nil || papi.Idempotency == nil {
+		resp, responder := papi.createPayment(ctx, params)
+		if resp == nil {
+			return responder
+		}
+		return payments.NewCreatePaymentCreated().WithLocation(resp.Links.Self).WithPayload(resp)
+	}
+
+	key := *params.IdempotencyKey
+	fingerprint, err := requestFingerprint(params.PaymentCreationRequest)
+	if err != nil {
+		return payments.NewCreatePaymentInternalServerError().WithPayload(papi.internalError(ctx, "CreatePayment", err))
+	}
+
+	if responder := papi.reserveCreatePayment(ctx, key, fingerprint); responder != nil {
+		return responder
+	}
+
+	resp, responder := papi.createPayment(ctx, params)
+	if resp == nil {
+		// Only successful responses are kept, so that requests that fail can
+		// be retried with the same key
+		if err := papi.Idempotency.Release(ctx, idempotencyKey(ctx, key)); err != nil {
+			papi.logger(ctx).WithError(err).Error("Error on CreatePayment")
+		}
+		return responder
+	}
+
+	// The payment has been created anyway, so failing to save the response
+	// only means that retries will get a conflict until the key expires
+	if err := papi.saveCreatePayment(ctx, key, fingerprint, resp); err != nil {
+		papi.logger(ctx).WithError(err).Error("Error on CreatePayment")
+	}
+
+	return payments.NewCreatePaymentCreated().WithLocation(resp.Links.Self).WithPayload(resp)
+}
+
+// createPayment adds the payment included in params to the repository and
+// returns the response to send. If the payment can't be created, the response
+// is nil and the returned responder holds the error to send instead
+func (papi *PaymentsService) createPayment(ctx context.Context, params payments.CreatePaymentParams) (*models.PaymentCreationResponse, middleware.Responder) {
 	payment := params.PaymentCreationRequest.Data
-	created, err := papi.Repo.Add(payment)
+	if payment.ID == nil {
+		newID, err := uuid.NewV4()
+		if err != nil {
+			return nil, payments.NewCreatePaymentInternalServerError().WithPayload(papi.internalError(ctx, "CreatePayment", err))
+		}
+
+		// Don't modify the request params, they belong to the caller
+		payment = copyPayment(payment)
+		paymentID := strfmt.UUID(newID.String())
+		payment.ID = &paymentID
+	}
+
+	created, err := papi.Repo.Add(ctx, payment)
 	if err != nil {
-		apiError := newAPIError(err.Error())
+		apiError := repoAPIError(ctx, err)
+		if _, ok := err.(ErrForbidden); ok {
+			return nil, payments.NewCreatePaymentForbidden().WithPayload(apiError)
+		}
+
 		if _, ok := err.(ErrConflict); ok {
-			return payments.NewCreatePaymentConflict().WithPayload(apiError)
+			return nil, payments.NewCreatePaymentConflict().WithPayload(apiError)
 		}
 
-		papi.Logger.Printf("Error on CreatePayment: %v", err)
-		return payments.NewCreatePaymentInternalServerError().WithPayload(apiError)
+		return nil, payments.NewCreatePaymentInternalServerError().WithPayload(papi.internalError(ctx, "CreatePayment", err))
 	}
+	papi.Metrics.Created(created)
 
 	links := &models.Links{
-		Self: fmt.Sprintf("%s/%s", params.HTTPRequest.URL.Path, created.ID),
+		Self: papi.link(params.HTTPRequest, &payments.GetPaymentURL{ID: *created.ID}),
+	}
+	return &models.PaymentCreationResponse{Data: created, Links: links}, nil
+}
+
+// reserveCreatePayment reserves the given idempotency key for a create request
+// with the given fingerprint. If the key has already been used, the response
+// to send to the request is returned. A nil responder is returned if the key
+// has been reserved and the request must be handled as a new one
+func (papi *PaymentsService) reserveCreatePayment(ctx context.Context, key, fingerprint string) middleware.Responder {
+	record := &IdempotencyRecord{Key: idempotencyKey(ctx, key), Fingerprint: fingerprint}
+	err := papi.Idempotency.Reserve(ctx, record)
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(ErrConflict); !ok {
+		return payments.NewCreatePaymentInternalServerError().WithPayload(papi.internalError(ctx, "CreatePayment", err))
+	}
+
+	return papi.replayCreatePayment(ctx, key, fingerprint)
+}
+
+// replayCreatePayment returns the response to send to a create request with the
+// given idempotency key and fingerprint, which has already been used
+func (papi *PaymentsService) replayCreatePayment(ctx context.Context, key, fingerprint string) middleware.Responder {
+	inProgress := func() middleware.Responder {
+		msg := fmt.Sprintf("a request with idempotency key %q is still being processed, retry it later", key)
+		return payments.NewCreatePaymentConflict().WithPayload(newAPIError(ctx, http.StatusConflict, codeConflict, msg))
+	}
+
+	record, err := papi.Idempotency.Get(ctx, idempotencyKey(ctx, key))
+	if err != nil {
+		// The request that reserved the key failed and released it
+		// after this one tried to reserve it
+		if _, ok := err.(ErrNoResults); ok {
+			return inProgress()
+		}
+
+		return payments.NewCreatePaymentInternalServerError().WithPayload(papi.internalError(ctx, "CreatePayment", err))
+	}
+
+	if record.Fingerprint != fingerprint {
+		msg := fmt.Sprintf("idempotency key %q has already been used with a different request", key)
+		return payments.NewCreatePaymentUnprocessableEntity().WithPayload(newAPIError(ctx, http.StatusUnprocessableEntity, codeInvalid, msg))
+	}
+
+	if record.Pending {
+		return inProgress()
+	}
+
+	var resp models.PaymentCreationResponse
+	if err := json.Unmarshal(record.Response, &resp); err != nil {
+		return payments.NewCreatePaymentInternalServerError().WithPayload(papi.internalError(ctx, "CreatePayment", err))
+	}
+
+	return payments.NewCreatePaymentCreated().WithLocation(resp.Links.Self).WithPayload(&resp)
+}
+
+// idempotencyKey returns the key a response is saved under for the idempotency
+// key sent by a client. Keys are scoped to the principal that sent the request,
+// so that clients can't get responses sent to other clients by reusing their keys
+func idempotencyKey(ctx context.Context, key string) string {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return key
+	}
+
+	return principal.Subject + ":" + key
+}
+
+// saveCreatePayment saves the response sent to a create request with the given
+// idempotency key and fingerprint, which must have been reserved beforehand
+func (papi *PaymentsService) saveCreatePayment(ctx context.Context, key, fingerprint string, resp *models.PaymentCreationResponse) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	record := &IdempotencyRecord{Key: idempotencyKey(ctx, key), Fingerprint: fingerprint, Response: raw}
+	return papi.Idempotency.Complete(ctx, record)
+}
+
+// BulkCreatePayments creates several payments with a single request
+//
+// Atomic requests create every payment or none of them. Otherwise, valid
+// payments are created and the rest are reported as failed
+func (papi *PaymentsService) BulkCreatePayments(ctx context.Context, params payments.BulkCreatePaymentsParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.BulkCreatePayments")
+	defer span.End()
+
+	atomic := params.Atomic == nil || *params.Atomic
+	items := params.PaymentBulkCreationRequest.Data
+	results := make([]*models.PaymentBulkCreationResult, len(items))
+
+	// Payments that can be sent to the repository, along with their positions
+	// in the request
+	batch := make([]*models.Payment, 0, len(items))
+	positions := make([]int, 0, len(items))
+	for i, item := range items {
+		payment, err := bulkPayment(item, fmt.Sprintf("data.%d", i))
+		if errs, ok := err.(invalidDocumentError); ok {
+			results[i] = newFailedBulkResult(i, http.StatusUnprocessableEntity, invalidAPIError(ctx, errs))
+			continue
+		}
+		if err != nil {
+			return payments.NewBulkCreatePaymentsInternalServerError().WithPayload(papi.internalError(ctx, "BulkCreatePayments", err))
+		}
+
+		// The repository would reject the whole batch because of this payment
+		if err := checkOrganisation(ctx, payment); err != nil {
+			apiError := newAPIError(ctx, http.StatusForbidden, codeForbidden, err.Error())
+			results[i] = newFailedBulkResult(i, http.StatusForbidden, apiError)
+			continue
+		}
+
+		if payment.ID == nil {
+			newID, err := uuid.NewV4()
+			if err != nil {
+				return payments.NewBulkCreatePaymentsInternalServerError().WithPayload(papi.internalError(ctx, "BulkCreatePayments", err))
+			}
+
+			paymentID := strfmt.UUID(newID.String())
+			payment.ID = &paymentID
+		}
+
+		batch = append(batch, payment)
+		positions = append(positions, i)
+	}
+
+	if atomic && len(batch) < len(items) {
+		failBulkResults(ctx, results)
+		return payments.NewBulkCreatePaymentsUnprocessableEntity().WithPayload(newBulkResponse(results))
+	}
+
+	created := []*models.Payment{}
+	if len(batch) > 0 {
+		var err error
+		created, err = papi.Repo.AddBatch(ctx, batch, atomic)
+		if err != nil {
+			conflict, ok := err.(ErrBatchConflict)
+			if !ok {
+				return payments.NewBulkCreatePaymentsInternalServerError().WithPayload(papi.internalError(ctx, "BulkCreatePayments", err))
+			}
+
+			for _, index := range conflict.Indexes {
+				msg := fmt.Sprintf("A payment with ID %s already exists or appears earlier in the request", *batch[index].ID)
+				apiError := newAPIError(ctx, http.StatusConflict, codeConflict, msg)
+				results[positions[index]] = newFailedBulkResult(positions[index], http.StatusConflict, apiError)
+			}
+
+			if atomic {
+				failBulkResults(ctx, results)
+				return payments.NewBulkCreatePaymentsConflict().WithPayload(newBulkResponse(results))
+			}
+		}
+	}
+
+	for index, payment := range created {
+		if payment == nil {
+			continue
+		}
+		papi.Metrics.Created(payment)
+
+		result := newBulkResult(positions[index], http.StatusCreated)
+		result.Data = payment
+		result.Links = &models.Links{
+			Self: papi.link(params.HTTPRequest, &payments.GetPaymentURL{ID: *payment.ID}),
+		}
+		results[positions[index]] = result
+	}
+
+	resp := newBulkResponse(results)
+	if *resp.Meta.Failed > 0 {
+		return payments.NewBulkCreatePaymentsMultiStatus().WithPayload(resp)
+	}
+
+	return payments.NewBulkCreatePaymentsCreated().WithPayload(resp)
+}
+
+// bulkPayment decodes and validates one of the payments of a bulk creation
+// request. Each payment is validated as if it were created on its own. The
+// problems found are named after the path to the invalid field, prefixed with
+// path, which is the path to the payment in the request
+func bulkPayment(item interface{}, path string) (*models.Payment, error) {
+	// Items are validated as generic JSON values, as they are sent
+	value, err := toJSONValue(item)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := definitionErrors("Payment", path, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, invalidDocumentError(errs)
+	}
+
+	var payment models.Payment
+	if err := fromJSONValue(value, &payment); err != nil {
+		return nil, err
+	}
+
+	req := &models.PaymentCreationRequest{Data: &payment}
+	if err := req.Validate(strfmt.Default); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// newBulkResult builds the result of one of the payments of a bulk request
+func newBulkResult(index, status int) *models.PaymentBulkCreationResult {
+	i, st := int64(index), int64(status)
+	return &models.PaymentBulkCreationResult{Index: &i, Status: &st}
+}
+
+// newFailedBulkResult builds the result of a payment of a bulk request that
+// could not be created
+func newFailedBulkResult(index, status int, apiError *models.APIError) *models.PaymentBulkCreationResult {
+	result := newBulkResult(index, status)
+	result.Error = apiError
+	return result
+}
+
+// failBulkResults marks every payment without a result as failed because
+// other payments in the same atomic request failed
+func failBulkResults(ctx context.Context, results []*models.PaymentBulkCreationResult) {
+	msg := "Payment was not created because other payments in the request failed"
+	for i := range results {
+		if results[i] == nil {
+			apiError := newAPIError(ctx, http.StatusFailedDependency, codeFailedDependency, msg)
+			results[i] = newFailedBulkResult(i, http.StatusFailedDependency, apiError)
+		}
+	}
+}
+
+// newBulkResponse builds the response to a bulk creation request, counting
+// the payments that were created and those that failed
+func newBulkResponse(results []*models.PaymentBulkCreationResult) *models.PaymentBulkCreationResponse {
+	var created, failed int64
+	for _, result := range results {
+		if *result.Status == http.StatusCreated {
+			created++
+		} else {
+			failed++
+		}
+	}
+
+	return &models.PaymentBulkCreationResponse{
+		Data: results,
+		Meta: &models.PaymentBulkCreationMeta{Created: &created, Failed: &failed},
 	}
-	resp := &models.PaymentCreationResponse{Data: created, Links: links}
-	return payments.NewCreatePaymentCreated().WithPayload(resp)
 }
 
 // DeletePayment Deletes a payment identified by its ID
 func (papi *PaymentsService) DeletePayment(ctx context.Context, params payments.DeletePaymentParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.DeletePayment")
+	defer span.End()
+
 	paymentID := params.ID
-	err := papi.Repo.Delete(paymentID)
+	deleted, err := papi.Repo.Delete(ctx, paymentID)
 	if err != nil {
-		apiError := newAPIError(err.Error())
+		apiError := repoAPIError(ctx, err)
 		if _, ok := err.(ErrNoResults); ok {
 			return payments.NewDeletePaymentNotFound().WithPayload(apiError)
 		}
 
-		papi.Logger.Printf("Error on DeletePayment: %v", err)
-		return payments.NewDeletePaymentInternalServerError().WithPayload(apiError)
+		return payments.NewDeletePaymentInternalServerError().WithPayload(papi.internalError(ctx, "DeletePayment", err))
 	}
+	papi.Metrics.Deleted(deleted)
 
 	return payments.NewDeletePaymentNoContent()
 }
 
 // GetPayment Returns details of a payment identified by its ID
 func (papi *PaymentsService) GetPayment(ctx context.Context, params payments.GetPaymentParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.GetPayment")
+	defer span.End()
+
 	paymentID := params.ID
-	got, err := papi.Repo.Get(paymentID)
+	got, err := papi.Repo.Get(ctx, paymentID)
 	if err != nil {
-		apiError := newAPIError(err.Error())
+		apiError := repoAPIError(ctx, err)
 		if _, ok := err.(ErrNoResults); ok {
 			return payments.NewGetPaymentNotFound().WithPayload(apiError)
 		}
 
-		papi.Logger.Printf("Error on GetPayment: %v", err)
-		return payments.NewGetPaymentInternalServerError().WithPayload(apiError)
+		return payments.NewGetPaymentInternalServerError().WithPayload(papi.internalError(ctx, "GetPayment", err))
 	}
 
 	links := &models.Links{
-		Self: params.HTTPRequest.URL.Path,
+		Self: papi.link(params.HTTPRequest, &payments.GetPaymentURL{ID: params.ID}),
 	}
 	resp := &models.PaymentDetailsResponse{Data: got, Links: links}
-	return payments.NewGetPaymentOK().WithPayload(resp)
+	return payments.NewGetPaymentOK().WithETag(versionETag(*got.Version)).WithPayload(resp)
+}
+
+// GetPaymentVersion Returns a version of a payment identified by its ID and version number
+func (papi *PaymentsService) GetPaymentVersion(ctx context.Context, params payments.GetPaymentVersionParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.GetPaymentVersion")
+	defer span.End()
+
+	got, err := papi.Repo.GetVersion(ctx, params.ID, params.Version)
+	if err != nil {
+		apiError := repoAPIError(ctx, err)
+		if _, ok := err.(ErrNoResults); ok {
+			return payments.NewGetPaymentVersionNotFound().WithPayload(apiError)
+		}
+
+		return payments.NewGetPaymentVersionInternalServerError().WithPayload(papi.internalError(ctx, "GetPaymentVersion", err))
+	}
+
+	links := &models.Links{
+		Self: papi.link(params.HTTPRequest, &payments.GetPaymentVersionURL{ID: params.ID, Version: params.Version}),
+	}
+	resp := &models.PaymentVersionResponse{Data: got, Links: links}
+	return payments.NewGetPaymentVersionOK().WithPayload(resp)
+}
+
+// ListPaymentVersions Returns every version of a payment identified by its ID
+func (papi *PaymentsService) ListPaymentVersions(ctx context.Context, params payments.ListPaymentVersionsParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.ListPaymentVersions")
+	defer span.End()
+
+	list, err := papi.Repo.ListVersions(ctx, params.ID)
+	if err != nil {
+		apiError := repoAPIError(ctx, err)
+		if _, ok := err.(ErrNoResults); ok {
+			return payments.NewListPaymentVersionsNotFound().WithPayload(apiError)
+		}
+
+		return payments.NewListPaymentVersionsInternalServerError().WithPayload(papi.internalError(ctx, "ListPaymentVersions", err))
+	}
+
+	links := &models.Links{
+		Self: papi.link(params.HTTPRequest, &payments.ListPaymentVersionsURL{ID: params.ID}),
+	}
+	resp := &models.PaymentVersionListResponse{Data: list, Links: links}
+	return payments.NewListPaymentVersionsOK().WithPayload(resp)
 }
 
 // ListPayments Returns details of a collection of payments
+//
+// Pages can be selected by number or by cursor. Either way, links to the next
+// and previous pages use cursors, as they are not affected by payments being
+// added or deleted between requests. The link to the last page uses its
+// number, as there is no cursor to point to the end of the list
 func (papi *PaymentsService) ListPayments(ctx context.Context, params payments.ListPaymentsParams) middleware.Responder {
-	// Request params have already been validated by go-swagger generated code
-	pageNumber := *params.PageNumber
-	pageSize := *params.PageSize
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.ListPayments")
+	defer span.End()
+
+	filter, err := newPaymentFilter(params)
+	if err != nil {
+		return payments.NewListPaymentsBadRequest().WithPayload(newAPIError(ctx, http.StatusBadRequest, codeBadRequest, errorMessage(err)))
+	}
 
-	offset := pageNumber * pageSize
-	limit := pageSize
-	list, err := papi.Repo.List(offset, limit)
+	sortBy, err := parseSort(params.Sort)
 	if err != nil {
-		apiError := newAPIError(err.Error())
+		return payments.NewListPaymentsBadRequest().WithPayload(newAPIError(ctx, http.StatusBadRequest, codeBadRequest, errorMessage(err)))
+	}
+
+	pagination, err := newPagination(params, sortBy)
+	if err != nil {
+		return payments.NewListPaymentsBadRequest().WithPayload(newAPIError(ctx, http.StatusBadRequest, codeBadRequest, errorMessage(err)))
+	}
+
+	page, err := papi.Repo.List(ctx, filter, sortBy, pagination)
+	if err != nil {
+		apiError := repoAPIError(ctx, err)
 		if _, ok := err.(ErrNoResults); ok {
 			return payments.NewListPaymentsNotFound().WithPayload(apiError)
 		}
 
-		papi.Logger.Printf("Error on ListPayments: %v", err)
-		return payments.NewListPaymentsInternalServerError().WithPayload(apiError)
+		return payments.NewListPaymentsInternalServerError().WithPayload(papi.internalError(ctx, "ListPayments", err))
 	}
 
-	links := &models.Links{
-		Self: "/payments",
+	total, err := papi.Repo.Count(ctx, filter)
+	if err != nil {
+		return payments.NewListPaymentsInternalServerError().WithPayload(papi.internalError(ctx, "ListPayments", err))
 	}
+
 	resp := &models.PaymentDetailsListResponse{
-		Data:  list,
-		Links: links,
+		Data:  page.Payments,
+		Links: papi.listLinks(params, sortBy, page, total),
+		Meta:  &models.ListMeta{Total: &total},
 	}
 	return payments.NewListPaymentsOK().WithPayload(resp)
 }
 
-// UpdatePayment Adds a new payment with the data included in params
+// listLinks builds absolute links to navigate the list of payments from the
+// given page. Every link keeps the filters, sorting and page size of the current request
+func (papi *PaymentsService) listLinks(params payments.ListPaymentsParams, sortBy []SortField, page *PaymentPage, total int64) *models.Links {
+	self := listURL(params)
+	self.PageNumber = params.PageNumber
+	self.PageAfter = params.PageAfter
+	self.PageBefore = params.PageBefore
+	links := &models.Links{
+		Self:  papi.link(params.HTTPRequest, self),
+		First: papi.link(params.HTTPRequest, listURL(params)),
+	}
+
+	last := listURL(params)
+	lastPage := (total - 1) / *params.PageSize
+	last.PageNumber = &lastPage
+	links.Last = papi.link(params.HTTPRequest, last)
+
+	if page.HasNext {
+		next := listURL(params)
+		after := encodeCursor(paymentCursor(page.Payments[len(page.Payments)-1], sortBy))
+		next.PageAfter = &after
+		links.Next = papi.link(params.HTTPRequest, next)
+	}
+
+	if page.HasPrev {
+		prev := listURL(params)
+		before := encodeCursor(paymentCursor(page.Payments[0], sortBy))
+		prev.PageBefore = &before
+		links.Prev = papi.link(params.HTTPRequest, prev)
+	}
+
+	return links
+}
+
+// urlBuilder is implemented by the URL builders of every operation of the API
+type urlBuilder interface {
+	SetBasePath(basePath string)
+	StringFull(scheme, host string) string
+}
+
+// link returns the absolute URL built by u to be used as a link in the response to r
+func (papi *PaymentsService) link(r *http.Request, u urlBuilder) string {
+	if papi.BaseURL != nil {
+		// An empty base path makes the builder use the one in the spec
+		u.SetBasePath(papi.BaseURL.Path)
+		return u.StringFull(papi.BaseURL.Scheme, papi.BaseURL.Host)
+	}
+
+	scheme, host := requestSchemeHost(r)
+	return u.StringFull(scheme, host)
+}
+
+// requestSchemeHost returns the scheme and host the request was sent to
+func requestSchemeHost(r *http.Request) (string, string) {
+	if r.TLS != nil {
+		return "https", r.Host
+	}
+
+	return "http", r.Host
+}
+
+// parseSort parses the value of the sort query param, which is a comma separated
+// list of field names, each one optionally prefixed with - to sort it in
+// descending order. It returns an error if any field is not allowed or repeated
+func parseSort(value *string) ([]SortField, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var sortBy []SortField
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(*value, ",") {
+		field := SortField{Name: name}
+		if strings.HasPrefix(name, "-") {
+			field = SortField{Name: name[1:], Descending: true}
+		}
+
+		if _, ok := sortableFields[field.Name]; !ok {
+			return nil, fmt.Errorf("sort field %q is not valid, must be one of %s", name, strings.Join(sortableFieldNames(), ", "))
+		}
+
+		if seen[field.Name] {
+			return nil, fmt.Errorf("sort field %q is repeated", field.Name)
+		}
+		seen[field.Name] = true
+
+		sortBy = append(sortBy, field)
+	}
+
+	return sortBy, nil
+}
+
+// sortableFieldNames returns the names of the fields payments can be sorted by, sorted
+func sortableFieldNames() []string {
+	names := make([]string, 0, len(sortableFields))
+	for name := range sortableFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// newPagination builds the pagination to apply when listing payments sorted by
+// the given fields out of the page[...] query params. It returns an error if
+// any cursor is not valid
+func newPagination(params payments.ListPaymentsParams, sortBy []SortField) (Pagination, error) {
+	// Page number and size have already been validated by go-swagger generated code
+	pagination := Pagination{
+		Offset: *params.PageNumber * *params.PageSize,
+		Limit:  *params.PageSize,
+	}
+
+	if params.PageAfter != nil && params.PageBefore != nil {
+		return pagination, errors.New("page[after] and page[before] can't be used at the same time")
+	}
+
+	var err error
+	if pagination.After, err = decodeCursor("page[after]", params.PageAfter, sortBy); err != nil {
+		return pagination, err
+	}
+	if pagination.Before, err = decodeCursor("page[before]", params.PageBefore, sortBy); err != nil {
+		return pagination, err
+	}
+
+	return pagination, nil
+}
+
+// cursorToken is the content of the opaque cursors handed to clients
+type cursorToken struct {
+	ID     strfmt.UUID `json:"id"`
+	Values []string    `json:"values,omitempty"`
+}
+
+// paymentCursor returns the cursor that marks the position of payment in a
+// list sorted by the given fields
+func paymentCursor(payment *models.Payment, sortBy []SortField) Cursor {
+	cursor := Cursor{ID: *payment.ID}
+	for _, field := range sortBy {
+		cursor.Values = append(cursor.Values, sortableFields[field.Name].value(payment))
+	}
+
+	return cursor
+}
+
+// encodeCursor turns a cursor into an opaque value that is safe to be used in URLs
+func encodeCursor(cursor Cursor) string {
+	token, _ := json.Marshal(cursorToken{ID: cursor.ID, Values: cursor.Values})
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// decodeCursor parses the value of the cursor param called name, which must have
+// been created by encodeCursor for a list sorted by the given fields.
+// A nil cursor is returned if the param has no value
+func decodeCursor(name string, value *string, sortBy []SortField) (*Cursor, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("%s %q is not a valid cursor", name, *value)
+	raw, err := base64.RawURLEncoding.DecodeString(*value)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil || !strfmt.IsUUID(token.ID.String()) {
+		return nil, invalid
+	}
+
+	// Cursors can only be used with the sorting of the list they were created for
+	if len(token.Values) != len(sortBy) {
+		return nil, invalid
+	}
+	for i, field := range sortBy {
+		if !validSortValue(sortableFields[field.Name].kind, token.Values[i]) {
+			return nil, invalid
+		}
+	}
+
+	return &Cursor{ID: token.ID, Values: token.Values}, nil
+}
+
+// listURL returns a builder for URLs of the list of payments that keeps the
+// filters, sorting and page size of params. The URL points to the first page
+func listURL(params payments.ListPaymentsParams) *payments.ListPaymentsURL {
+	return &payments.ListPaymentsURL{
+		FilterAmountGte:         params.FilterAmountGte,
+		FilterAmountLte:         params.FilterAmountLte,
+		FilterCurrency:          params.FilterCurrency,
+		FilterOrganisationID:    params.FilterOrganisationID,
+		FilterPaymentScheme:     params.FilterPaymentScheme,
+		FilterProcessingDateGte: params.FilterProcessingDateGte,
+		FilterProcessingDateLte: params.FilterProcessingDateLte,
+		PageSize:                params.PageSize,
+		Sort:                    params.Sort,
+	}
+}
+
+// newPaymentFilter builds the filter to apply when listing payments out of
+// the filter[...] query params. It returns an error if any of them is not valid
+func newPaymentFilter(params payments.ListPaymentsParams) (PaymentFilter, error) {
+	var filter PaymentFilter
+
+	if params.FilterOrganisationID != nil {
+		if !strfmt.IsUUID(*params.FilterOrganisationID) {
+			return filter, fmt.Errorf("filter[organisation_id] %q is not a valid UUID", *params.FilterOrganisationID)
+		}
+		id := strfmt.UUID(*params.FilterOrganisationID)
+		filter.OrganisationID = &id
+	}
+
+	if params.FilterCurrency != nil {
+		if !currencyRegexp.MatchString(*params.FilterCurrency) {
+			return filter, fmt.Errorf("filter[currency] %q is not a valid ISO 4217 currency code", *params.FilterCurrency)
+		}
+		currency := models.Currency(*params.FilterCurrency)
+		filter.Currency = &currency
+	}
+
+	filter.PaymentScheme = params.FilterPaymentScheme
+
+	var err error
+	if filter.ProcessingDateFrom, err = parseDateFilter("filter[processing_date][gte]", params.FilterProcessingDateGte); err != nil {
+		return filter, err
+	}
+	if filter.ProcessingDateTo, err = parseDateFilter("filter[processing_date][lte]", params.FilterProcessingDateLte); err != nil {
+		return filter, err
+	}
+	if filter.ProcessingDateFrom != nil && filter.ProcessingDateTo != nil &&
+		time.Time(*filter.ProcessingDateFrom).After(time.Time(*filter.ProcessingDateTo)) {
+		return filter, fmt.Errorf("filter[processing_date][gte] %s is after filter[processing_date][lte] %s",
+			filter.ProcessingDateFrom, filter.ProcessingDateTo)
+	}
+
+	if filter.AmountFrom, err = parseAmountFilter("filter[amount][gte]", params.FilterAmountGte); err != nil {
+		return filter, err
+	}
+	if filter.AmountTo, err = parseAmountFilter("filter[amount][lte]", params.FilterAmountLte); err != nil {
+		return filter, err
+	}
+	if filter.AmountFrom != nil && filter.AmountTo != nil {
+		from, _ := parseAmount(*filter.AmountFrom)
+		to, _ := parseAmount(*filter.AmountTo)
+		if from.Cmp(to) > 0 {
+			return filter, fmt.Errorf("filter[amount][gte] %s is greater than filter[amount][lte] %s",
+				*filter.AmountFrom, *filter.AmountTo)
+		}
+	}
+
+	return filter, nil
+}
+
+var (
+	currencyRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+	amountRegexp   = regexp.MustCompile(`^[0-9]{1,18}(\.[0-9]{1,2})?$`)
+)
+
+// parseDateFilter parses the value of the date filter called name.
+// A nil date is returned if the filter has no value
+func parseDateFilter(name string, value *string) (*strfmt.Date, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	t, err := time.Parse(strfmt.RFC3339FullDate, *value)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q is not a valid date, expected YYYY-MM-DD", name, *value)
+	}
+
+	date := strfmt.Date(t)
+	return &date, nil
+}
+
+// parseAmountFilter parses the value of the amount filter called name.
+// A nil amount is returned if the filter has no value
+func parseAmountFilter(name string, value *string) (*models.Amount, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if !amountRegexp.MatchString(*value) {
+		return nil, fmt.Errorf("%s %q is not a valid amount, expected a decimal number with up to 2 decimal places", name, *value)
+	}
+
+	amount := models.Amount(*value)
+	return &amount, nil
+}
+
+// UpdatePayment Updates the details of a payment identified by its ID
+//
+// The update can be made conditional on the version of the payment, either by
+// setting the version in the new details or by sending an If-Match header with
+// the ETag of the version. If both are given, the If-Match header takes precedence
 func (papi *PaymentsService) UpdatePayment(ctx context.Context, params payments.UpdatePaymentParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.UpdatePayment")
+	defer span.End()
+
 	paymentID := params.ID
 	payment := params.PaymentUpdateRequest.Data
-	updated, err := papi.Repo.Update(paymentID, payment)
+	if params.IfMatch != nil {
+		version, ok := parseIfMatch(*params.IfMatch)
+		if !ok {
+			apiError := ifMatchAPIError(ctx, *params.IfMatch)
+			return payments.NewUpdatePaymentPreconditionFailed().WithPayload(apiError)
+		}
+
+		// Don't modify the request params, they belong to the caller
+		payment = copyPayment(payment)
+		payment.Version = version
+	}
+
+	updated, err := papi.Repo.Update(ctx, paymentID, payment)
 	if err != nil {
-		apiError := newAPIError(err.Error())
-		if _, ok := err.(ErrNoResults); ok {
+		apiError := repoAPIError(ctx, err)
+		switch err.(type) {
+		case ErrNoResults:
 			return payments.NewUpdatePaymentNotFound().WithPayload(apiError)
+
+		case ErrForbidden:
+			return payments.NewUpdatePaymentForbidden().WithPayload(apiError)
+
+		case ErrVersionMismatch:
+			if params.IfMatch != nil {
+				apiError = newAPIError(ctx, http.StatusPreconditionFailed, codePreconditionFailed, errorMessage(err))
+				return payments.NewUpdatePaymentPreconditionFailed().WithPayload(apiError)
+			}
+			return payments.NewUpdatePaymentConflict().WithPayload(apiError)
 		}
 
-		papi.Logger.Printf("Error on UpdatePayment: %v", err)
-		return payments.NewUpdatePaymentInternalServerError().WithPayload(apiError)
+		return payments.NewUpdatePaymentInternalServerError().WithPayload(papi.internalError(ctx, "UpdatePayment", err))
 	}
+	papi.Metrics.Updated(updated)
 
 	links := &models.Links{
-		Self: params.HTTPRequest.URL.Path,
+		Self: papi.link(params.HTTPRequest, &payments.UpdatePaymentURL{ID: params.ID}),
 	}
 	resp := &models.PaymentUpdateResponse{Data: updated, Links: links}
-	return payments.NewUpdatePaymentOK().WithPayload(resp)
+	return payments.NewUpdatePaymentOK().WithETag(versionETag(*updated.Version)).WithPayload(resp)
 }
 
-func newAPIError(msg string) *models.APIError {
-	errorCode, _ := uuid.NewV4()
-	return &models.APIError{
-		ErrorCode:    strfmt.UUID(errorCode.String()),
-		ErrorMessage: msg,
+// PatchPayment applies a JSON Merge Patch to the details of a payment identified
+// by its ID. The patch is applied to the payment details document, so that it can
+// also be a JSON:API document with partial attributes. The patched payment
+// must be valid, as it happens with the details sent to UpdatePayment, and
+// only the details that changed are written.
+//
+// The patch can be made conditional on the version of the payment, either by
+// setting the version in the patch or by sending an If-Match header with
+// the ETag of the version. If both are given, the If-Match header takes precedence
+func (papi *PaymentsService) PatchPayment(ctx context.Context, params payments.PatchPaymentParams) middleware.Responder {
+	ctx, span := tracing.StartSpan(ctx, "PaymentsService.PatchPayment")
+	defer span.End()
+
+	paymentID := params.ID
+	original, err := papi.Repo.Get(ctx, paymentID)
+	if err != nil {
+		apiError := repoAPIError(ctx, err)
+		if _, ok := err.(ErrNoResults); ok {
+			return payments.NewPatchPaymentNotFound().WithPayload(apiError)
+		}
+
+		return payments.NewPatchPaymentInternalServerError().WithPayload(papi.internalError(ctx, "PatchPayment", err))
+	}
+
+	patched, err := patchPayment(original, params.PaymentPatch)
+	if errs, ok := err.(invalidDocumentError); ok {
+		return payments.NewPatchPaymentUnprocessableEntity().WithPayload(invalidAPIError(ctx, errs))
+	}
+	if err != nil {
+		return payments.NewPatchPaymentInternalServerError().WithPayload(papi.internalError(ctx, "PatchPayment", err))
+	}
+
+	if patched.ID == nil || !strings.EqualFold(patched.ID.String(), paymentID.String()) {
+		apiError := newAPIError(ctx, http.StatusUnprocessableEntity, codeInvalid, "The ID of a payment can't be changed")
+		apiError.Errors[0].Source = &models.APIErrorSource{Pointer: "/data/id"}
+		return payments.NewPatchPaymentUnprocessableEntity().WithPayload(apiError)
+	}
+
+	if params.IfMatch != nil {
+		version, ok := parseIfMatch(*params.IfMatch)
+		if !ok {
+			apiError := ifMatchAPIError(ctx, *params.IfMatch)
+			return payments.NewPatchPaymentPreconditionFailed().WithPayload(apiError)
+		}
+
+		patched.Version = version
 	}
+
+	updated, err := papi.Repo.Patch(ctx, paymentID, original, patched)
+	if err != nil {
+		apiError := repoAPIError(ctx, err)
+		switch err.(type) {
+		case ErrNoResults:
+			return payments.NewPatchPaymentNotFound().WithPayload(apiError)
+
+		case ErrForbidden:
+			return payments.NewPatchPaymentForbidden().WithPayload(apiError)
+
+		case ErrVersionMismatch:
+			if params.IfMatch != nil {
+				apiError = newAPIError(ctx, http.StatusPreconditionFailed, codePreconditionFailed, errorMessage(err))
+				return payments.NewPatchPaymentPreconditionFailed().WithPayload(apiError)
+			}
+			return payments.NewPatchPaymentConflict().WithPayload(apiError)
+		}
+
+		return payments.NewPatchPaymentInternalServerError().WithPayload(papi.internalError(ctx, "PatchPayment", err))
+	}
+	papi.Metrics.Updated(updated)
+
+	links := &models.Links{
+		Self: papi.link(params.HTTPRequest, &payments.PatchPaymentURL{ID: params.ID}),
+	}
+	resp := &models.PaymentUpdateResponse{Data: updated, Links: links}
+	return payments.NewPatchPaymentOK().WithETag(versionETag(*updated.Version)).WithPayload(resp)
+}
+
+// patchPayment applies a JSON Merge Patch to the details document of a payment
+// and returns the patched payment once it has been validated. The version of
+// the payment is left out of the document, so the patched payment only has
+// a version if the patch sets it. The problems found validating the patched
+// document are returned as an invalidDocumentError
+func patchPayment(payment *models.Payment, patch interface{}) (*models.Payment, error) {
+	payment = copyPayment(payment)
+	payment.Version = nil
+	doc, err := toJSONValue(&models.PaymentUpdateRequest{Data: payment})
+	if err != nil {
+		return nil, err
+	}
+
+	// The patched document is validated as a generic JSON value, as it is sent
+	merged, err := toJSONValue(applyMergePatch(doc, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := definitionErrors("PaymentUpdateRequest", "", merged)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, invalidDocumentError(errs)
+	}
+
+	var patched models.PaymentUpdateRequest
+	if err := fromJSONValue(merged, &patched); err != nil {
+		return nil, err
+	}
+	if err := patched.Validate(strfmt.Default); err != nil {
+		return nil, err
+	}
+
+	return patched.Data, nil
+}
+
+// versionETag builds the ETag that identifies a version of a payment
+func versionETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// parseIfMatch extracts the version from the value of an If-Match header.
+// A nil version is returned for "*", as it matches any version. Weak ETags are
+// accepted as well, as versions are always compared as a whole.
+//
+// parseIfMatch returns false if the value is not a valid version ETag
+func parseIfMatch(value string) (*int64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return nil, true
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return nil, false
+	}
+
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 0 {
+		return nil, false
+	}
+
+	return &version, true
+}
+
+// logger returns the logger used for the request with ctx
+func (papi *PaymentsService) logger(ctx context.Context) logrus.FieldLogger {
+	return RequestLogger(ctx, papi.Logger)
 }
@@ -4,6 +4,7 @@ package service_test
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -11,9 +12,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -25,6 +29,7 @@ import (
 	"github.com/mitchellh/copystructure"
 
 	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi"
 	"github.com/volmedo/pAPI/pkg/restapi/operations/payments"
 	"github.com/volmedo/pAPI/pkg/service"
 )
@@ -142,12 +147,15 @@ func TestMain(m *testing.M) {
 		panic(fmt.Sprintf("Unable to configure DB connection: %v", err))
 	}
 
-	testRepo, err = service.NewDBPaymentRepository(db, dbName, migrationsPath)
+	testRepo, err = service.NewDBPaymentRepository(db, dbConf)
 	if err != nil {
 		panic(fmt.Sprintf("Unable to create test DB repo: %v", err))
 	}
 
-	ps = &service.PaymentsService{Repo: testRepo}
+	ps = &service.PaymentsService{
+		Repo:        testRepo,
+		Idempotency: service.NewDBIdempotencyStore(db, dbConf, time.Hour),
+	}
 
 	// Run tests
 	exitCode := m.Run()
@@ -161,11 +169,14 @@ func TestMain(m *testing.M) {
 }
 
 type TestCase struct {
-	name      string
-	setupData []*models.Payment
-	params    interface{}
-	wantCode  int
-	wantResp  interface{}
+	name        string
+	setupData   []*models.Payment
+	params      interface{}
+	wantCode    int
+	wantHeaders map[string]string
+	wantResp    interface{}
+	// wantPointer is the field an error response must point to, if any
+	wantPointer string
 }
 
 func TestPaymentsService(t *testing.T) {
@@ -175,18 +186,20 @@ func TestPaymentsService(t *testing.T) {
 	tests = append(tests, getTests()...)
 	tests = append(tests, deleteTests()...)
 	tests = append(tests, updateTests()...)
+	tests = append(tests, patchTests()...)
 	tests = append(tests, listTests()...)
+	tests = append(tests, versionTests()...)
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Arrange
-			if err := testRepo.DeleteAll(); err != nil {
+			if err := testRepo.DeleteAll(context.Background()); err != nil {
 				t.Fatalf("Error cleaning test repository: %v", err)
 			}
 
 			if tc.setupData != nil {
 				for _, payment := range tc.setupData {
-					_, err := testRepo.Add(payment)
+					_, err := testRepo.Add(context.Background(), payment)
 					if err != nil {
 						t.Fatalf("Error populating test repository: %v", err)
 					}
@@ -204,11 +217,27 @@ func TestPaymentsService(t *testing.T) {
 				t.Fatalf("Wrong status code: got %v, want %v", rr.Code, tc.wantCode)
 			}
 
+			for header, want := range tc.wantHeaders {
+				if got := rr.Header().Get(header); got != want {
+					t.Fatalf("Wrong %s header: got %q, want %q", header, got, want)
+				}
+			}
+
+			if tc.wantPointer != "" {
+				var apiErr models.APIError
+				if err := json.NewDecoder(rr.Body).Decode(&apiErr); err != nil {
+					t.Fatalf("Error decoding ApiError: %v", err)
+				}
+				if !hasPointer(&apiErr, tc.wantPointer) {
+					t.Fatalf("Want an error pointing to %s, got %+v", tc.wantPointer, apiErr.Errors)
+				}
+			}
+
 			if tc.wantResp == nil {
 				return
 			}
 
-			dataDiff, linksDiff, err := compareResponses(rr.Body, tc.wantResp)
+			dataDiff, linksDiff, metaDiff, err := compareResponses(rr.Body, tc.wantResp)
 			if err != nil {
 				t.Fatal(err.Error())
 			}
@@ -218,11 +247,457 @@ func TestPaymentsService(t *testing.T) {
 			if linksDiff != "" {
 				t.Fatalf("Link objects mismatch:\n%s", linksDiff)
 			}
+			if metaDiff != "" {
+				t.Fatalf("Meta objects mismatch:\n%s", metaDiff)
+			}
 		})
 	}
 }
 
+func TestCreatePaymentIdempotency(t *testing.T) {
+	if err := testRepo.DeleteAll(context.Background()); err != nil {
+		t.Fatalf("Error cleaning test repository: %v", err)
+	}
+
+	// Use a new key every time the test runs, as keys are kept between runs
+	newKey, _ := uuid.NewV4()
+	key := newKey.String()
+	params := payments.CreatePaymentParams{
+		HTTPRequest:            httptest.NewRequest("POST", "/payments", nil),
+		IdempotencyKey:         &key,
+		PaymentCreationRequest: &models.PaymentCreationRequest{Data: copyPayment(&testPayment)},
+	}
+	wantPayment := copyPayment(&testPayment)
+	wantPayment.Type = service.TYPE_PAYMENT
+	wantResp := &models.PaymentCreationResponse{
+		Data:  wantPayment,
+		Links: &models.Links{Self: paymentURL(*testPayment.ID)},
+	}
+
+	otherPayment := copyPayment(&testPayment)
+	otherPayment.Attributes.Amount = "1.00"
+	otherBody := params
+	otherBody.PaymentCreationRequest = &models.PaymentCreationRequest{Data: otherPayment}
+
+	otherKey := params
+	newKey, _ = uuid.NewV4()
+	otherKeyValue := newKey.String()
+	otherKey.IdempotencyKey = &otherKeyValue
+
+	noKey := params
+	noKey.IdempotencyKey = nil
+
+	steps := []struct {
+		name     string
+		params   payments.CreatePaymentParams
+		wantCode int
+		wantResp interface{}
+	}{
+		{"first request", params, http.StatusCreated, wantResp},
+		{"retry", params, http.StatusCreated, wantResp},
+		{"same key with a different body", otherBody, http.StatusUnprocessableEntity, nil},
+		{"same body with a different key", otherKey, http.StatusConflict, nil},
+		{"same body without key", noKey, http.StatusConflict, nil},
+	}
+
+	// Steps depend on each other, so they must run in order
+	for _, step := range steps {
+		rr, err := doRequest(ps, step.params)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		if rr.Code != step.wantCode {
+			t.Fatalf("%s: wrong status code: got %v, want %v", step.name, rr.Code, step.wantCode)
+		}
+
+		if step.wantResp == nil {
+			continue
+		}
+
+		dataDiff, linksDiff, _, err := compareResponses(rr.Body, step.wantResp)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if dataDiff != "" {
+			t.Fatalf("%s: payment data mismatch:\n%s", step.name, dataDiff)
+		}
+		if linksDiff != "" {
+			t.Fatalf("%s: link objects mismatch:\n%s", step.name, linksDiff)
+		}
+	}
+}
+
+func TestCreatePaymentWithoutID(t *testing.T) {
+	if err := testRepo.DeleteAll(context.Background()); err != nil {
+		t.Fatalf("Error cleaning test repository: %v", err)
+	}
+
+	payment := copyPayment(&testPayment)
+	payment.ID = nil
+	params := payments.CreatePaymentParams{
+		HTTPRequest:            httptest.NewRequest("POST", "/payments", nil),
+		PaymentCreationRequest: &models.PaymentCreationRequest{Data: payment},
+	}
+
+	rr, err := doRequest(ps, params)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Wrong status code: got %v, want %v", rr.Code, http.StatusCreated)
+	}
+
+	var resp models.PaymentCreationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if resp.Data.ID == nil || !strfmt.IsUUID(resp.Data.ID.String()) {
+		t.Fatalf("Want a new UUID for the payment but got %v", resp.Data.ID)
+	}
+
+	if payment.ID != nil {
+		t.Error("Request params should not be modified")
+	}
+
+	want := paymentURL(*resp.Data.ID)
+	if got := rr.Header().Get("Location"); got != want {
+		t.Errorf("Wrong Location header: got %q, want %q", got, want)
+	}
+	if resp.Links.Self != want {
+		t.Errorf("Wrong self link: got %q, want %q", resp.Links.Self, want)
+	}
+
+	if _, err := testRepo.Get(context.Background(), *resp.Data.ID); err != nil {
+		t.Errorf("Error getting created payment: %v", err)
+	}
+}
+
+// slowRepo is a repository that takes some time to add payments
+type slowRepo struct {
+	service.PaymentRepository
+	delay time.Duration
+}
+
+func (sr *slowRepo) Add(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	time.Sleep(sr.delay)
+	return sr.PaymentRepository.Add(ctx, payment)
+}
+
+func TestCreatePaymentConcurrentRetries(t *testing.T) {
+	if err := testRepo.DeleteAll(context.Background()); err != nil {
+		t.Fatalf("Error cleaning test repository: %v", err)
+	}
+
+	// Without an ID in the request, retries would create a new payment each
+	// if they were handled concurrently
+	payment := copyPayment(&testPayment)
+	payment.ID = nil
+	newKey, _ := uuid.NewV4()
+	key := newKey.String()
+	params := payments.CreatePaymentParams{
+		HTTPRequest:            httptest.NewRequest("POST", "/payments", nil),
+		IdempotencyKey:         &key,
+		PaymentCreationRequest: &models.PaymentCreationRequest{Data: payment},
+	}
+
+	// Payments take a while to be created, so that every request is handled
+	// while the first one is still running
+	slowPS := *ps
+	slowPS.Repo = &slowRepo{PaymentRepository: testRepo, delay: 50 * time.Millisecond}
+
+	const retries = 10
+	recorders := make([]*httptest.ResponseRecorder, retries)
+	errs := make([]error, retries)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < retries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			recorders[i], errs[i] = doRequest(&slowPS, params)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	var ids []strfmt.UUID
+	for i, rr := range recorders {
+		if errs[i] != nil {
+			t.Fatalf("Error sending request: %v", errs[i])
+		}
+
+		switch rr.Code {
+		case http.StatusCreated:
+			var resp models.PaymentCreationResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+			ids = append(ids, *resp.Data.ID)
+
+		case http.StatusConflict:
+			// The first request was still being handled
+
+		default:
+			t.Fatalf("Wrong status code: got %v, want %v or %v", rr.Code, http.StatusCreated, http.StatusConflict)
+		}
+	}
+
+	// A retry sent once every request has finished gets the original response
+	rr, err := doRequest(ps, params)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Wrong status code: got %v, want %v", rr.Code, http.StatusCreated)
+	}
+	var resp models.PaymentCreationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	ids = append(ids, *resp.Data.ID)
+
+	for _, id := range ids {
+		if id != ids[0] {
+			t.Fatalf("Want every response to have the same payment but got %v", ids)
+		}
+	}
+
+	stored, err := testRepo.Count(context.Background(), service.PaymentFilter{})
+	if err != nil {
+		t.Fatalf("Error counting payments: %v", err)
+	}
+	if stored != 1 {
+		t.Errorf("Want a single payment created but got %d", stored)
+	}
+}
+
+func TestBulkCreatePayments(t *testing.T) {
+	newPayment := func() *models.Payment {
+		payment := copyPayment(&testPayment)
+		newID, _ := uuid.NewV4()
+		paymentID := strfmt.UUID(newID.String())
+		payment.ID = &paymentID
+		return payment
+	}
+	invalid := newPayment()
+	invalid.Attributes.Amount = "not an amount"
+	generated := newPayment()
+	generated.ID = nil
+	duplicate := copyPayment(&testPayment)
+
+	tests := []struct {
+		name         string
+		items        []*models.Payment
+		atomic       bool
+		wantCode     int
+		wantStatuses []int64
+		wantStored   int64
+	}{
+		{
+			name:         "atomic",
+			items:        []*models.Payment{newPayment(), generated, newPayment()},
+			atomic:       true,
+			wantCode:     http.StatusCreated,
+			wantStatuses: []int64{201, 201, 201},
+			wantStored:   4,
+		}, {
+			name:         "atomic with invalid payment",
+			items:        []*models.Payment{newPayment(), invalid, newPayment()},
+			atomic:       true,
+			wantCode:     http.StatusUnprocessableEntity,
+			wantStatuses: []int64{424, 422, 424},
+			wantStored:   1,
+		}, {
+			name:         "atomic with conflict",
+			items:        []*models.Payment{newPayment(), duplicate, newPayment()},
+			atomic:       true,
+			wantCode:     http.StatusConflict,
+			wantStatuses: []int64{424, 409, 424},
+			wantStored:   1,
+		}, {
+			name:         "not atomic",
+			items:        []*models.Payment{newPayment(), invalid, duplicate, generated},
+			atomic:       false,
+			wantCode:     http.StatusMultiStatus,
+			wantStatuses: []int64{201, 422, 409, 201},
+			wantStored:   3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := testRepo.DeleteAll(context.Background()); err != nil {
+				t.Fatalf("Error cleaning test repository: %v", err)
+			}
+			if _, err := testRepo.Add(context.Background(), &testPayment); err != nil {
+				t.Fatalf("Error populating test repository: %v", err)
+			}
+
+			items := make([]interface{}, len(tc.items))
+			for i, item := range tc.items {
+				items[i] = item
+			}
+			atomic := tc.atomic
+			params := payments.BulkCreatePaymentsParams{
+				HTTPRequest:                httptest.NewRequest("POST", "/payments/bulk", nil),
+				Atomic:                     &atomic,
+				PaymentBulkCreationRequest: &models.PaymentBulkCreationRequest{Data: items},
+			}
+
+			rr, err := doRequest(ps, params)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if rr.Code != tc.wantCode {
+				t.Fatalf("Wrong status code: got %v, want %v", rr.Code, tc.wantCode)
+			}
+
+			var resp models.PaymentBulkCreationResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+
+			var wantCreated int64
+			for i, result := range resp.Data {
+				if *result.Index != int64(i) || *result.Status != tc.wantStatuses[i] {
+					t.Fatalf("Wrong result %d: got status %d, want %d", i, *result.Status, tc.wantStatuses[i])
+				}
+
+				if *result.Status != http.StatusCreated {
+					if result.Error == nil || len(result.Error.Errors) == 0 {
+						t.Fatalf("Want an error for result %d", i)
+					}
+					// Invalid payments point to the invalid field in the request
+					wantPointer := fmt.Sprintf("/data/%d/attributes/amount", i)
+					source := result.Error.Errors[0].Source
+					if *result.Status == http.StatusUnprocessableEntity && (source == nil || source.Pointer != wantPointer) {
+						t.Errorf("Want an error pointing to %s for result %d but got %+v", wantPointer, i, source)
+					}
+					continue
+				}
+
+				wantCreated++
+				if result.Links.Self != paymentURL(*result.Data.ID) {
+					t.Errorf("Wrong self link for result %d: %q", i, result.Links.Self)
+				}
+			}
+
+			if *resp.Meta.Created != wantCreated || *resp.Meta.Failed != int64(len(tc.items))-wantCreated {
+				t.Errorf("Wrong meta: got %d created and %d failed", *resp.Meta.Created, *resp.Meta.Failed)
+			}
+
+			stored, err := testRepo.Count(context.Background(), service.PaymentFilter{})
+			if err != nil {
+				t.Fatalf("Error counting payments: %v", err)
+			}
+			if stored != tc.wantStored {
+				t.Errorf("Wrong number of stored payments: got %d, want %d", stored, tc.wantStored)
+			}
+		})
+	}
+}
+
+func TestPublicBaseURL(t *testing.T) {
+	if err := testRepo.DeleteAll(context.Background()); err != nil {
+		t.Fatalf("Error cleaning test repository: %v", err)
+	}
+
+	baseURL, _ := url.Parse("https://api.example.com/payments-api/v1")
+	publicPS := *ps
+	publicPS.BaseURL = baseURL
+	params := payments.CreatePaymentParams{
+		HTTPRequest:            httptest.NewRequest("POST", "http://internal:8080/v1/payments", nil),
+		PaymentCreationRequest: &models.PaymentCreationRequest{Data: copyPayment(&testPayment)},
+	}
+
+	rr, err := doRequest(&publicPS, params)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Wrong status code: got %v, want %v", rr.Code, http.StatusCreated)
+	}
+
+	want := fmt.Sprintf("https://api.example.com/payments-api/v1/payments/%s", testPayment.ID)
+	if got := rr.Header().Get("Location"); got != want {
+		t.Errorf("Wrong Location header: got %q, want %q", got, want)
+	}
+
+	var resp models.PaymentCreationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if resp.Links.Self != want {
+		t.Errorf("Wrong self link: got %q, want %q", resp.Links.Self, want)
+	}
+}
+
 // copyPayment performs a deep copy of a models.Payment structure
+func TestOrganisationScope(t *testing.T) {
+	if err := testRepo.DeleteAll(context.Background()); err != nil {
+		t.Fatalf("Error cleaning test repository: %v", err)
+	}
+	if _, err := testRepo.Add(context.Background(), &testPayment); err != nil {
+		t.Fatalf("Error adding test payment: %v", err)
+	}
+
+	// The caller only has access to an organisation other than the one of testPayment
+	otherOrg := strfmt.UUID("2f1f6f9e-6b0c-4a3e-9d55-5b1f4d4c3e2a")
+	principal := &service.Principal{Subject: "other", Organisations: []strfmt.UUID{otherOrg}}
+	ctx := context.WithValue(context.Background(), restapi.AuthKey, principal)
+
+	tests := map[string]struct {
+		responder middleware.Responder
+		wantCode  int
+	}{
+		"get payment of other organisation": {
+			responder: ps.GetPayment(ctx, payments.GetPaymentParams{ID: *testPayment.ID}),
+			wantCode:  http.StatusNotFound,
+		},
+		"delete payment of other organisation": {
+			responder: ps.DeletePayment(ctx, payments.DeletePaymentParams{ID: *testPayment.ID}),
+			wantCode:  http.StatusNotFound,
+		},
+		"create payment for other organisation": {
+			responder: ps.CreatePayment(ctx, payments.CreatePaymentParams{
+				HTTPRequest:            httptest.NewRequest("POST", "/payments", nil),
+				PaymentCreationRequest: &models.PaymentCreationRequest{Data: copyPayment(&testPayment)},
+			}),
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tc.responder.WriteResponse(rr, runtime.JSONProducer())
+			if rr.Code != tc.wantCode {
+				t.Errorf("Wrong status code: got %v, want %v", rr.Code, tc.wantCode)
+			}
+		})
+	}
+
+	if _, err := testRepo.Get(context.Background(), *testPayment.ID); err != nil {
+		t.Errorf("Payment should not be deleted by other organisations: %v", err)
+	}
+}
+
+// hasPointer returns whether any of the errors in apiErr points to the given field
+func hasPointer(apiErr *models.APIError, pointer string) bool {
+	for _, obj := range apiErr.Errors {
+		if obj.Source != nil && obj.Source.Pointer == pointer {
+			return true
+		}
+	}
+	return false
+}
+
 func copyPayment(payment *models.Payment) *models.Payment {
 	dup, _ := copystructure.Copy(*payment)
 	paymentDup := dup.(models.Payment)
@@ -246,9 +721,24 @@ func doRequest(ps *service.PaymentsService, params interface{}) (*httptest.Respo
 	case payments.UpdatePaymentParams:
 		responder = ps.UpdatePayment(ctx, p)
 
+	case payments.PatchPaymentParams:
+		responder = ps.PatchPayment(ctx, p)
+
+	case payments.BulkCreatePaymentsParams:
+		responder = ps.BulkCreatePayments(ctx, p)
+
 	case payments.ListPaymentsParams:
+		if p.HTTPRequest == nil {
+			p.HTTPRequest = httptest.NewRequest("GET", listRequestURL(p), nil)
+		}
 		responder = ps.ListPayments(ctx, p)
 
+	case payments.ListPaymentVersionsParams:
+		responder = ps.ListPaymentVersions(ctx, p)
+
+	case payments.GetPaymentVersionParams:
+		responder = ps.GetPaymentVersion(ctx, p)
+
 	default:
 		return nil, fmt.Errorf("Unknown params type: %T", p)
 	}
@@ -263,13 +753,14 @@ func doRequest(ps *service.PaymentsService, params interface{}) (*httptest.Respo
 	return rr, nil
 }
 
-func compareResponses(gotBody io.Reader, wantResp interface{}) (dataDiff, linksDiff string, e error) {
+func compareResponses(gotBody io.Reader, wantResp interface{}) (dataDiff, linksDiff, metaDiff string, e error) {
 	decoder := json.NewDecoder(gotBody)
 	// Use maps to allow direct comparison, independent of element order
 	wantData := make(map[strfmt.UUID]*models.Payment)
 	var wantLinks *models.Links
 	gotData := make(map[strfmt.UUID]*models.Payment)
 	var gotLinks *models.Links
+	var wantMeta, gotMeta *models.ListMeta
 	var err error
 	switch resp := wantResp.(type) {
 	case *models.PaymentCreationResponse:
@@ -278,7 +769,7 @@ func compareResponses(gotBody io.Reader, wantResp interface{}) (dataDiff, linksD
 		var gotResp models.PaymentCreationResponse
 		err = decoder.Decode(&gotResp)
 		if err != nil {
-			return "", "", fmt.Errorf("Malformed JSON in response: %v", err)
+			return "", "", "", fmt.Errorf("Malformed JSON in response: %v", err)
 		}
 		gotData[*gotResp.Data.ID] = gotResp.Data
 		gotLinks = gotResp.Links
@@ -289,7 +780,7 @@ func compareResponses(gotBody io.Reader, wantResp interface{}) (dataDiff, linksD
 		var gotResp models.PaymentCreationResponse
 		err = decoder.Decode(&gotResp)
 		if err != nil {
-			return "", "", fmt.Errorf("Malformed JSON in response: %v", err)
+			return "", "", "", fmt.Errorf("Malformed JSON in response: %v", err)
 		}
 		gotData[*gotResp.Data.ID] = gotResp.Data
 		gotLinks = gotResp.Links
@@ -300,7 +791,7 @@ func compareResponses(gotBody io.Reader, wantResp interface{}) (dataDiff, linksD
 		var gotResp models.PaymentCreationResponse
 		err = decoder.Decode(&gotResp)
 		if err != nil {
-			return "", "", fmt.Errorf("Malformed JSON in response: %v", err)
+			return "", "", "", fmt.Errorf("Malformed JSON in response: %v", err)
 		}
 		gotData[*gotResp.Data.ID] = gotResp.Data
 		gotLinks = gotResp.Links
@@ -313,15 +804,17 @@ func compareResponses(gotBody io.Reader, wantResp interface{}) (dataDiff, linksD
 		var gotResp models.PaymentDetailsListResponse
 		err = decoder.Decode(&gotResp)
 		if err != nil {
-			return "", "", fmt.Errorf("Malformed JSON in response: %v", err)
+			return "", "", "", fmt.Errorf("Malformed JSON in response: %v", err)
 		}
 		for _, payment := range gotResp.Data {
 			gotData[*payment.ID] = payment
 		}
 		gotLinks = gotResp.Links
+		wantMeta = resp.Meta
+		gotMeta = gotResp.Meta
 
 	default:
-		return "", "", fmt.Errorf("Unable to decode response, unkwnown type: %T", resp)
+		return "", "", "", fmt.Errorf("Unable to decode response, unkwnown type: %T", resp)
 	}
 
 	// go-cmp requires a custom comparer for strfmt.Date because it has unexported fields
@@ -331,8 +824,9 @@ func compareResponses(gotBody io.Reader, wantResp interface{}) (dataDiff, linksD
 	})
 	dataDiff = cmp.Diff(gotData, wantData, dateComparer)
 	linksDiff = cmp.Diff(gotLinks, wantLinks)
+	metaDiff = cmp.Diff(gotMeta, wantMeta)
 
-	return dataDiff, linksDiff, nil
+	return dataDiff, linksDiff, metaDiff, nil
 }
 
 func createTests() []TestCase {
@@ -345,7 +839,7 @@ func createTests() []TestCase {
 	wantPayment := copyPayment(&testPayment)
 	wantPayment.Type = service.TYPE_PAYMENT
 	wantLinks := &models.Links{
-		Self: fmt.Sprintf("/payments/%s", testPayment.ID),
+		Self: paymentURL(*testPayment.ID),
 	}
 	wantResp := &models.PaymentCreationResponse{
 		Data:  wantPayment,
@@ -353,11 +847,12 @@ func createTests() []TestCase {
 	}
 	return []TestCase{
 		{
-			name:      "create",
-			setupData: nil,
-			params:    params,
-			wantCode:  http.StatusCreated,
-			wantResp:  wantResp,
+			name:        "create",
+			setupData:   nil,
+			params:      params,
+			wantCode:    http.StatusCreated,
+			wantHeaders: map[string]string{"Location": paymentURL(*testPayment.ID)},
+			wantResp:    wantResp,
 		}, {
 			name:      "create conflict",
 			setupData: setupData,
@@ -378,7 +873,7 @@ func getTests() []TestCase {
 	wantPayment := copyPayment(&testPayment)
 	wantPayment.Type = service.TYPE_PAYMENT
 	wantLinks := &models.Links{
-		Self: fmt.Sprintf("/payments/%s", testPayment.ID),
+		Self: paymentURL(*testPayment.ID),
 	}
 	wantResp := &models.PaymentDetailsResponse{
 		Data:  wantPayment,
@@ -386,11 +881,12 @@ func getTests() []TestCase {
 	}
 	return []TestCase{
 		{
-			name:      "get",
-			setupData: setupData,
-			params:    params,
-			wantCode:  http.StatusOK,
-			wantResp:  wantResp,
+			name:        "get",
+			setupData:   setupData,
+			params:      params,
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"ETag": `"0"`},
+			wantResp:    wantResp,
 		}, {
 			name:      "get non-existent",
 			setupData: nil,
@@ -445,23 +941,241 @@ func updateTests() []TestCase {
 	wantVersion += 1
 	wantPayment.Version = &wantVersion
 	wantLinks := &models.Links{
-		Self: fmt.Sprintf("/payments/%s", testPayment.ID),
+		Self: paymentURL(*testPayment.ID),
 	}
 	wantResp := &models.PaymentUpdateResponse{
 		Data:  wantPayment,
 		Links: wantLinks,
 	}
+	// Updates based on a version other than the current one must be rejected
+	staleVersion := *testPayment.Version + 3
+	stalePayment := copyPayment(updatedPayment)
+	stalePayment.Version = &staleVersion
+	staleParams := params
+	staleParams.PaymentUpdateRequest = &models.PaymentUpdateRequest{Data: stalePayment}
+
+	// If-Match takes precedence over the version in the body
+	ifMatchPayment := copyPayment(stalePayment)
+	ifMatchParams := staleParams
+	ifMatchParams.PaymentUpdateRequest = &models.PaymentUpdateRequest{Data: ifMatchPayment}
+	currentETag := fmt.Sprintf(`"%d"`, *testPayment.Version)
+	ifMatchParams.IfMatch = &currentETag
+
+	staleIfMatchParams := params
+	staleETag := fmt.Sprintf(`"%d"`, staleVersion)
+	staleIfMatchParams.IfMatch = &staleETag
+
+	badIfMatchParams := params
+	badETag := "not-a-version"
+	badIfMatchParams.IfMatch = &badETag
+
+	wantETag := map[string]string{"ETag": fmt.Sprintf(`"%d"`, wantVersion)}
 	return []TestCase{
 		{
-			name:      "update",
+			name:        "update",
+			setupData:   setupData,
+			params:      params,
+			wantCode:    http.StatusOK,
+			wantHeaders: wantETag,
+			wantResp:    wantResp,
+		}, {
+			name:      "update non-existent",
+			setupData: nil,
+			params:    params,
+			wantCode:  http.StatusNotFound,
+			wantResp:  nil,
+		}, {
+			name:      "update stale version",
 			setupData: setupData,
+			params:    staleParams,
+			wantCode:  http.StatusConflict,
+			wantResp:  nil,
+		}, {
+			name:        "update if-match",
+			setupData:   setupData,
+			params:      ifMatchParams,
+			wantCode:    http.StatusOK,
+			wantHeaders: wantETag,
+			wantResp:    nil,
+		}, {
+			name:      "update stale if-match",
+			setupData: setupData,
+			params:    staleIfMatchParams,
+			wantCode:  http.StatusPreconditionFailed,
+			wantResp:  nil,
+		}, {
+			name:      "update malformed if-match",
+			setupData: setupData,
+			params:    badIfMatchParams,
+			wantCode:  http.StatusPreconditionFailed,
+			wantResp:  nil,
+		},
+	}
+}
+
+func patchTests() []TestCase {
+	req := httptest.NewRequest("PATCH", fmt.Sprintf("/payments/%s", testPayment.ID), nil)
+	setupData := []*models.Payment{&testPayment}
+
+	patch := map[string]interface{}{
+		"data": map[string]interface{}{
+			"attributes": map[string]interface{}{
+				"reference":       "Payment for Em's guitar lessons",
+				"payment_purpose": nil,
+			},
+		},
+	}
+	params := payments.PatchPaymentParams{
+		HTTPRequest:  req,
+		ID:           *testPayment.ID,
+		PaymentPatch: patch,
+	}
+
+	wantPayment := copyPayment(&testPayment)
+	wantPayment.Type = service.TYPE_PAYMENT
+	wantVersion := *wantPayment.Version + 1
+	wantPayment.Version = &wantVersion
+	wantPayment.Attributes.Reference = "Payment for Em's guitar lessons"
+	wantPayment.Attributes.PaymentPurpose = ""
+	wantResp := &models.PaymentUpdateResponse{
+		Data:  wantPayment,
+		Links: &models.Links{Self: paymentURL(*testPayment.ID)},
+	}
+	wantETag := map[string]string{"ETag": fmt.Sprintf(`"%d"`, wantVersion)}
+
+	// Patches can set the version they are based on, like updates
+	staleParams := params
+	staleParams.PaymentPatch = map[string]interface{}{
+		"data": map[string]interface{}{
+			"version":    *testPayment.Version + 3,
+			"attributes": map[string]interface{}{"reference": "Stale reference"},
+		},
+	}
+
+	ifMatchParams := staleParams
+	currentETag := fmt.Sprintf(`"%d"`, *testPayment.Version)
+	ifMatchParams.IfMatch = &currentETag
+
+	staleIfMatchParams := params
+	staleETag := fmt.Sprintf(`"%d"`, *testPayment.Version+3)
+	staleIfMatchParams.IfMatch = &staleETag
+
+	// The patched payment must be valid
+	invalidParams := params
+	invalidParams.PaymentPatch = map[string]interface{}{
+		"data": map[string]interface{}{"organisation_id": nil},
+	}
+
+	malformedParams := params
+	malformedParams.PaymentPatch = map[string]interface{}{
+		"data": map[string]interface{}{
+			"attributes": map[string]interface{}{"amount": []interface{}{"100.21"}},
+		},
+	}
+
+	newID := strfmt.UUID("a4f5c9a1-7a4e-4a8e-9d83-3c0d2f5b1e11")
+	changeIDParams := params
+	changeIDParams.PaymentPatch = map[string]interface{}{
+		"data": map[string]interface{}{"id": newID},
+	}
+
+	return []TestCase{
+		{
+			name:        "patch",
+			setupData:   setupData,
+			params:      params,
+			wantCode:    http.StatusOK,
+			wantHeaders: wantETag,
+			wantResp:    wantResp,
+		}, {
+			name:      "patch non-existent",
+			setupData: nil,
 			params:    params,
+			wantCode:  http.StatusNotFound,
+			wantResp:  nil,
+		}, {
+			name:      "patch stale version",
+			setupData: setupData,
+			params:    staleParams,
+			wantCode:  http.StatusConflict,
+			wantResp:  nil,
+		}, {
+			name:        "patch if-match",
+			setupData:   setupData,
+			params:      ifMatchParams,
+			wantCode:    http.StatusOK,
+			wantHeaders: wantETag,
+			wantResp:    nil,
+		}, {
+			name:      "patch stale if-match",
+			setupData: setupData,
+			params:    staleIfMatchParams,
+			wantCode:  http.StatusPreconditionFailed,
+			wantResp:  nil,
+		}, {
+			name:        "patch invalid result",
+			setupData:   setupData,
+			params:      invalidParams,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantResp:    nil,
+			wantPointer: "/data/organisation_id",
+		}, {
+			name:        "patch malformed attribute",
+			setupData:   setupData,
+			params:      malformedParams,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantResp:    nil,
+			wantPointer: "/data/attributes/amount",
+		}, {
+			name:        "patch id",
+			setupData:   setupData,
+			params:      changeIDParams,
+			wantCode:    http.StatusUnprocessableEntity,
+			wantResp:    nil,
+			wantPointer: "/data/id",
+		},
+	}
+}
+
+func versionTests() []TestCase {
+	setupData := []*models.Payment{&testPayment}
+	listReq := httptest.NewRequest("GET", fmt.Sprintf("/payments/%s/versions", testPayment.ID), nil)
+	listParams := payments.ListPaymentVersionsParams{
+		HTTPRequest: listReq,
+		ID:          *testPayment.ID,
+	}
+	getReq := httptest.NewRequest("GET", fmt.Sprintf("/payments/%s/versions/0", testPayment.ID), nil)
+	getParams := payments.GetPaymentVersionParams{
+		HTTPRequest: getReq,
+		ID:          *testPayment.ID,
+		Version:     0,
+	}
+	missingParams := getParams
+	missingParams.Version = 1
+
+	return []TestCase{
+		{
+			name:      "list versions",
+			setupData: setupData,
+			params:    listParams,
 			wantCode:  http.StatusOK,
-			wantResp:  wantResp,
+			wantResp:  nil,
 		}, {
-			name:      "update non-existent",
+			name:      "list versions non-existent",
 			setupData: nil,
-			params:    params,
+			params:    listParams,
+			wantCode:  http.StatusNotFound,
+			wantResp:  nil,
+		}, {
+			name:      "get version",
+			setupData: setupData,
+			params:    getParams,
+			wantCode:  http.StatusOK,
+			wantResp:  nil,
+		}, {
+			name:      "get version non-existent",
+			setupData: setupData,
+			params:    missingParams,
 			wantCode:  http.StatusNotFound,
 			wantResp:  nil,
 		},
@@ -488,8 +1202,49 @@ func listTests() []TestCase {
 		setupData = append(setupData, payment)
 	}
 
-	wantLinks := &models.Links{
-		Self:  "/payments",
+	// pageLinks builds the links wanted for a page of results listed with params,
+	// which point to the pages right after next and right before prev, if given
+	pageLinks := func(params payments.ListPaymentsParams, next, prev *models.Payment, total int64) *models.Links {
+		pageURL := func() *payments.ListPaymentsURL {
+			return &payments.ListPaymentsURL{
+				FilterAmountGte:         params.FilterAmountGte,
+				FilterAmountLte:         params.FilterAmountLte,
+				FilterCurrency:          params.FilterCurrency,
+				FilterOrganisationID:    params.FilterOrganisationID,
+				FilterPaymentScheme:     params.FilterPaymentScheme,
+				FilterProcessingDateGte: params.FilterProcessingDateGte,
+				FilterProcessingDateLte: params.FilterProcessingDateLte,
+				PageSize:                params.PageSize,
+				Sort:                    params.Sort,
+			}
+		}
+
+		last := pageURL()
+		lastPage := (total - 1) / *params.PageSize
+		last.PageNumber = &lastPage
+		links := &models.Links{
+			Self:  listRequestURL(params),
+			First: pageURL().StringFull("http", "example.com"),
+			Last:  last.StringFull("http", "example.com"),
+		}
+		if next != nil {
+			after := cursorFor(next, sortValuesFor(params, next)...)
+			u := pageURL()
+			u.PageAfter = &after
+			links.Next = u.StringFull("http", "example.com")
+		}
+		if prev != nil {
+			before := cursorFor(prev, sortValuesFor(params, prev)...)
+			u := pageURL()
+			u.PageBefore = &before
+			links.Prev = u.StringFull("http", "example.com")
+		}
+
+		return links
+	}
+
+	listMeta := func(total int64) *models.ListMeta {
+		return &models.ListMeta{Total: &total}
 	}
 
 	newParams := func(pNum, pSize *int64) payments.ListPaymentsParams {
@@ -514,7 +1269,8 @@ func listTests() []TestCase {
 		wantCode:  http.StatusOK,
 		wantResp:  &models.PaymentDetailsListResponse{
 			Data: setupData[:10],
-			Links: wantLinks,
+			Links: pageLinks(params, setupData[9], nil, 20),
+			Meta:  listMeta(20),
 		},
 	}
 
@@ -529,7 +1285,8 @@ func listTests() []TestCase {
 		wantCode:  http.StatusOK,
 		wantResp:  &models.PaymentDetailsListResponse{
 			Data: setupData[:5],
-			Links: wantLinks,
+			Links: pageLinks(params, setupData[4], nil, 20),
+			Meta:  listMeta(20),
 		},
 	}
 
@@ -545,7 +1302,8 @@ func listTests() []TestCase {
 		wantCode:  http.StatusOK,
 		wantResp:  &models.PaymentDetailsListResponse{
 			Data: setupData[9:12],
-			Links: wantLinks,
+			Links: pageLinks(params, setupData[11], setupData[9], 20),
+			Meta:  listMeta(20),
 		},
 	}
 
@@ -561,7 +1319,8 @@ func listTests() []TestCase {
 		wantCode:  http.StatusOK,
 		wantResp:  &models.PaymentDetailsListResponse{
 			Data: setupData[18:],
-			Links: wantLinks,
+			Links: pageLinks(params, nil, setupData[18], 20),
+			Meta:  listMeta(20),
 		},
 	}
 
@@ -576,7 +1335,8 @@ func listTests() []TestCase {
 		wantCode:  http.StatusOK,
 		wantResp:  &models.PaymentDetailsListResponse{
 			Data: setupData[10:],
-			Links: wantLinks,
+			Links: pageLinks(params, nil, setupData[10], 20),
+			Meta:  listMeta(20),
 		},
 	}
 
@@ -593,6 +1353,221 @@ func listTests() []TestCase {
 		wantResp:  nil,
 	}
 
+	// Half of the payments are made in EUR and half of them on a later date
+	filterData := make([]*models.Payment, 0, len(setupData))
+	for i, payment := range setupData {
+		payment = copyPayment(payment)
+		if i%2 == 0 {
+			payment.Attributes.Currency = "EUR"
+		}
+		if i >= len(setupData)/2 {
+			payment.Attributes.ProcessingDate = strfmt.Date(time.Date(2017, 2, 1, 0, 0, 0, 0, time.UTC))
+		}
+		filterData = append(filterData, payment)
+	}
+
+	currency := "EUR"
+	dateFrom := "2017-01-20"
+	params = newParams(nil, nil)
+	params.FilterCurrency = &currency
+	params.FilterProcessingDateGte = &dateFrom
+	filtered := TestCase{
+		name:      "list filtered by currency and processing date",
+		setupData: filterData,
+		params:    params,
+		wantCode:  http.StatusOK,
+		wantResp:  &models.PaymentDetailsListResponse{
+			Data: []*models.Payment{filterData[10], filterData[12], filterData[14], filterData[16], filterData[18]},
+			Links: pageLinks(params, nil, nil, 5),
+			Meta:  listMeta(5),
+		},
+	}
+
+	amountFrom := "100.22"
+	params = newParams(nil, nil)
+	params.FilterAmountGte = &amountFrom
+	filteredNoResults := TestCase{
+		name:      "list filtered with no matching payments",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusNotFound,
+		wantResp:  nil,
+	}
+
+	badDate := "18/01/2017"
+	params = newParams(nil, nil)
+	params.FilterProcessingDateLte = &badDate
+	badFilter := TestCase{
+		name:      "list with invalid filter",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusBadRequest,
+		wantResp:  nil,
+	}
+
+	amountTo := "100.00"
+	params = newParams(nil, nil)
+	params.FilterAmountGte = &amountFrom
+	params.FilterAmountLte = &amountTo
+	badRange := TestCase{
+		name:      "list with empty amount range",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusBadRequest,
+		wantResp:  nil,
+	}
+
+	pageSize = new(int64)
+	*pageSize = 5
+	after := cursorFor(setupData[4])
+	params = newParams(nil, pageSize)
+	params.PageAfter = &after
+	afterCursor := TestCase{
+		name:      "list page after cursor",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusOK,
+		wantResp:  &models.PaymentDetailsListResponse{
+			Data: setupData[5:10],
+			Links: pageLinks(params, setupData[9], setupData[5], 20),
+			Meta:  listMeta(20),
+		},
+	}
+
+	pageSize = new(int64)
+	*pageSize = 3
+	before := cursorFor(setupData[5])
+	params = newParams(nil, pageSize)
+	params.PageBefore = &before
+	beforeCursor := TestCase{
+		name:      "list page before cursor",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusOK,
+		wantResp:  &models.PaymentDetailsListResponse{
+			Data: setupData[2:5],
+			Links: pageLinks(params, setupData[4], setupData[2], 20),
+			Meta:  listMeta(20),
+		},
+	}
+
+	badCursor := "not-a-cursor"
+	params = newParams(nil, nil)
+	params.PageAfter = &badCursor
+	invalidCursor := TestCase{
+		name:      "list with invalid cursor",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusBadRequest,
+		wantResp:  nil,
+	}
+
+	params = newParams(nil, nil)
+	params.PageAfter = &after
+	params.PageBefore = &before
+	bothCursors := TestCase{
+		name:      "list with both cursors",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusBadRequest,
+		wantResp:  nil,
+	}
+
+	// Amounts are chosen so that sorting them as text gives a different order.
+	// Processing dates decrease with the position of the payment in setupData
+	amounts := []string{"5.00", "40.00", "120.50", "9.99"}
+	sortData := make([]*models.Payment, 0, len(setupData))
+	for i, payment := range setupData {
+		payment = copyPayment(payment)
+		payment.Attributes.Amount = models.Amount(amounts[i%len(amounts)])
+		payment.Attributes.ProcessingDate = strfmt.Date(time.Date(2017, 1, 31-i, 0, 0, 0, 0, time.UTC))
+		sortData = append(sortData, payment)
+	}
+
+	// Payments sorted by amount, descending, and then by processing date
+	sorted := make([]*models.Payment, 0, len(sortData))
+	for _, r := range []int{2, 1, 3, 0} {
+		for i := len(sortData) - len(amounts) + r; i >= 0; i -= len(amounts) {
+			sorted = append(sorted, sortData[i])
+		}
+	}
+
+	sortBy := "-amount,processing_date"
+	pageNumber = new(int64)
+	*pageNumber = 1
+	pageSize = new(int64)
+	*pageSize = 5
+	params = newParams(pageNumber, pageSize)
+	params.Sort = &sortBy
+	sortedPage := TestCase{
+		name:      "list sorted by amount and processing date",
+		setupData: sortData,
+		params:    params,
+		wantCode:  http.StatusOK,
+		wantResp:  &models.PaymentDetailsListResponse{
+			Data: sorted[5:10],
+			Links: pageLinks(params, sorted[9], sorted[5], 20),
+			Meta:  listMeta(20),
+		},
+	}
+
+	sortedAfter := cursorFor(sorted[4], sortValuesFor(params, sorted[4])...)
+	params = newParams(nil, pageSize)
+	params.Sort = &sortBy
+	params.PageAfter = &sortedAfter
+	sortedAfterCursor := TestCase{
+		name:      "list sorted page after cursor",
+		setupData: sortData,
+		params:    params,
+		wantCode:  http.StatusOK,
+		wantResp:  &models.PaymentDetailsListResponse{
+			Data: sorted[5:10],
+			Links: pageLinks(params, sorted[9], sorted[5], 20),
+			Meta:  listMeta(20),
+		},
+	}
+
+	pageSize = new(int64)
+	*pageSize = 3
+	sortedBefore := cursorFor(sorted[10], sortValuesFor(params, sorted[10])...)
+	params = newParams(nil, pageSize)
+	params.Sort = &sortBy
+	params.PageBefore = &sortedBefore
+	sortedBeforeCursor := TestCase{
+		name:      "list sorted page before cursor",
+		setupData: sortData,
+		params:    params,
+		wantCode:  http.StatusOK,
+		wantResp:  &models.PaymentDetailsListResponse{
+			Data: sorted[7:10],
+			Links: pageLinks(params, sorted[9], sorted[7], 20),
+			Meta:  listMeta(20),
+		},
+	}
+
+	badSortBy := "amount,beneficiary_party"
+	params = newParams(nil, nil)
+	params.Sort = &badSortBy
+	badSort := TestCase{
+		name:      "list with invalid sort field",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusBadRequest,
+		wantResp:  nil,
+	}
+
+	// Cursors can't be used to page through a list sorted differently
+	params = newParams(nil, nil)
+	params.Sort = &sortBy
+	params.PageAfter = &after
+	cursorOtherSort := TestCase{
+		name:      "list with cursor for other sort",
+		setupData: setupData,
+		params:    params,
+		wantCode:  http.StatusBadRequest,
+		wantResp:  nil,
+	}
+
 	return []TestCase{
 		noParams,
 		firstFive,
@@ -600,5 +1575,75 @@ func listTests() []TestCase {
 		lastPage,
 		pageNumberButNoPageSize,
 		paginationOffLimits,
+		filtered,
+		filteredNoResults,
+		badFilter,
+		badRange,
+		afterCursor,
+		beforeCursor,
+		invalidCursor,
+		bothCursors,
+		sortedPage,
+		sortedAfterCursor,
+		sortedBeforeCursor,
+		badSort,
+		cursorOtherSort,
+	}
+}
+
+// listRequestURL builds the URL a client would request to list payments with params
+func listRequestURL(params payments.ListPaymentsParams) string {
+	u := payments.ListPaymentsURL{
+		FilterAmountGte:         params.FilterAmountGte,
+		FilterAmountLte:         params.FilterAmountLte,
+		FilterCurrency:          params.FilterCurrency,
+		FilterOrganisationID:    params.FilterOrganisationID,
+		FilterPaymentScheme:     params.FilterPaymentScheme,
+		FilterProcessingDateGte: params.FilterProcessingDateGte,
+		FilterProcessingDateLte: params.FilterProcessingDateLte,
+		PageAfter:               params.PageAfter,
+		PageBefore:              params.PageBefore,
+		PageNumber:              params.PageNumber,
+		PageSize:                params.PageSize,
+		Sort:                    params.Sort,
 	}
+	return u.StringFull("http", "example.com")
+}
+
+// paymentURL builds the link to the payment with the given ID, as returned in
+// responses to requests built by httptest, which are sent to example.com
+func paymentURL(id strfmt.UUID) string {
+	u := payments.GetPaymentURL{ID: id}
+	return u.StringFull("http", "example.com")
+}
+
+// cursorFor builds the opaque cursor that marks the position of payment in a list,
+// which holds the given values of the fields the list is sorted by
+func cursorFor(payment *models.Payment, values ...string) string {
+	token := fmt.Sprintf(`{"id":"%s"}`, *payment.ID)
+	if len(values) > 0 {
+		quoted, _ := json.Marshal(values)
+		token = fmt.Sprintf(`{"id":"%s","values":%s}`, *payment.ID, quoted)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+// sortValuesFor returns the values of the fields set in the sort param for payment.
+// Only the fields used to sort payments in these tests are supported
+func sortValuesFor(params payments.ListPaymentsParams, payment *models.Payment) []string {
+	if params.Sort == nil {
+		return nil
+	}
+
+	var values []string
+	for _, field := range strings.Split(*params.Sort, ",") {
+		switch strings.TrimPrefix(field, "-") {
+		case "amount":
+			values = append(values, string(payment.Attributes.Amount))
+		case "processing_date":
+			values = append(values, payment.Attributes.ProcessingDate.String())
+		}
+	}
+
+	return values
 }
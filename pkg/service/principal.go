@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/volmedo/pAPI/pkg/restapi"
+)
+
+// Principal identifies the client that sent a request, as established
+// by the authentication layer
+type Principal struct {
+	// Subject is the name of the API key or the subject of the JWT used
+	// to authenticate the request
+	Subject string
+
+	// Organisations are the organisations whose payments the principal
+	// can access
+	Organisations []strfmt.UUID
+
+	// Scopes are the scopes granted to the principal, which determine
+	// the operations it can perform
+	Scopes []string
+}
+
+// HasScope reports whether scope has been granted to the principal
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PrincipalFromContext returns the principal stored in ctx by the
+// authentication layer, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(restapi.AuthKey).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -1,6 +1,12 @@
 package service
 
 import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/volmedo/pAPI/pkg/models"
 )
@@ -11,35 +17,334 @@ const TYPE_PAYMENT = "Payment"
 
 // PaymentRepository stores a collection of payment resources that
 // is safe for concurrent use
+//
+// Every method takes a context that carries the deadline and cancellation
+// signal of the request that triggered the call. Implementations should give up
+// as soon as possible when the context is done
+//
+// The context also carries the principal that sent the request, if any.
+// Implementations must restrict every operation to the payments of the
+// organisations the principal belongs to: payments of other organisations
+// are reported as not found and writing them is forbidden
 type PaymentRepository interface {
 	// Add adds a new payment resource to the repository
 	//
 	// Add returns an error if a payment with the same ID as the one
-	// to be added already exists
-	Add(payment *models.Payment) (*models.Payment, error)
+	// to be added already exists or if the payment belongs to an
+	// organisation the caller can't access
+	Add(ctx context.Context, payment *models.Payment) (*models.Payment, error)
+
+	// AddBatch adds several new payment resources to the repository at once
+	//
+	// If atomic is true, either every payment is added or none is. Otherwise,
+	// the payments that can't be added are skipped and the rest are added
+	//
+	// AddBatch returns the added payments in the same order as the given ones,
+	// with nil in place of those that were not added. It returns an
+	// ErrBatchConflict if some payments have the same ID as an existing payment
+	// or as a previous payment in the batch. No payment is added if any of them
+	// belongs to an organisation the caller can't access
+	AddBatch(ctx context.Context, payments []*models.Payment, atomic bool) ([]*models.Payment, error)
 
 	// Delete deletes the payment resource associated to the given paymentID
+	// and returns it as it was before being deleted
 	//
 	// Delete returns an error if the paymentID is not present in the respository
-	Delete(paymentID strfmt.UUID) error
+	Delete(ctx context.Context, paymentID strfmt.UUID) (*models.Payment, error)
 
 	// Get returns the payment resource associated with the given paymentID
 	//
 	// Get returns an error if the paymentID does not exist in the collection
-	Get(paymentID strfmt.UUID) (*models.Payment, error)
+	Get(ctx context.Context, paymentID strfmt.UUID) (*models.Payment, error)
 
-	// List returns a slice of payment resources. An empty slice will be returned
-	// if no payment exists.
+	// List returns a page of payment resources, sorted by the given fields.
+	// Payments are sorted by ID after every other field, so that the order
+	// of the list is always well defined.
+	//
+	// Only payments that match the given filter are returned. A zero filter
+	// matches every payment.
 	//
-	// List implements basic pagination by means of offset and limit parameters.
-	// List will return an error if offset is beyond the number of elements available.
-	// A limit of 0 will return all elements available. Both parameters default to 0.
-	List(offset, limit int64) ([]*models.Payment, error)
+	// The page to return is selected either by offset or by the position of the
+	// payments relative to a cursor, as set in pagination.
+	// List will return an error if the page contains no payments or if
+	// pagination parameters are not valid.
+	List(ctx context.Context, filter PaymentFilter, sortBy []SortField, pagination Pagination) (*PaymentPage, error)
+
+	// Count returns the number of payments that match the given filter.
+	// A zero filter matches every payment
+	Count(ctx context.Context, filter PaymentFilter) (int64, error)
+
+	// ListVersions returns every version recorded for the payment with the given
+	// paymentID, oldest first. A new version is recorded every time a payment is
+	// added, updated or deleted
+	//
+	// ListVersions returns an error if there are no versions for the paymentID
+	ListVersions(ctx context.Context, paymentID strfmt.UUID) ([]*models.PaymentVersion, error)
+
+	// GetVersion returns the given version of the payment with paymentID
+	//
+	// GetVersion returns an error if the version does not exist
+	GetVersion(ctx context.Context, paymentID strfmt.UUID, version int64) (*models.PaymentVersion, error)
 
 	// Update updates the details associated with the given paymentID
 	//
-	// Update returns an error if the paymentID does not exist in the collection
-	Update(paymentID strfmt.UUID, payment *models.Payment) (*models.Payment, error)
+	// If the version of the given payment is set, the update will only be applied
+	// if it matches the version currently stored. Otherwise, the update is
+	// applied unconditionally
+	//
+	// Update returns an error if the paymentID does not exist in the collection,
+	// if the version of the given payment is stale or if the payment would be
+	// moved to an organisation the caller can't access
+	Update(ctx context.Context, paymentID strfmt.UUID, payment *models.Payment) (*models.Payment, error)
+
+	// Patch updates the details of the payment with the given paymentID that
+	// differ between original and patched, leaving the rest of the details as
+	// they are currently stored. original is the payment that was patched, as
+	// it was returned by Get. The payment is returned as stored after the patch
+	//
+	// If the version of patched is set, the patch will only be applied if it
+	// matches the version currently stored. Otherwise, the patch is applied
+	// unconditionally
+	//
+	// Patch returns an error if the paymentID does not exist in the collection,
+	// if the version of the patched payment is stale or if the payment would be
+	// moved to an organisation the caller can't access
+	Patch(ctx context.Context, paymentID strfmt.UUID, original, patched *models.Payment) (*models.Payment, error)
+
+	// Close frees the resources held by the repository. The repository
+	// can't be used after closing it
+	Close() error
+}
+
+// PaymentFilter holds the criteria used to select payments when listing them.
+// A payment must match every criteria set to be selected. Nil fields
+// are not taken into account
+type PaymentFilter struct {
+	// OrganisationID selects payments created by the given organisation
+	OrganisationID *strfmt.UUID
+
+	// Currency selects payments made in the given currency
+	Currency *models.Currency
+
+	// PaymentScheme selects payments processed through the given scheme
+	PaymentScheme *string
+
+	// ProcessingDateFrom and ProcessingDateTo select payments processed
+	// in the given range of dates, both included
+	ProcessingDateFrom *strfmt.Date
+	ProcessingDateTo   *strfmt.Date
+
+	// AmountFrom and AmountTo select payments whose amount is in the given
+	// range, both included
+	AmountFrom *models.Amount
+	AmountTo   *models.Amount
+}
+
+// SortField is one of the fields used to sort a list of payments
+type SortField struct {
+	// Name of the field, which must be one of the names in sortableFields
+	Name string
+
+	// Descending reverses the order of the field, which is ascending by default
+	Descending bool
+}
+
+// sortKind tells how the values of a sortable field are compared
+type sortKind int
+
+const (
+	// sortText values are compared byte by byte
+	sortText sortKind = iota
+	// sortNumber values are decimal numbers compared by their value
+	sortNumber
+	// sortInteger values are integers compared by their value
+	sortInteger
+	// sortDate values are dates in RFC3339 full-date format
+	sortDate
+	// sortUUID values are UUIDs compared in their lowercase form
+	sortUUID
+)
+
+// sortableField describes a field payments can be sorted by
+type sortableField struct {
+	kind sortKind
+
+	// value returns the value of the field for a given payment, formatted
+	// as the payments table returns it
+	value func(payment *models.Payment) string
+}
+
+// sortableFields is the allow-list of fields that payments can be sorted by, by name
+var sortableFields = map[string]sortableField{
+	"amount": {sortNumber, func(p *models.Payment) string {
+		return string(paymentAttributes(p).Amount)
+	}},
+	"currency": {sortText, func(p *models.Payment) string {
+		return string(paymentAttributes(p).Currency)
+	}},
+	"id": {sortUUID, func(p *models.Payment) string {
+		if p.ID == nil {
+			return ""
+		}
+		return strings.ToLower(p.ID.String())
+	}},
+	"organisation_id": {sortUUID, func(p *models.Payment) string {
+		if p.OrganisationID == nil {
+			return ""
+		}
+		return strings.ToLower(p.OrganisationID.String())
+	}},
+	"payment_scheme": {sortText, func(p *models.Payment) string {
+		return paymentAttributes(p).PaymentScheme
+	}},
+	"payment_type": {sortText, func(p *models.Payment) string {
+		return paymentAttributes(p).PaymentType
+	}},
+	"processing_date": {sortDate, func(p *models.Payment) string {
+		return paymentAttributes(p).ProcessingDate.String()
+	}},
+	"reference": {sortText, func(p *models.Payment) string {
+		return paymentAttributes(p).Reference
+	}},
+	"version": {sortInteger, func(p *models.Payment) string {
+		if p.Version == nil {
+			return ""
+		}
+		return strconv.FormatInt(*p.Version, 10)
+	}},
+}
+
+var (
+	numberRegexp  = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	integerRegexp = regexp.MustCompile(`^-?[0-9]+$`)
+)
+
+// validSortValue reports whether value is a valid value for a field of the given kind
+func validSortValue(kind sortKind, value string) bool {
+	switch kind {
+	case sortNumber:
+		return numberRegexp.MatchString(value)
+	case sortInteger:
+		return integerRegexp.MatchString(value)
+	case sortDate:
+		_, err := time.Parse(strfmt.RFC3339FullDate, value)
+		return err == nil
+	case sortUUID:
+		return strfmt.IsUUID(value)
+	default:
+		return true
+	}
+}
+
+// paymentAttributes returns the attributes of payment, which are empty if not set
+func paymentAttributes(payment *models.Payment) *models.PaymentAttributes {
+	if payment.Attributes == nil {
+		return &models.PaymentAttributes{}
+	}
+
+	return payment.Attributes
+}
+
+// sortKeys returns the fields a list of payments is actually sorted by, which
+// are the given fields followed by the ID if it is not already one of them
+func sortKeys(sortBy []SortField) []SortField {
+	for _, field := range sortBy {
+		if field.Name == "id" {
+			return sortBy
+		}
+	}
+
+	keys := make([]SortField, len(sortBy), len(sortBy)+1)
+	copy(keys, sortBy)
+	return append(keys, SortField{Name: "id"})
+}
+
+// cursorValues returns the values of the sort keys at the position of cursor
+func cursorValues(sortBy []SortField, cursor *Cursor) []string {
+	values := cursor.Values
+	if len(sortKeys(sortBy)) > len(sortBy) {
+		values = append(values[:len(values):len(values)], strings.ToLower(cursor.ID.String()))
+	}
+
+	return values
+}
+
+// Pagination selects which payments of a list make up a page. Payments can be
+// selected by their offset from the start of the list or by their position
+// relative to a cursor (keyset pagination). Offset is ignored if any cursor is set
+type Pagination struct {
+	// Offset is the number of payments to skip from the start of the list
+	Offset int64
+
+	// Limit is the maximum number of payments in the page. It must be between 1 and 100
+	Limit int64
+
+	// After selects the payments that come right after the cursor
+	After *Cursor
+
+	// Before selects the payments that come right before the cursor.
+	// After and Before can't be set at the same time
+	Before *Cursor
+}
+
+// Cursor marks a position in a list of payments, which is given by the sort
+// key of a payment. The payment doesn't need to exist anymore for the cursor
+// to be valid
+type Cursor struct {
+	// ID of the payment at the position of the cursor
+	ID strfmt.UUID
+
+	// Values of the fields the list is sorted by for the payment at the
+	// position of the cursor, in the same order as the fields. They must be
+	// formatted as the value functions in sortableFields do
+	Values []string
+}
+
+// PaymentPage is a page of a list of payments
+type PaymentPage struct {
+	// Payments in the page, in list order
+	Payments []*models.Payment
+
+	// HasNext reports whether there are payments after the last one in the page
+	HasNext bool
+
+	// HasPrev reports whether there are payments before the first one in the page
+	//
+	// The payment at a cursor is assumed to be part of the list, so HasPrev is
+	// always true when paging forward from a cursor and HasNext is always true
+	// when paging backward from it
+	HasPrev bool
+}
+
+// newPaymentPage builds a page out of the payments retrieved for the given
+// pagination, which must include one more payment than the limit of the
+// page if there are more payments beyond it. When paging backward from a
+// cursor, payments must be in reverse order
+func newPaymentPage(payments []*models.Payment, pagination Pagination) *PaymentPage {
+	more := int64(len(payments)) > pagination.Limit
+	if more {
+		payments = payments[:pagination.Limit]
+	}
+
+	page := &PaymentPage{Payments: payments}
+	switch {
+	case pagination.After != nil:
+		page.HasNext = more
+		page.HasPrev = true
+
+	case pagination.Before != nil:
+		for i, j := 0, len(payments)-1; i < j; i, j = i+1, j-1 {
+			payments[i], payments[j] = payments[j], payments[i]
+		}
+		page.HasNext = true
+		page.HasPrev = more
+
+	default:
+		page.HasNext = more
+		page.HasPrev = pagination.Offset > 0
+	}
+
+	return page
 }
 
 // ErrConflict signals an attempt to add a new payment with the same
@@ -55,6 +360,23 @@ func (e ErrConflict) Error() string {
 	return string(e)
 }
 
+// ErrBatchConflict signals an attempt to add a batch of payments where some
+// of them have the same id as one already present. Indexes holds the positions
+// of those payments in the batch
+type ErrBatchConflict struct {
+	msg     string
+	Indexes []int
+}
+
+func newErrBatchConflict(msg string, indexes []int) ErrBatchConflict {
+	return ErrBatchConflict{msg: msg, Indexes: indexes}
+}
+
+// Error satisfies stdlib's error interface
+func (e ErrBatchConflict) Error() string {
+	return e.msg
+}
+
 // ErrNoResults is returned when a get, delete or update is attempted
 // for a non-existent id
 type ErrNoResults string
@@ -80,3 +402,30 @@ func newErrBadOffsetLimit(msg string) ErrBadOffsetLimit {
 func (e ErrBadOffsetLimit) Error() string {
 	return string(e)
 }
+
+// ErrVersionMismatch is returned when an update is attempted with a version
+// that doesn't match the current version of the payment, meaning that
+// the payment has been modified in the meantime
+type ErrVersionMismatch string
+
+func newErrVersionMismatch(msg string) ErrVersionMismatch {
+	return ErrVersionMismatch(msg)
+}
+
+// Error satisfies stdlib's error interface
+func (e ErrVersionMismatch) Error() string {
+	return string(e)
+}
+
+// ErrForbidden is returned when an attempt is made to write a payment
+// that belongs to an organisation the caller can't access
+type ErrForbidden string
+
+func newErrForbidden(msg string) ErrForbidden {
+	return ErrForbidden(msg)
+}
+
+// Error satisfies stdlib's error interface
+func (e ErrForbidden) Error() string {
+	return string(e)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+
+	"github.com/volmedo/pAPI/pkg/models"
+)
+
+// ServeError writes the errors raised by the API before requests reach the
+// service, such as authentication, routing or validation errors, as an
+// ApiError, like the errors returned by the service itself.
+//
+// Problems found validating the body of a request point to the invalid field.
+// The body is validated again against the schema of the route for this, as the
+// names of nested fields are lost by the validators generated by go-swagger.
+// That requires the body to be recorded while it is read, see RecordBody
+func ServeError(rw http.ResponseWriter, r *http.Request, err error) {
+	ctx := r.Context()
+
+	var objects []*models.APIErrorObject
+	var bodyErrs []*errors.Validation
+	for _, err := range flattenErrors(err) {
+		if e, ok := err.(*errors.Validation); ok && e.In == "body" {
+			bodyErrs = append(bodyErrs, e)
+			continue
+		}
+
+		objects = append(objects, errorObject(ctx, err))
+	}
+
+	if len(bodyErrs) > 0 {
+		if errs := bodyErrors(r); len(errs) > 0 {
+			objects = append(objects, fieldErrorObjects(ctx, errs)...)
+		} else {
+			// The names of the fields are not reliable, so the problems
+			// found by the generated validators don't point anywhere
+			for _, e := range bodyErrs {
+				objects = append(objects, validationErrorObject(ctx, e))
+			}
+		}
+	}
+
+	if e, ok := err.(*errors.MethodNotAllowedError); ok {
+		rw.Header().Add("Allow", strings.Join(e.Allowed, ","))
+	}
+
+	writeAPIError(rw, r, errorStatus(objects), newAPIErrors(objects...))
+}
+
+// writeAPIError writes apiErr as the body of a response with the given status
+func writeAPIError(rw http.ResponseWriter, r *http.Request, status int, apiErr *models.APIError) {
+	rw.Header().Set(runtime.HeaderContentType, "application/vnd.api+json")
+	rw.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if err := json.NewEncoder(rw).Encode(apiErr); err != nil {
+		panic(err) // let the recovery middleware deal with this
+	}
+}
+
+// flattenErrors returns the errors in err, which may be a composite of several
+// of them. Errors raised while reading request bodies are kept as they are
+func flattenErrors(err error) []error {
+	composite, ok := err.(*errors.CompositeError)
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, err := range composite.Errors {
+		errs = append(errs, flattenErrors(err)...)
+	}
+
+	return errs
+}
+
+// errorObject creates the object that describes a problem raised by the API.
+// Unexpected errors are logged and reported without details
+func errorObject(ctx context.Context, err error) *models.APIErrorObject {
+	switch e := err.(type) {
+	case *errors.Validation:
+		return validationErrorObject(ctx, e)
+
+	case *errors.ParseError:
+		// Errors raised while reading the body, such as the body being too
+		// large, are wrapped by the error of the param it was bound to
+		if reason, ok := e.Reason.(errors.Error); ok {
+			return errorObject(ctx, reason)
+		}
+
+		if e.In == "body" {
+			detail := fmt.Sprintf("The request body is not a valid JSON document: %v", e.Reason)
+			return newAPIErrorObject(ctx, http.StatusBadRequest, codeMalformedBody, detail)
+		}
+
+		obj := newAPIErrorObject(ctx, http.StatusBadRequest, codeBadRequest, e.Error())
+		if e.In == "header" {
+			obj.Source = &models.APIErrorSource{Header: e.Name}
+		} else {
+			obj.Source = &models.APIErrorSource{Parameter: e.Name}
+		}
+		return obj
+
+	case errors.Error:
+		status := int(e.Code())
+		code, ok := statusCodes[status]
+		if !ok {
+			break
+		}
+
+		// Errors with a 5xx status are raised on purpose, such as when
+		// recovering from panics, and their details are logged where raised
+		if status >= http.StatusInternalServerError {
+			return newAPIErrorObject(ctx, status, code, internalErrorDetail)
+		}
+
+		return newAPIErrorObject(ctx, status, code, e.Error())
+	}
+
+	// The standard logger is used, as ServeError can't take a logger
+	RequestLogger(ctx, nil).WithError(err).Error("Error serving request")
+	return newAPIErrorObject(ctx, http.StatusInternalServerError, codeInternalError, internalErrorDetail)
+}
+
+// errorStatus returns the HTTP status of a response with the given problems,
+// which is the status of the problems if they all share it. Otherwise, the
+// most general status of their class is used
+func errorStatus(objects []*models.APIErrorObject) int {
+	status := http.StatusInternalServerError
+	for i, obj := range objects {
+		st, _ := strconv.Atoi(obj.Status)
+		switch {
+		case i == 0:
+			status = st
+		case st >= http.StatusInternalServerError || status >= http.StatusInternalServerError:
+			status = http.StatusInternalServerError
+		case st != status:
+			status = http.StatusBadRequest
+		}
+	}
+
+	return status
+}
+
+// recordedBodyKey is the key under which the body of a request is recorded in its context
+type recordedBodyKey struct{}
+
+// recordingBody is a request body that keeps a copy of what is read from it
+type recordingBody struct {
+	io.Reader
+	io.Closer
+}
+
+// RecordBody is meant to be used as inner middleware of the API handler. It
+// records the body of the requests to routes that take one as it is read, so
+// that ServeError can point to the invalid fields when the body is not valid
+func RecordBody(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := middleware.MatchedRouteFrom(r)
+		if route == nil || r.Body == nil || bodySchemaParam(route) == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		r.Body = &recordingBody{Reader: io.TeeReader(r.Body, buf), Closer: r.Body}
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), recordedBodyKey{}, buf)))
+	})
+}
+
+// bodySchemaParam returns the name of the body param of route, if it has one
+func bodySchemaParam(route *middleware.MatchedRoute) string {
+	for name, param := range route.Parameters {
+		if param.In == "body" && param.Schema != nil {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// bodyErrors validates the body recorded for r against the schema of its
+// route and returns the problems found, named after the path to the invalid
+// fields. No problems are returned if the body was not recorded
+func bodyErrors(r *http.Request) []*errors.Validation {
+	buf, ok := r.Context().Value(recordedBodyKey{}).(*bytes.Buffer)
+	route := middleware.MatchedRouteFrom(r)
+	if !ok || route == nil {
+		return nil
+	}
+
+	param := route.Parameters[bodySchemaParam(route)]
+	if param.Schema == nil {
+		return nil
+	}
+
+	// Consume whatever was left unread so that the whole body is validated.
+	// The body may have been closed already, then what was read is used
+	io.Copy(ioutil.Discard, r.Body)
+
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil
+	}
+
+	root, err := loadSpec()
+	if err != nil {
+		return nil
+	}
+
+	return schemaErrors(root, param.Schema, "", data)
+}
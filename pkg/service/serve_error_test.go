@@ -0,0 +1,174 @@
+// +build !integration
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/volmedo/pAPI/pkg/models"
+	"github.com/volmedo/pAPI/pkg/restapi"
+)
+
+// findErrorObject returns the object in apiErr with the given code and
+// pointer, or nil if there is none
+func findErrorObject(apiErr *models.APIError, code, pointer string) *models.APIErrorObject {
+	for _, obj := range apiErr.Errors {
+		if obj.Code == code && obj.Source != nil && obj.Source.Pointer == pointer {
+			return obj
+		}
+	}
+
+	return nil
+}
+
+func TestServeError(t *testing.T) {
+	handler, api, err := restapi.HandlerAPI(restapi.Config{
+		PaymentsAPI:     &PaymentsService{Repo: NewMemPaymentRepository()},
+		InnerMiddleware: RecordBody,
+	})
+	if err != nil {
+		t.Fatalf("Error creating API handler: %v", err)
+	}
+	api.ServeError = ServeError
+
+	const orgID = "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb"
+	tests := map[string]struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantSource models.APIErrorSource
+		wantHeader string
+	}{
+		"invalid nested field": {
+			method:     http.MethodPost,
+			path:       "/v1/payments",
+			body:       `{"data": {"organisation_id": "` + orgID + `", "attributes": {"amount": "ten pounds"}}}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "pattern",
+			wantSource: models.APIErrorSource{Pointer: "/data/attributes/amount"},
+		},
+		"missing field": {
+			method:     http.MethodPost,
+			path:       "/v1/payments",
+			body:       `{"data": {"attributes": {"amount": "10.00"}}}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "required",
+			wantSource: models.APIErrorSource{Pointer: "/data/organisation_id"},
+		},
+		"invalid item": {
+			method:     http.MethodPost,
+			path:       "/v1/payments/bulk",
+			body:       `{"data": []}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "min_items",
+			wantSource: models.APIErrorSource{Pointer: "/data"},
+		},
+		"malformed body": {
+			method:     http.MethodPost,
+			path:       "/v1/payments",
+			body:       `{"data": `,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeMalformedBody,
+		},
+		"invalid query param": {
+			method:     http.MethodGet,
+			path:       "/v1/payments?page[size]=0",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "minimum",
+			wantSource: models.APIErrorSource{Parameter: "page[size]"},
+		},
+		"method not allowed": {
+			method:     http.MethodPut,
+			path:       "/v1/payments",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   codeMethodNotAllowed,
+			wantHeader: "Allow",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/vnd.api+json")
+			req.Header.Set("X-API-Key", "test-key")
+			req = req.WithContext(ContextWithRequestID(req.Context(), "req-42"))
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantStatus {
+				t.Fatalf("Want status %d but got %d: %s", tc.wantStatus, resp.Code, resp.Body)
+			}
+			if tc.wantHeader != "" && resp.Header().Get(tc.wantHeader) == "" {
+				t.Errorf("Want the %s header in the response", tc.wantHeader)
+			}
+
+			var apiErr models.APIError
+			if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+				t.Fatalf("Error decoding ApiError: %v", err)
+			}
+			if apiErr.ErrorCode != "req-42" || len(apiErr.Errors) == 0 || apiErr.ErrorMessage != apiErr.Errors[0].Detail {
+				t.Errorf("Want the ID and the detail of the first error in the legacy fields but got %+v", apiErr)
+			}
+
+			var found bool
+			for _, obj := range apiErr.Errors {
+				var source models.APIErrorSource
+				if obj.Source != nil {
+					source = *obj.Source
+				}
+				if obj.Code == tc.wantCode && source == tc.wantSource {
+					found = true
+					if obj.ID != "req-42" || obj.Title == "" || obj.Detail == "" {
+						t.Errorf("Want an ID, a title and a detail but got %+v", obj)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("Want an error with code %q and source %+v but got %s", tc.wantCode, tc.wantSource, resp.Body)
+			}
+		})
+	}
+}
+
+func TestDefinitionErrors(t *testing.T) {
+	item := map[string]interface{}{
+		"organisation_id": "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb",
+		"attributes": map[string]interface{}{
+			"amount":   "ten pounds",
+			"currency": "GBP",
+		},
+	}
+
+	errs, err := definitionErrors("Payment", "data.3", item)
+	if err != nil {
+		t.Fatalf("Error validating payment: %v", err)
+	}
+
+	apiErr := newAPIErrors(fieldErrorObjects(context.Background(), errs)...)
+	if findErrorObject(apiErr, "pattern", "/data/3/attributes/amount") == nil {
+		t.Errorf("Want the invalid amount of the 4th item but got %+v", errs)
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"data":                    "/data",
+		".data.attributes.amount": "/data/attributes/amount",
+		"data.0.id":               "/data/0/id",
+		"a/b.c~d":                 "/a~1b/c~0d",
+	}
+
+	for path, want := range tests {
+		if got := jsonPointer(path); got != want {
+			t.Errorf("Want %q for %q but got %q", want, path, got)
+		}
+	}
+}
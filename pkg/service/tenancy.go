@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+
+	"github.com/volmedo/pAPI/pkg/models"
+)
+
+// callerOrganisations returns the organisations whose payments can be accessed
+// by the principal stored in ctx.
+//
+// scoped is false if there is no principal in ctx, which is the case for calls
+// that don't come from client requests, such as those made by maintenance tasks
+// or tests. Those calls can access the payments of every organisation
+func callerOrganisations(ctx context.Context) (orgs []strfmt.UUID, scoped bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return principal.Organisations, true
+}
+
+// canAccessOrganisation reports whether the caller can access the payments
+// of the organisation with orgID
+func canAccessOrganisation(ctx context.Context, orgID *strfmt.UUID) bool {
+	orgs, scoped := callerOrganisations(ctx)
+	if !scoped {
+		return true
+	}
+	if orgID == nil {
+		return false
+	}
+
+	for _, org := range orgs {
+		if strings.EqualFold(org.String(), orgID.String()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkOrganisation returns an ErrForbidden if the caller can't write payments
+// for the organisation payment belongs to
+func checkOrganisation(ctx context.Context, payment *models.Payment) error {
+	if canAccessOrganisation(ctx, payment.OrganisationID) {
+		return nil
+	}
+
+	orgID := "<none>"
+	if payment.OrganisationID != nil {
+		orgID = payment.OrganisationID.String()
+	}
+	return newErrForbidden(fmt.Sprintf("payments of organisation %s can't be written by the caller", orgID))
+}
+
+// checkOrganisationChange returns an ErrForbidden if patched moves a payment
+// from the organisation of original to one the caller can't write payments for
+func checkOrganisationChange(ctx context.Context, original, patched *models.Payment) error {
+	if reflect.DeepEqual(original.OrganisationID, patched.OrganisationID) {
+		return nil
+	}
+
+	return checkOrganisation(ctx, patched)
+}
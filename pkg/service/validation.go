@@ -0,0 +1,234 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+
+	"github.com/volmedo/pAPI/pkg/restapi"
+)
+
+// validationCodes are the codes of the problems found validating requests,
+// by the code of the validation error that describes them
+var validationCodes = map[int32]string{
+	errors.InvalidTypeCode:           "invalid_type",
+	errors.RequiredFailCode:          "required",
+	errors.TooLongFailCode:           "too_long",
+	errors.TooShortFailCode:          "too_short",
+	errors.PatternFailCode:           "pattern",
+	errors.EnumFailCode:              "enum",
+	errors.MultipleOfFailCode:        "multiple_of",
+	errors.MaxFailCode:               "maximum",
+	errors.MinFailCode:               "minimum",
+	errors.UniqueFailCode:            "unique",
+	errors.MaxItemsFailCode:          "max_items",
+	errors.MinItemsFailCode:          "min_items",
+	errors.NoAdditionalItemsCode:     "additional_items",
+	errors.TooFewPropertiesCode:      "too_few_properties",
+	errors.TooManyPropertiesCode:     "too_many_properties",
+	errors.UnallowedPropertyCode:     "unallowed_property",
+	errors.FailedAllPatternPropsCode: "failed_pattern_properties",
+}
+
+var (
+	apiSpecOnce sync.Once
+	apiSpec     *spec.Swagger
+	apiSpecErr  error
+)
+
+// loadSpec returns the spec of the API, which is loaded the first time it is needed
+func loadSpec() (*spec.Swagger, error) {
+	apiSpecOnce.Do(func() {
+		// The embedded spec is copied, as it is shared with the API handler
+		raw := append(json.RawMessage(nil), restapi.SwaggerJSON...)
+		doc, err := loads.Analyzed(raw, "")
+		if err != nil {
+			apiSpecErr = err
+			return
+		}
+		apiSpec = doc.Spec()
+	})
+
+	return apiSpec, apiSpecErr
+}
+
+// definitionErrors validates data against the definition called name in the
+// spec of the API. The problems found are named after the path to the invalid
+// field, prefixed with path
+func definitionErrors(name, path string, data interface{}) ([]*errors.Validation, error) {
+	root, err := loadSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	schema, ok := root.Definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("definition %s not found in the spec", name)
+	}
+
+	return schemaErrors(root, &schema, path, data), nil
+}
+
+// schemaErrors validates data against schema and returns the problems found.
+// Every problem is named after the path to the invalid field, with the names
+// of the fields and the positions of the items separated by dots and prefixed
+// with path.
+//
+// The validators of go-openapi lose the path to the fields in nested objects
+// and arrays, so objects and arrays are walked here and each level is checked
+// with a copy of its schema that doesn't go deeper
+func schemaErrors(root *spec.Swagger, schema *spec.Schema, path string, data interface{}) []*errors.Validation {
+	for schema.Ref.String() != "" {
+		resolved, err := spec.ResolveRef(root, &schema.Ref)
+		if err != nil {
+			return nil
+		}
+		schema = resolved
+	}
+
+	level := *schema
+	levelData := data
+	var children []string
+	childSchemas := make(map[string]*spec.Schema)
+	switch value := data.(type) {
+	case map[string]interface{}:
+		if len(schema.Properties) == 0 {
+			break
+		}
+
+		// Properties are still checked for presence, but not for content.
+		// Their values are left out, as some of them, such as json.Number,
+		// are not valid against an empty schema
+		level.Properties = make(map[string]spec.Schema, len(schema.Properties))
+		for name := range schema.Properties {
+			level.Properties[name] = spec.Schema{}
+		}
+		presence := make(map[string]interface{}, len(value))
+		for name, v := range value {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				presence[name] = v
+				continue
+			}
+
+			presence[name] = nil
+			children = append(children, name)
+			childSchemas[name] = &prop
+		}
+		levelData = presence
+		sort.Strings(children)
+
+	case []interface{}:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			break
+		}
+
+		level.Items = nil
+		for i := range value {
+			name := strconv.Itoa(i)
+			children = append(children, name)
+			childSchemas[name] = schema.Items.Schema
+		}
+	}
+
+	result := validate.NewSchemaValidator(&level, root, path, strfmt.Default).Validate(levelData)
+	var errs []*errors.Validation
+	for _, err := range result.Errors {
+		errs = append(errs, flattenValidation(err)...)
+	}
+
+	for _, name := range children {
+		var value interface{}
+		switch d := data.(type) {
+		case map[string]interface{}:
+			value = d[name]
+		case []interface{}:
+			i, _ := strconv.Atoi(name)
+			value = d[i]
+		}
+		errs = append(errs, schemaErrors(root, childSchemas[name], joinPath(path, name), value)...)
+	}
+
+	return errs
+}
+
+// invalidDocumentError is returned when a document sent by a client is not
+// valid. It holds the problems found, named after the path to the invalid fields
+type invalidDocumentError []*errors.Validation
+
+func (e invalidDocumentError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// flattenValidation returns the validation errors in err, which may be a
+// composite of several of them
+func flattenValidation(err error) []*errors.Validation {
+	switch e := err.(type) {
+	case *errors.Validation:
+		return []*errors.Validation{e}
+	case *errors.CompositeError:
+		var errs []*errors.Validation
+		for _, err := range e.Errors {
+			errs = append(errs, flattenValidation(err)...)
+		}
+		return errs
+	}
+
+	return nil
+}
+
+// fieldName returns the path to the field a validation error refers to.
+// Errors about unallowed properties are named after the object, so the
+// name of the property is added to the path
+func fieldName(e *errors.Validation) string {
+	if key, ok := e.Value.(string); ok && e.Code() == errors.UnallowedPropertyCode {
+		return joinPath(e.Name, key)
+	}
+
+	return e.Name
+}
+
+// joinPath joins the names of fields in a path with dots
+func joinPath(path, name string) string {
+	path = strings.Trim(path, ".")
+	name = strings.Trim(name, ".")
+	if path == "" {
+		return name
+	}
+	if name == "" {
+		return path
+	}
+
+	return path + "." + name
+}
+
+// jsonPointer converts a path with the names of fields separated by dots into
+// a JSON Pointer, as defined in RFC 6901. An empty path points to the whole document
+func jsonPointer(path string) string {
+	var pointer strings.Builder
+	for _, name := range strings.Split(path, ".") {
+		if name == "" {
+			continue
+		}
+
+		name = strings.Replace(name, "~", "~0", -1)
+		name = strings.Replace(name, "/", "~1", -1)
+		pointer.WriteString("/" + name)
+	}
+
+	return pointer.String()
+}
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// SpanData holds the details of a span that has ended, as exported
+type SpanData struct {
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Name         string                 `json:"name"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	DurationMS   float64                `json:"duration_ms"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// Exporter sends the spans of sampled traces somewhere they can be looked at,
+// such as a file or a tracing backend. ExportSpan is called once for every
+// span when it ends, and it may be called concurrently
+type Exporter interface {
+	ExportSpan(span *SpanData) error
+}
+
+// JSONExporter writes spans to a writer as JSON objects, one per line, so
+// that traces can be looked at without a tracing backend
+type JSONExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONExporter creates a JSONExporter that writes spans to w, such as
+// os.Stdout or a file
+func NewJSONExporter(w io.Writer) *JSONExporter {
+	return &JSONExporter{enc: json.NewEncoder(w)}
+}
+
+// ExportSpan writes span as a line of JSON
+func (e *JSONExporter) ExportSpan(span *SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.enc.Encode(span)
+}
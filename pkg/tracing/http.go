@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"net/http"
+)
+
+const (
+	// TraceparentHeader propagates the trace and the span a request belongs to
+	TraceparentHeader = "traceparent"
+
+	// TracestateHeader propagates vendor specific data along with traceparent
+	TracestateHeader = "tracestate"
+)
+
+// statusRecorder is a response writer that records the status of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records status and writes it to the wrapped writer
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write records an implicit 200 status if none was written before
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Handler returns a middleware that starts the root span of every request to
+// handler with tracer. The span continues the trace of the traceparent header
+// of the request, if it carries a valid one, and it is propagated in the
+// traceparent header of the response
+func Handler(tracer *Tracer, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Invalid headers are ignored and a new trace is started, as required
+		remote, err := ParseTraceparent(r.Header.Get(TraceparentHeader))
+		if err == nil {
+			remote.TraceState = r.Header.Get(TracestateHeader)
+		}
+
+		ctx, span := tracer.StartSpan(r.Context(), "HTTP "+r.Method, remote)
+		defer span.End()
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.URL.RequestURI())
+
+		sc := span.Context()
+		w.Header().Set(TraceparentHeader, sc.Traceparent())
+		if sc.TraceState != "" {
+			w.Header().Set(TracestateHeader, sc.TraceState)
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		handler.ServeHTTP(rec, r.WithContext(ctx))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		span.SetAttribute("http.status_code", rec.status)
+	})
+}
+
+// Middleware returns a middleware that measures the time spent in handler,
+// which is usually another middleware, as a span with the given name
+func Middleware(name string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := StartSpan(r.Context(), name)
+		defer span.End()
+
+		if span != nil {
+			r = r.WithContext(ctx)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
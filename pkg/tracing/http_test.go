@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	const incoming = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := map[string]struct {
+		traceparent   string
+		wantContinued bool
+	}{
+		"continued trace": {
+			traceparent:   incoming,
+			wantContinued: true,
+		},
+		"invalid header": {
+			traceparent: "not-a-traceparent",
+		},
+		"no header": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tracer := NewTracer(Config{Exporter: NewJSONExporter(buf), SampleRatio: 1})
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+			handler := Handler(tracer, Middleware("inner", inner))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/payments", nil)
+			if tc.traceparent != "" {
+				req.Header.Set(TraceparentHeader, tc.traceparent)
+				req.Header.Set(TracestateHeader, "vendor=value")
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			sc, err := ParseTraceparent(resp.Header().Get(TraceparentHeader))
+			if err != nil {
+				t.Fatalf("want a valid traceparent in the response: %v", err)
+			}
+			continued := sc.TraceID.String() == "4bf92f3577b34da6a3ce929d0e0e4736"
+			if continued != tc.wantContinued {
+				t.Errorf("want the trace continued: %v but got %s", tc.wantContinued, sc.Traceparent())
+			}
+			if (resp.Header().Get(TracestateHeader) != "") != tc.wantContinued {
+				t.Errorf("want tracestate propagated only with the trace but got %q", resp.Header().Get(TracestateHeader))
+			}
+
+			var spans []SpanData
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				var span SpanData
+				if err := json.Unmarshal([]byte(line), &span); err != nil {
+					t.Fatalf("Error decoding span %q: %v", line, err)
+				}
+				spans = append(spans, span)
+			}
+			if len(spans) != 2 {
+				t.Fatalf("want 2 spans but got %d", len(spans))
+			}
+
+			innerSpan, root := spans[0], spans[1]
+			if root.SpanID != sc.SpanID.String() || innerSpan.ParentSpanID != root.SpanID {
+				t.Errorf("want the root span in the response and the inner span as its child but got %+v and %+v", root, innerSpan)
+			}
+			if tc.wantContinued && root.ParentSpanID != "00f067aa0ba902b7" {
+				t.Errorf("want the root span as a child of the client span but got %+v", root)
+			}
+			if root.Attributes["http.status_code"] != float64(http.StatusTeapot) {
+				t.Errorf("want the status of the response recorded but got %v", root.Attributes)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+// Package tracing records where time goes while handling requests as traces
+// made of spans, and propagates them across services with the W3C Trace
+// Context traceparent header (https://www.w3.org/TR/trace-context/).
+//
+// Spans are started from the context of a request, so that each of them is a
+// child of the span already stored in the context. The root span of a request
+// is started by the middleware returned by Handler, which continues the trace
+// of the client if the request carries a traceparent header. Spans started
+// from a context without a span are nil, and every method of a nil span is a
+// no-op, so code can be traced whether tracing is set up or not
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace, which is made of every span of a request
+type TraceID [16]byte
+
+// String returns the ID as lowercase hex
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid tells whether the ID is valid, as IDs made of zeroes are not
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the ID as lowercase hex
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid tells whether the ID is valid, as IDs made of zeroes are not
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext holds what identifies a span across services
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+
+	// Sampled tells whether the spans of the trace are exported
+	Sampled bool
+
+	// TraceState holds the vendor specific data of the tracestate header,
+	// which is propagated as it is
+	TraceState string
+}
+
+// IsValid tells whether sc identifies a span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent returns the value of the traceparent header that propagates sc
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses the value of a traceparent header. Versions after
+// 00 are parsed as version 00, ignoring any additional fields, as required by
+// the specification
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+
+	value = strings.TrimSpace(value)
+	if len(value) < 55 {
+		return sc, fmt.Errorf("traceparent %q is too short", value)
+	}
+
+	version, err := parseHex(value[0:2], 1)
+	if err != nil || version[0] == 0xff || value[2] != '-' {
+		return sc, fmt.Errorf("traceparent %q has an invalid version", value)
+	}
+	if version[0] == 0 && len(value) != 55 {
+		return sc, fmt.Errorf("traceparent %q has trailing data", value)
+	}
+	if len(value) > 55 && value[55] != '-' {
+		return sc, fmt.Errorf("traceparent %q has an invalid format", value)
+	}
+	if value[35] != '-' || value[52] != '-' {
+		return sc, fmt.Errorf("traceparent %q has an invalid format", value)
+	}
+
+	traceID, err := parseHex(value[3:35], len(sc.TraceID))
+	if err != nil {
+		return sc, fmt.Errorf("traceparent %q has an invalid trace ID", value)
+	}
+	copy(sc.TraceID[:], traceID)
+
+	spanID, err := parseHex(value[36:52], len(sc.SpanID))
+	if err != nil {
+		return sc, fmt.Errorf("traceparent %q has an invalid parent ID", value)
+	}
+	copy(sc.SpanID[:], spanID)
+
+	flags, err := parseHex(value[53:55], 1)
+	if err != nil {
+		return sc, fmt.Errorf("traceparent %q has invalid flags", value)
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("traceparent %q has an all-zero ID", value)
+	}
+
+	return sc, nil
+}
+
+// parseHex decodes s, which must be n bytes written as lowercase hex
+func parseHex(s string, n int) ([]byte, error) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, fmt.Errorf("%q is not %d bytes of lowercase hex", s, n)
+	}
+
+	return hex.DecodeString(s)
+}
+
+// Config holds the configuration of a Tracer
+type Config struct {
+	// Exporter receives the spans of sampled traces once they end. Spans are
+	// created and propagated but not exported if Exporter is nil
+	Exporter Exporter
+
+	// SampleRatio is the fraction of new traces, from 0 to 1, that are
+	// sampled. Traces continued from a traceparent header keep the decision
+	// of the client
+	SampleRatio float64
+
+	// OnError is called with the errors raised exporting spans, if it is set
+	OnError func(error)
+}
+
+// Tracer starts the root spans of requests and exports spans once they end
+type Tracer struct {
+	conf Config
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewTracer creates a Tracer with the given configuration
+func NewTracer(conf Config) *Tracer {
+	// IDs only need to be unique, not unpredictable
+	return &Tracer{conf: conf, rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// newIDs returns a new span ID and, if newTrace is set, a new trace ID along
+// with the sampling decision for it
+func (t *Tracer) newIDs(newTrace bool) (traceID TraceID, spanID SpanID, sampled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for !spanID.IsValid() {
+		t.rand.Read(spanID[:])
+	}
+	if newTrace {
+		for !traceID.IsValid() {
+			t.rand.Read(traceID[:])
+		}
+		sampled = t.rand.Float64() < t.conf.SampleRatio
+	}
+
+	return traceID, spanID, sampled
+}
+
+// StartSpan starts the root span of a request with the given name, which
+// continues the trace of remote if it is valid or starts a new trace if not.
+// The returned context carries the span
+func (t *Tracer) StartSpan(ctx context.Context, name string, remote SpanContext) (context.Context, *Span) {
+	span := &Span{tracer: t, name: name, start: time.Now()}
+	if remote.IsValid() {
+		_, span.context.SpanID, _ = t.newIDs(false)
+		span.context.TraceID = remote.TraceID
+		span.context.Sampled = remote.Sampled
+		span.context.TraceState = remote.TraceState
+		span.parentID = remote.SpanID
+	} else {
+		span.context.TraceID, span.context.SpanID, span.context.Sampled = t.newIDs(true)
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// export sends data to the exporter
+func (t *Tracer) export(data *SpanData) {
+	if t.conf.Exporter == nil {
+		return
+	}
+
+	if err := t.conf.Exporter.ExportSpan(data); err != nil && t.conf.OnError != nil {
+		t.conf.OnError(err)
+	}
+}
+
+// spanKey is the key under which the current span is stored in a context
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx that carries span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// FromContext returns the span stored in ctx, or nil if there is none
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// StartSpan starts a span with the given name as a child of the span stored in
+// ctx. The returned context carries the new span. If ctx carries no span, the
+// returned span is nil and ctx is returned as it is
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: parent.tracer, name: name, start: time.Now(), parentID: parent.context.SpanID}
+	span.context = parent.context
+	_, span.context.SpanID, _ = parent.tracer.newIDs(false)
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Span measures an operation done while handling a request
+type Span struct {
+	tracer   *Tracer
+	name     string
+	context  SpanContext
+	parentID SpanID
+	start    time.Time
+
+	mu         sync.Mutex
+	attributes map[string]interface{}
+	err        string
+	ended      bool
+}
+
+// Context returns what identifies the span across services
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.context
+}
+
+// SetAttribute records a detail of the operation measured by the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// SetError records that the operation measured by the span failed with err.
+// Nil errors are ignored
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err.Error()
+}
+
+// End ends the span and exports it if its trace is sampled. Calls after the
+// first one are ignored
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	end := time.Now()
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+
+	if !s.context.Sampled {
+		s.mu.Unlock()
+		return
+	}
+
+	data := &SpanData{
+		TraceID:    s.context.TraceID.String(),
+		SpanID:     s.context.SpanID.String(),
+		Name:       s.name,
+		Start:      s.start,
+		End:        end,
+		DurationMS: float64(end.Sub(s.start)) / float64(time.Millisecond),
+		Attributes: s.attributes,
+		Error:      s.err,
+	}
+	if s.parentID.IsValid() {
+		data.ParentSpanID = s.parentID.String()
+	}
+	s.mu.Unlock()
+
+	s.tracer.export(data)
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// spanRecorder is an exporter that keeps the spans it gets
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+func (sr *spanRecorder) ExportSpan(span *SpanData) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	sr.spans = append(sr.spans, span)
+	return nil
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := map[string]struct {
+		value       string
+		wantErr     bool
+		wantSampled bool
+	}{
+		"sampled": {
+			value:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantSampled: true,
+		},
+		"not sampled": {
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		},
+		"future version with more fields": {
+			value:       "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-holds",
+			wantSampled: true,
+		},
+		"trailing data in version 00": {
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			wantErr: true,
+		},
+		"forbidden version": {
+			value:   "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		"uppercase": {
+			value:   "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+			wantErr: true,
+		},
+		"zero trace ID": {
+			value:   "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		"zero parent ID": {
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			wantErr: true,
+		},
+		"too short": {
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			wantErr: true,
+		},
+		"empty": {
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error: %v but got %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if sc.Sampled != tc.wantSampled {
+				t.Errorf("want sampled %v but got %v", tc.wantSampled, sc.Sampled)
+			}
+			if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+				t.Errorf("want the IDs of the header but got %+v", sc)
+			}
+			if tc.value[:2] == "00" && sc.Traceparent() != tc.value {
+				t.Errorf("want %q formatted back but got %q", tc.value, sc.Traceparent())
+			}
+		})
+	}
+}
+
+func TestStartSpan(t *testing.T) {
+	if _, span := StartSpan(context.Background(), "orphan"); span != nil {
+		t.Errorf("want no span without a parent but got %+v", span)
+	}
+
+	rec := &spanRecorder{}
+	tracer := NewTracer(Config{Exporter: rec, SampleRatio: 1})
+	ctx, root := tracer.StartSpan(context.Background(), "root", SpanContext{})
+	_, child := StartSpan(ctx, "child")
+	child.SetAttribute("key", "value")
+	child.End()
+	child.End()
+	root.End()
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("want 2 spans exported once each but got %d", len(rec.spans))
+	}
+	childData, rootData := rec.spans[0], rec.spans[1]
+	if childData.TraceID != rootData.TraceID || childData.ParentSpanID != rootData.SpanID || rootData.ParentSpanID != "" {
+		t.Errorf("want the child in the trace of the root but got %+v and %+v", childData, rootData)
+	}
+	if childData.Attributes["key"] != "value" {
+		t.Errorf("want the attributes of the child but got %v", childData.Attributes)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	rec := &spanRecorder{}
+	tracer := NewTracer(Config{Exporter: rec, SampleRatio: 0})
+
+	_, span := tracer.StartSpan(context.Background(), "not sampled", SpanContext{})
+	span.End()
+	if len(rec.spans) != 0 {
+		t.Errorf("want no spans exported but got %d", len(rec.spans))
+	}
+
+	// The decision of the client is kept
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span = tracer.StartSpan(context.Background(), "sampled", remote)
+	span.End()
+	if len(rec.spans) != 1 || rec.spans[0].ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("want the span exported as a child of the remote span but got %+v", rec.spans)
+	}
+}
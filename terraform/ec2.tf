@@ -25,6 +25,11 @@ resource "aws_instance" "papi-server" {
     destination = "/home/ec2-user/"
   }
 
+  provisioner "file" {
+    content     = "${var.api-keys}"
+    destination = "/home/ec2-user/apikeys.json"
+  }
+
   provisioner "remote-exec" {
     inline = [
       "chmod +x /home/ec2-user/${basename(var.srv-bin-path)}",
@@ -36,7 +41,8 @@ resource "aws_instance" "papi-server" {
         -dbuser=${var.db-user} \
         -dbpass=${var.db-pass} \
         -dbname=${var.db-name} \
-        -migrations="/home/ec2-user/${basename(var.db-migrations-path)}" &
+        -migrations="/home/ec2-user/${basename(var.db-migrations-path)}" \
+        -apikeys="/home/ec2-user/apikeys.json" &
       EOF
       ,
       "sleep 1",
@@ -31,3 +31,7 @@ variable "db-pass" {
 variable "db-migrations-path" {
   description = "Path to the directory that contains the migration files to be deployed on provisioning"
 }
+
+variable "api-keys" {
+  description = "JSON document with the API keys accepted by the server, which will be deployed on provisioning. It must be kept secret, unlike the keys used by local e2e tests"
+}